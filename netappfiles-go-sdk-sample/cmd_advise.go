@@ -0,0 +1,102 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/advisor"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/logging"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/metrics"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/pricing"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/sdkutils"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/uri"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/utils"
+)
+
+// runAdvise reports over-provisioned capacity pools of an account and how much could be saved
+func runAdvise(cntx context.Context, args []string) error {
+
+	flags := flag.NewFlagSet("advise", flag.ContinueOnError)
+	accountID := flags.String("account-id", "", "resource id of the account to inspect")
+	pricesFile := flags.String("prices", "", "optional json file with prices per GiB per month overriding the default ones")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *accountID == "" {
+		return fmt.Errorf("-account-id must be provided")
+	}
+
+	catalog, err := pricing.LoadCatalog(*pricesFile)
+	if err != nil {
+		return err
+	}
+
+	report, err := advisor.Advise(cntx, *accountID, metrics.AzureMonitorSource{}, catalog)
+	if err != nil {
+		return err
+	}
+
+	utils.ConsoleOutput(fmt.Sprintf("Capacity pools of account %v:", uri.GetANFAccount(*accountID)))
+	for _, pool := range report.Pools {
+		utils.ConsoleOutput(fmt.Sprintf(
+			"\t%v (%v, %v volumes): size %v TiB, allocated %.2f TiB, consumed %.2f TiB%v",
+			uri.GetANFCapacityPool(pool.PoolID),
+			pool.ServiceLevel,
+			pool.VolumeCount,
+			pool.SizeBytes/sdkutils.TiBInBytes,
+			float64(pool.AllocatedBytes)/float64(sdkutils.TiBInBytes),
+			float64(pool.ConsumedBytes)/float64(sdkutils.TiBInBytes),
+			missingConsumption(pool),
+		))
+	}
+
+	for _, missing := range report.MissingMetrics {
+		logging.Warn(fmt.Sprintf("cannot get %v of volume", missing.Metric), logging.Operation("advise"), logging.ResourceID(missing.ResourceID), logging.Err(missing.Err))
+	}
+
+	if len(report.Recommendations) == 0 {
+		if !report.Complete() {
+			return incompleteAdvice(report)
+		}
+		utils.ConsoleOutput("No recommendations, capacity pools are right-sized")
+		return nil
+	}
+
+	utils.ConsoleOutput("Recommendations:")
+	for _, recommendation := range report.Recommendations {
+		utils.ConsoleOutput(fmt.Sprintf("\t[%v] %v: %v (saves %.2f %v/month)",
+			recommendation.Kind,
+			uri.GetResourceName(recommendation.ResourceID),
+			recommendation.Description,
+			recommendation.MonthlySavings,
+			report.Currency,
+		))
+	}
+	utils.ConsoleOutput(fmt.Sprintf("Estimated total savings: %.2f %v/month", report.TotalMonthlySavings(), report.Currency))
+
+	if !report.Complete() {
+		return incompleteAdvice(report)
+	}
+
+	return nil
+}
+
+// missingConsumption flags pools whose consumption is a lower bound
+func missingConsumption(pool advisor.PoolReport) string {
+	if pool.MissingConsumption == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" (at least, %v volumes without metrics)", pool.MissingConsumption)
+}
+
+// incompleteAdvice fails the command when some metrics could not be read so the advice is not mistaken for a full one
+func incompleteAdvice(report advisor.Report) error {
+	return fmt.Errorf("advice is incomplete, %v volume metrics could not be read", len(report.MissingMetrics))
+}
@@ -0,0 +1,69 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/autoscale"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/metrics"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/sdkutils"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/state"
+)
+
+// runAutoscale watches volume consumption and grows volumes once or until interrupted
+func runAutoscale(cntx context.Context, args []string) error {
+
+	flags := flag.NewFlagSet("autoscale", flag.ContinueOnError)
+	volumeIDs := flags.String("volume-ids", "", "comma separated resource ids of the volumes to watch")
+	threshold := flags.Float64("threshold", 80, "usage percentage of the volume quota that triggers growth")
+	stepGiB := flags.Int64("step-gib", 100, "GiB added to the volume quota on each growth")
+	maxVolumeGiB := flags.Int64("max-volume-gib", 102400, "maximum volume quota in GiB (100TiB is the service limit)")
+	maxPoolTiB := flags.Int64("max-pool-tib", 0, "maximum capacity pool size in TiB, 0 means the pool is never grown beyond what is needed")
+	cooldown := flags.Duration("cooldown", 30*time.Minute, "minimum time between two growths of the same volume or pool")
+	interval := flags.Duration("interval", 5*time.Minute, "time between two evaluations")
+	once := flags.Bool("once", false, "evaluates volumes a single time and exits")
+	statePath := flags.String("state", state.Path(), "state journal whose last applied properties are updated")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *volumeIDs == "" {
+		return fmt.Errorf("-volume-ids must be provided")
+	}
+
+	journal, err := state.Load(*statePath)
+	if err != nil {
+		return err
+	}
+
+	autoscaler, err := autoscale.New(
+		autoscale.Config{
+			VolumeIDs:          strings.Split(*volumeIDs, ","),
+			ThresholdPercent:   *threshold,
+			GrowStepBytes:      *stepGiB * sdkutils.GiBInBytes,
+			MaxVolumeSizeBytes: *maxVolumeGiB * sdkutils.GiBInBytes,
+			MaxPoolSizeBytes:   *maxPoolTiB * sdkutils.TiBInBytes,
+			Cooldown:           *cooldown,
+			Interval:           *interval,
+			Journal:            journal,
+		},
+		metrics.AzureMonitorSource{},
+	)
+	if err != nil {
+		return err
+	}
+
+	if *once {
+		return autoscaler.EvaluateAll(cntx)
+	}
+
+	return autoscaler.Run(cntx)
+}
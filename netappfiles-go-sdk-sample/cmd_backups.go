@@ -0,0 +1,62 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/sdkutils"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/uri"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/utils"
+)
+
+// runBackups lists the backups of a volume with their size and creation date
+func runBackups(cntx context.Context, args []string) error {
+
+	flags := flag.NewFlagSet("backups", flag.ContinueOnError)
+	volumeID := flags.String("volume-id", "", "resource id of the volume to list backups from")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if !uri.IsANFVolume(*volumeID) {
+		return fmt.Errorf("-volume-id must be a volume resource id")
+	}
+
+	backups, err := sdkutils.ListANFBackups(
+		cntx,
+		uri.GetResourceGroup(*volumeID),
+		uri.GetANFAccount(*volumeID),
+		uri.GetANFCapacityPool(*volumeID),
+		uri.GetANFVolume(*volumeID),
+	)
+	if err != nil {
+		return err
+	}
+
+	utils.ConsoleOutput(fmt.Sprintf("Volume %v has %v backup(s)", uri.GetANFVolume(*volumeID), len(backups)))
+	for _, backup := range backups {
+		if backup.BackupProperties == nil {
+			continue
+		}
+
+		created := "unknown"
+		if backup.CreationDate != nil {
+			created = backup.CreationDate.String()
+		}
+
+		size := int64(0)
+		if backup.Size != nil {
+			size = *backup.Size
+		}
+
+		utils.ConsoleOutput(fmt.Sprintf("\t%v\tcreated: %v\tsize: %v bytes\ttype: %v", uri.GetANFBackup(*backup.ID), created, size, backup.BackupType))
+	}
+
+	return nil
+}
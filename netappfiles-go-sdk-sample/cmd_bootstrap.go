@@ -0,0 +1,71 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/bootstrap"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/state"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/utils"
+)
+
+// runBootstrap creates the resource group, virtual network and delegated subnet when they do not exist
+func runBootstrap(cntx context.Context, args []string) error {
+
+	flags := flag.NewFlagSet("bootstrap", flag.ContinueOnError)
+	region := flags.String("location", location, "region the network is created in")
+	resourceGroup := flags.String("resource-group", vnetResourceGroupName, "resource group of the virtual network")
+	vnet := flags.String("vnet-name", vnetName, "name of the virtual network")
+	addressSpace := flags.String("vnet-address-space", strings.Join(vnetAddressSpace, ","), "comma separated address spaces of the virtual network")
+	subnet := flags.String("subnet-name", subnetName, "name of the subnet delegated to Microsoft.NetApp/volumes")
+	subnetPrefix := flags.String("subnet-prefix", subnetAddressPrefix, "address prefix of the subnet")
+	statePath := flags.String("state", state.Path(), "state journal the created resources are recorded in")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	config, err := utils.ReadAzureBasicInfoJSON(os.Getenv("AZURE_AUTH_LOCATION"))
+	if err != nil {
+		return fmt.Errorf("cannot get non-sensitive info from AzureAuthFile: %v", err)
+	}
+
+	journal, err := state.Load(*statePath)
+	if err != nil {
+		return err
+	}
+
+	utils.ConsoleOutput("Creating missing network resources...")
+	subnetID, created, err := bootstrap.Network(
+		cntx,
+		*config.SubscriptionID,
+		bootstrap.NetworkSpec{
+			Location:            *region,
+			ResourceGroupName:   *resourceGroup,
+			VnetName:            *vnet,
+			VnetAddressSpace:    strings.Split(*addressSpace, ","),
+			SubnetName:          *subnet,
+			SubnetAddressPrefix: *subnetPrefix,
+			Tags:                sampleTags,
+		},
+		journal,
+	)
+	if err != nil {
+		return err
+	}
+
+	if len(created) == 0 {
+		utils.ConsoleOutput(fmt.Sprintf("Nothing to create, subnet %v already exists", subnetID))
+		return nil
+	}
+	utils.ConsoleOutput(fmt.Sprintf("Network ready, %v resource(s) recorded in %v, subnet id: %v", len(created), *statePath, subnetID))
+
+	return nil
+}
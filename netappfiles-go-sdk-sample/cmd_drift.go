@@ -0,0 +1,122 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/drift"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/logging"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/models"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/state"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/utils"
+)
+
+const (
+	// driftExitCode is returned when drift is found and not reconciled, so CI pipelines can tell it from other failures
+	driftExitCode int = 2
+)
+
+// runDrift compares the resources recorded in the state journal with a deployment spec, or with their last applied
+// properties, and optionally reconciles the differences
+func runDrift(cntx context.Context, args []string) error {
+
+	flags := flag.NewFlagSet("drift", flag.ContinueOnError)
+	statePath := flags.String("state", state.Path(), "state journal listing the managed resources")
+	specFile := flags.String("spec", "", "deployment spec with the desired state, last applied properties are used when empty")
+	reconcile := flags.Bool("reconcile", false, "changes live resources back to their desired state")
+	accept := flags.Bool("accept", false, "records the live properties as last applied properties instead of reporting drift")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *reconcile && *accept {
+		return fmt.Errorf("-reconcile and -accept cannot be used together")
+	}
+
+	journal, err := state.Load(*statePath)
+	if err != nil {
+		return err
+	}
+
+	var spec *models.DeploymentSpec
+	if *specFile != "" {
+		spec, err = utils.ReadDeploymentSpecJSON(*specFile)
+		if err != nil {
+			return err
+		}
+	}
+
+	if *accept {
+		ids := []string{}
+		for _, entry := range journal.Entries {
+			if entry.Type == state.AccountType || entry.Type == state.CapacityPoolType || entry.Type == state.VolumeType {
+				ids = append(ids, entry.ResourceID)
+			}
+		}
+		err = drift.RecordApplied(cntx, journal, ids...)
+		if err != nil {
+			return err
+		}
+		utils.ConsoleOutput(fmt.Sprintf("Live properties recorded as last applied properties in %v", *statePath))
+		return nil
+	}
+
+	report, err := drift.Check(cntx, journal, spec)
+	if err != nil {
+		return err
+	}
+
+	for _, resourceID := range report.NoBaseline {
+		utils.ConsoleOutput(fmt.Sprintf("\tNo last applied properties, cannot check: %v", resourceID))
+	}
+	for _, resourceID := range report.Unmanaged {
+		utils.ConsoleOutput(fmt.Sprintf("\tNot described in %v, skipped: %v", *specFile, resourceID))
+	}
+	for _, resourceID := range report.Missing {
+		utils.ConsoleOutput(fmt.Sprintf("\tMissing: %v", resourceID))
+	}
+	for _, difference := range report.Differences {
+		utils.ConsoleOutput(fmt.Sprintf("\t%v %v: desired %v, actual %v", difference.ResourceID, difference.Property, difference.Desired, difference.Actual))
+	}
+
+	if !report.HasDrift() {
+		utils.ConsoleOutput("No drift detected")
+		return nil
+	}
+
+	if len(report.NoBaseline) > 0 {
+		utils.ConsoleOutput("Run drift -accept to record the live properties of resources without last applied properties once they are verified")
+	}
+
+	if !*reconcile || len(report.Differences) == 0 {
+		return exitCodeError{
+			code: driftExitCode,
+			err:  fmt.Errorf("drift detected: %v difference(s), %v missing resource(s), %v resource(s) without last applied properties", len(report.Differences), len(report.Missing), len(report.NoBaseline)),
+		}
+	}
+
+	utils.ConsoleOutput("Reconciling differences...")
+	result := drift.Reconcile(cntx, journal, report)
+	for _, difference := range result.Reconciled {
+		utils.ConsoleOutput(fmt.Sprintf("\tReconciled %v %v to %v", difference.ResourceID, difference.Property, difference.Desired))
+	}
+	for _, failure := range result.Failed {
+		logging.Warn(fmt.Sprintf("cannot reconcile %v", failure.Difference.Property), logging.Operation("reconcile"), logging.ResourceID(failure.Difference.ResourceID), logging.Err(failure.Err))
+	}
+
+	if len(result.Failed) > 0 || len(report.Missing) > 0 || len(report.NoBaseline) > 0 {
+		return exitCodeError{
+			code: driftExitCode,
+			err:  fmt.Errorf("drift remains: %v difference(s) not reconciled, %v missing resource(s), %v resource(s) without last applied properties", len(result.Failed), len(report.Missing), len(report.NoBaseline)),
+		}
+	}
+
+	utils.ConsoleOutput("Drift reconciled")
+	return nil
+}
@@ -0,0 +1,57 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/cost"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/pricing"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/utils"
+)
+
+// runEstimate prints the monthly cost of a deployment spec, no Azure API is called
+func runEstimate(cntx context.Context, args []string) error {
+
+	flags := flag.NewFlagSet("estimate", flag.ContinueOnError)
+	specFile := flags.String("spec", "", "deployment spec json file")
+	pricesFile := flags.String("prices", "", "optional json file with prices per GiB per month overriding the default ones")
+	output := flags.String("output", "table", "output format, table or json")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *specFile == "" {
+		return fmt.Errorf("-spec must be provided")
+	}
+
+	spec, err := utils.ReadDeploymentSpecJSON(*specFile)
+	if err != nil {
+		return err
+	}
+
+	catalog, err := pricing.LoadCatalog(*pricesFile)
+	if err != nil {
+		return err
+	}
+
+	estimate, err := cost.EstimateDeployment(*spec, catalog)
+	if err != nil {
+		return err
+	}
+
+	switch *output {
+	case "table":
+		return estimate.WriteTable(os.Stdout)
+	case "json":
+		return estimate.WriteJSON(os.Stdout)
+	default:
+		return fmt.Errorf("invalid output format %v, supported formats are: table, json", *output)
+	}
+}
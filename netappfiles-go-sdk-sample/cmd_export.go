@@ -0,0 +1,72 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/export"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/uri"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/utils"
+)
+
+// runExport writes a deployed account, its policies, capacity pools and volumes as an ARM json template or a Bicep file
+func runExport(cntx context.Context, args []string) error {
+
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	accountID := flags.String("account-id", "", "resource id of the account to export")
+	format := flags.String("format", "arm", "template format, arm or bicep")
+	output := flags.String("output", "", "file the template is written to, standard output when empty")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *accountID == "" || !uri.IsANFAccount(*accountID) {
+		return fmt.Errorf("-account-id must be the resource id of an account")
+	}
+	if *format != "arm" && *format != "bicep" {
+		return fmt.Errorf("-format must be arm or bicep")
+	}
+
+	tree, err := export.ReadTree(cntx, *accountID)
+	if err != nil {
+		return err
+	}
+
+	template, err := export.Build(tree)
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			return fmt.Errorf("cannot create template file: %v", err)
+		}
+		defer file.Close()
+		w = file
+	}
+
+	if *format == "bicep" {
+		err = template.WriteBicep(w)
+	} else {
+		err = template.WriteARM(w)
+	}
+	if err != nil {
+		return fmt.Errorf("cannot write template: %v", err)
+	}
+
+	if *output != "" {
+		utils.ConsoleOutput(fmt.Sprintf("Account %v exported to %v", uri.GetANFAccount(*accountID), *output))
+	}
+
+	return nil
+}
@@ -0,0 +1,136 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/gc"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/logging"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/operations"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/state"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/tagging"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/teardown"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/utils"
+)
+
+// runGc finds abandoned resources by tags, age and usage heuristics and deletes them with everything they
+// contain, this is destructive so it requires the -confirm flag
+func runGc(cntx context.Context, args []string) error {
+
+	flags := flag.NewFlagSet("gc", flag.ContinueOnError)
+	tagFilter := flags.String("tag", "", "comma separated key=value tags the resources must have, a value of * matches any value")
+	olderThan := flags.String("older-than", "", "minimum age based on the createdAt tag, e.g. 36h or 7d")
+	types := flags.String("types", "", "comma separated resource types to select: account, capacityPool, volume, snapshotPolicy")
+	location := flags.String("location", "", "only looks at accounts of this region, and their children")
+	emptyAccounts := flags.Bool("empty-accounts", false, "selects accounts without capacity pools")
+	emptyPools := flags.Bool("empty-pools", false, "selects capacity pools without volumes")
+	unusedVolumes := flags.Bool("unused-volumes", false, "selects volumes without any throughput in the last 24 hours")
+	allResources := flags.Bool("all-resources", false, "allows the -empty-* and -unused-volumes heuristics to select resources without -tag, whoever owns them")
+	exclusionTags := flags.String("exclude-tags", gc.DefaultExclusionTagName, "comma separated tag names protecting a resource and its parents")
+	statePath := flags.String("state", state.Path(), "state journal deleted resources are removed from")
+	storePath := flags.String("operations", operations.DefaultStorePath, "operation store deletions in progress are recorded in, they can be resumed with the status command")
+	confirm := flags.Bool("confirm", false, "confirms the resources found can be deleted")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *tagFilter == "" && *olderThan == "" && !*emptyAccounts && !*emptyPools && !*unusedVolumes {
+		return fmt.Errorf("at least one of -tag, -older-than, -empty-accounts, -empty-pools or -unused-volumes must be provided")
+	}
+
+	// Heuristics alone match resources of other teams as well, e.g. a pool whose volumes were just moved
+	if (*emptyAccounts || *emptyPools || *unusedVolumes) && *tagFilter == "" && !*allResources {
+		return fmt.Errorf("-empty-accounts, -empty-pools and -unused-volumes require -tag to scope the resources, or -all-resources to look at every resource")
+	}
+
+	criteria := gc.Criteria{
+		Types:         splitList(*types),
+		EmptyAccounts: *emptyAccounts,
+		EmptyPools:    *emptyPools,
+		UnusedVolumes: *unusedVolumes,
+		ExclusionTags: splitList(*exclusionTags),
+	}
+
+	var err error
+	criteria.TagFilter, err = tagging.ParseTags(*tagFilter)
+	if err != nil {
+		return err
+	}
+	if *olderThan != "" {
+		criteria.OlderThan, err = gc.ParseAge(*olderThan)
+		if err != nil {
+			return err
+		}
+	}
+
+	journal, err := state.Load(*statePath)
+	if err != nil {
+		return err
+	}
+
+	utils.ConsoleOutput("Listing accounts, snapshot policies, capacity pools and volumes...")
+	inventory, err := tagging.Inventory(cntx, *location)
+	if err != nil {
+		return err
+	}
+
+	report, err := gc.Find(cntx, inventory, criteria)
+	if err != nil {
+		return err
+	}
+
+	for _, resourceID := range report.UnknownAge {
+		utils.ConsoleOutput(fmt.Sprintf("\tSkipped, no %v tag: %v", gc.CreatedAtTagName, resourceID))
+	}
+	for _, unchecked := range report.Unchecked {
+		logging.Warn("cannot evaluate resource, it is skipped", logging.Operation("gc"), logging.ResourceID(unchecked.ResourceID), logging.Err(unchecked.Err))
+	}
+	for _, candidate := range report.Protected {
+		utils.ConsoleOutput(fmt.Sprintf("\tProtected %v %v (%v)", candidate.Resource.Type, candidate.Resource.ResourceID, strings.Join(candidate.Reasons, ", ")))
+	}
+
+	if len(report.Candidates) == 0 {
+		utils.ConsoleOutput("No resources to collect")
+		return nil
+	}
+
+	utils.ConsoleOutput(fmt.Sprintf("%v resource(s) to collect, with everything they contain:", len(report.Candidates)))
+	for _, candidate := range report.Candidates {
+		utils.ConsoleOutput(fmt.Sprintf("\t%v %v (%v)", candidate.Resource.Type, candidate.Resource.ResourceID, strings.Join(candidate.Reasons, ", ")))
+	}
+
+	if !*confirm {
+		return fmt.Errorf("garbage collection not confirmed, run again with -confirm to proceed")
+	}
+
+	store, err := operations.Load(*storePath)
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, candidate := range report.Candidates {
+		resourceIDs, err := teardown.Tree(cntx, candidate.Resource.ResourceID)
+		if err == nil {
+			err = teardown.Resources(cntx, journal, resourceIDs, store)
+		}
+		if err != nil {
+			failed++
+			logging.Warn("cannot collect resource", logging.Operation("gc"), logging.ResourceID(candidate.Resource.ResourceID), logging.Err(err))
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%v of %v resource(s) could not be collected", failed, len(report.Candidates))
+	}
+
+	utils.ConsoleOutput("Garbage collection completed!")
+	return nil
+}
@@ -0,0 +1,78 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/importer"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/logging"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/models"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/naming"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/state"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/utils"
+)
+
+// runImport records an existing account, capacity pool or volume and its children in the state journal and in a deployment spec
+func runImport(cntx context.Context, args []string) error {
+
+	flags := flag.NewFlagSet("import", flag.ContinueOnError)
+	resourceID := flags.String("resource-id", "", "resource id of the account, capacity pool or volume to import")
+	statePath := flags.String("state", state.Path(), "state journal the resources are recorded in")
+	specFile := flags.String("spec", "deployment.json", "deployment spec the resources are merged into, created when missing")
+	adopt := flags.Bool("adopt", false, "takes ownership of the resources so teardown deletes them, they are only tracked otherwise")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *resourceID == "" {
+		return fmt.Errorf("-resource-id must be provided")
+	}
+	// Imported names end up in the deployment spec, which is used to create resources
+	if err := naming.ValidateResourceID(*resourceID); err != nil {
+		return err
+	}
+
+	journal, err := state.Load(*statePath)
+	if err != nil {
+		return err
+	}
+
+	spec := &models.DeploymentSpec{Accounts: []models.AccountSpec{}}
+	if _, err := os.Stat(*specFile); err == nil {
+		spec, err = utils.ReadDeploymentSpecJSON(*specFile)
+		if err != nil {
+			return err
+		}
+	}
+
+	utils.ConsoleOutput(fmt.Sprintf("Importing %v...", *resourceID))
+	result, err := importer.Import(cntx, *resourceID, journal, spec, *adopt)
+	if err != nil {
+		return err
+	}
+
+	err = utils.WriteDeploymentSpecJSON(*specFile, spec)
+	if err != nil {
+		return err
+	}
+
+	for _, id := range result.ResourceIDs {
+		utils.ConsoleOutput(fmt.Sprintf("\tImported %v", id))
+	}
+	for _, warning := range result.Warnings {
+		logging.Warn(warning, logging.Operation("import"))
+	}
+	utils.ConsoleOutput(fmt.Sprintf("%v resource(s) recorded in %v and merged into %v", len(result.ResourceIDs), *statePath, *specFile))
+	if !*adopt {
+		utils.ConsoleOutput("Imported resources are not owned by this sample and are never deleted by teardown, import again with -adopt to take ownership")
+	}
+
+	return nil
+}
@@ -0,0 +1,64 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/kubernetes"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/utils"
+)
+
+// runK8sManifests writes Kubernetes PersistentVolume and PersistentVolumeClaim manifests for NFS volumes
+func runK8sManifests(cntx context.Context, args []string) error {
+
+	flags := flag.NewFlagSet("k8s-manifests", flag.ContinueOnError)
+	volumeIDs := flags.String("volume-ids", "", "comma separated resource ids of the volumes")
+	namespace := flags.String("namespace", "default", "namespace of the persistent volume claims")
+	reclaimPolicy := flags.String("reclaim-policy", "Retain", "reclaim policy of the persistent volumes, Retain or Delete")
+	output := flags.String("output", "", "file the manifests are written to, standard output when empty")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *volumeIDs == "" {
+		return fmt.Errorf("-volume-ids must be provided")
+	}
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			return fmt.Errorf("cannot create manifests file: %v", err)
+		}
+		defer file.Close()
+		w = file
+	}
+
+	ids := []string{}
+	for _, volumeID := range strings.Split(*volumeIDs, ",") {
+		ids = append(ids, strings.TrimSpace(volumeID))
+	}
+
+	err := kubernetes.WriteManifests(cntx, w, ids, kubernetes.Options{
+		Namespace:     *namespace,
+		ReclaimPolicy: *reclaimPolicy,
+	})
+	if err != nil {
+		return err
+	}
+
+	if *output != "" {
+		utils.ConsoleOutput(fmt.Sprintf("Manifests of %v volume(s) written to %v, apply them with kubectl apply -f %v", len(ids), *output, *output))
+	}
+
+	return nil
+}
@@ -0,0 +1,48 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/mount"
+)
+
+// runMountInfo prints ready to use mount commands for one or more volumes
+func runMountInfo(cntx context.Context, args []string) error {
+
+	flags := flag.NewFlagSet("mount-info", flag.ContinueOnError)
+	volumeIDs := flags.String("volume-ids", "", "comma separated resource ids of the volumes")
+	mountPoint := flags.String("mount-point", "", "directory the volume is mounted on, defaults to /mnt/<export path>")
+	fstab := flags.Bool("fstab", false, "also prints /etc/fstab entries")
+	systemd := flags.Bool("systemd", false, "also prints systemd mount units")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *volumeIDs == "" {
+		return fmt.Errorf("-volume-ids must be provided")
+	}
+
+	ids := strings.Split(*volumeIDs, ",")
+	if len(ids) > 1 && *mountPoint != "" {
+		return fmt.Errorf("-mount-point can only be used with a single volume")
+	}
+
+	for _, volumeID := range ids {
+		instructions, err := mount.GetInstructions(cntx, strings.TrimSpace(volumeID), *mountPoint)
+		if err != nil {
+			return fmt.Errorf("cannot get mount instructions of %v: %v", volumeID, err)
+		}
+
+		instructions.Print(*fstab, *systemd)
+	}
+
+	return nil
+}
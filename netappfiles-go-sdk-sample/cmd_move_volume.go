@@ -0,0 +1,63 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/drift"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/naming"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/sdkutils"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/state"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/utils"
+)
+
+// runMoveVolume moves a volume to another capacity pool, changing its service level to the target pool's one
+func runMoveVolume(cntx context.Context, args []string) error {
+
+	flags := flag.NewFlagSet("move-volume", flag.ContinueOnError)
+	volumeID := flags.String("volume-id", "", "resource id of the volume to move")
+	targetPoolID := flags.String("target-pool-id", "", "resource id of the capacity pool to move the volume to")
+	statePath := flags.String("state", state.Path(), "state journal where the volume id is updated when it is recorded")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *volumeID == "" || *targetPoolID == "" {
+		return fmt.Errorf("both -volume-id and -target-pool-id must be provided")
+	}
+	for _, resourceID := range []string{*volumeID, *targetPoolID} {
+		if err := naming.ValidateResourceID(resourceID); err != nil {
+			return err
+		}
+	}
+
+	journal, err := state.Load(*statePath)
+	if err != nil {
+		return err
+	}
+
+	utils.ConsoleOutput(fmt.Sprintf("Moving volume %v to capacity pool %v...", *volumeID, *targetPoolID))
+	volume, err := sdkutils.MoveANFVolumeToPool(cntx, *volumeID, *targetPoolID)
+	if err != nil {
+		return err
+	}
+	utils.ConsoleOutput(fmt.Sprintf("Volume successfully moved, new resource id: %v, service level: %v", *volume.ID, volume.ServiceLevel))
+
+	// The volume id changes with its pool
+	err = journal.Rename(*volumeID, *volume.ID)
+	if err != nil {
+		return err
+	}
+	err = drift.RecordApplied(cntx, journal, *volume.ID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,45 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/preflight"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/utils"
+)
+
+// runNetworkCheck reports whether a subnet is ready to host Azure NetApp Files volumes
+func runNetworkCheck(cntx context.Context, args []string) error {
+
+	flags := flag.NewFlagSet("network-check", flag.ContinueOnError)
+	subnetID := flags.String("subnet-id", "", "resource id of the subnet volumes will be created in")
+	location := flags.String("location", "", "region the account will be created in")
+	volumes := flags.Int("volumes", 1, "number of volumes planned in the subnet")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *subnetID == "" || *location == "" {
+		return fmt.Errorf("both -subnet-id and -location must be provided")
+	}
+
+	report, err := preflight.CheckNetwork(cntx, *subnetID, *location, *volumes)
+	if err != nil {
+		return err
+	}
+
+	utils.ConsoleOutput(fmt.Sprintf("Network pre-flight checks for subnet %v:", *subnetID))
+	report.Print()
+
+	if report.HasErrors() {
+		return fmt.Errorf("network pre-flight checks failed")
+	}
+
+	return nil
+}
@@ -0,0 +1,81 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/preflight"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/utils"
+)
+
+// runPreflight reports which quotas and resource limits a deployment spec would exceed, and which names are invalid or taken, before anything is created
+func runPreflight(cntx context.Context, args []string) error {
+
+	flags := flag.NewFlagSet("preflight", flag.ContinueOnError)
+	specFile := flags.String("spec", "", "deployment spec json file")
+	limitsFile := flags.String("limits", "", "optional json file with resource limits overriding the documented ones")
+	offline := flags.Bool("offline", false, "only checks the spec against the limits table, no Azure API is called")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *specFile == "" {
+		return fmt.Errorf("-spec must be provided")
+	}
+
+	spec, err := utils.ReadDeploymentSpecJSON(*specFile)
+	if err != nil {
+		return err
+	}
+
+	limits, err := preflight.LoadLimits(*limitsFile)
+	if err != nil {
+		return err
+	}
+
+	var report preflight.Report
+	if *offline {
+		report = preflight.CheckLimits(*spec, limits, nil)
+	} else {
+		report, err = preflight.CheckQuotas(cntx, *spec, limits)
+		if err != nil {
+			return err
+		}
+	}
+
+	nameReport := preflight.CheckNames(cntx, *spec, *offline)
+	report.Findings = append(report.Findings, nameReport.Findings...)
+
+	utils.ConsoleOutput(fmt.Sprintf("Quota, limit and name pre-flight checks for %v:", *specFile))
+	report.Print()
+
+	if !*offline && spec.SubnetID != "" {
+		plannedVolumes := 0
+		for _, account := range spec.Accounts {
+			for _, pool := range account.Pools {
+				plannedVolumes += len(pool.Volumes)
+			}
+		}
+
+		networkReport, err := preflight.CheckNetwork(cntx, spec.SubnetID, spec.Location, plannedVolumes)
+		if err != nil {
+			return err
+		}
+
+		utils.ConsoleOutput(fmt.Sprintf("Network pre-flight checks for subnet %v:", spec.SubnetID))
+		networkReport.Print()
+		report.Findings = append(report.Findings, networkReport.Findings...)
+	}
+
+	if report.HasErrors() {
+		return fmt.Errorf("pre-flight checks failed")
+	}
+
+	return nil
+}
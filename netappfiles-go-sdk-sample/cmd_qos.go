@@ -0,0 +1,145 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/drift"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/naming"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/qos"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/state"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/uri"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/utils"
+)
+
+// runQos shows volume throughput limits of a pool and, for manual QoS pools, allocates its throughput
+func runQos(cntx context.Context, args []string) error {
+
+	flags := flag.NewFlagSet("qos", flag.ContinueOnError)
+	poolID := flags.String("pool-id", "", "resource id of the capacity pool")
+	weights := flags.String("weights", "", "comma separated volume=weight pairs sharing the throughput left after targets")
+	targets := flags.String("targets", "", "comma separated volume=MiB/s pairs with explicit throughput targets")
+	apply := flags.Bool("apply", false, "applies the calculated allocation to the volumes")
+	statePath := flags.String("state", state.Path(), "state journal whose last applied properties are updated")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *poolID == "" {
+		return fmt.Errorf("-pool-id must be provided")
+	}
+	if err := naming.ValidateResourceID(*poolID); err != nil {
+		return err
+	}
+
+	pool, throughputs, err := qos.GetVolumeThroughputs(cntx, *poolID)
+	if err != nil {
+		return err
+	}
+
+	if pool.Size == nil {
+		return fmt.Errorf("cannot determine capacity pool size")
+	}
+
+	poolThroughput, err := qos.ThroughputMibps(pool.ServiceLevel, *pool.Size)
+	if err != nil {
+		return err
+	}
+
+	utils.ConsoleOutput(fmt.Sprintf("Capacity pool %v: %v, %v qos, %.1f MiB/s", uri.GetANFCapacityPool(*poolID), pool.ServiceLevel, pool.QosType, poolThroughput))
+	for _, volume := range throughputs {
+		utils.ConsoleOutput(fmt.Sprintf("\t%v: quota %v bytes, throughput %.1f MiB/s", uri.GetANFVolume(volume.VolumeID), volume.QuotaBytes, volume.ThroughputMibps))
+	}
+
+	if *weights == "" && *targets == "" {
+		return nil
+	}
+
+	// Requests use the volume ids returned by Azure so they match whatever the case of -pool-id
+	volumeIDs := map[string]string{}
+	for _, volume := range throughputs {
+		volumeIDs[strings.ToLower(uri.GetANFVolume(volume.VolumeID))] = volume.VolumeID
+	}
+
+	requests := []qos.Request{}
+	for _, pairs := range []struct {
+		value    string
+		isTarget bool
+	}{{*targets, true}, {*weights, false}} {
+		if pairs.value == "" {
+			continue
+		}
+		for _, pair := range strings.Split(pairs.value, ",") {
+			parts := strings.SplitN(pair, "=", 2)
+			if len(parts) != 2 {
+				return fmt.Errorf("invalid volume=value pair: %v", pair)
+			}
+			if err := naming.ValidateName(naming.Volume, parts[0]); err != nil {
+				return err
+			}
+			value, err := strconv.ParseFloat(parts[1], 64)
+			if err != nil {
+				return fmt.Errorf("invalid value for volume %v: %v", parts[0], err)
+			}
+
+			volumeID, found := volumeIDs[strings.ToLower(parts[0])]
+			if !found {
+				return fmt.Errorf("volume %v is not in capacity pool %v", parts[0], uri.GetANFCapacityPool(*poolID))
+			}
+
+			request := qos.Request{VolumeID: volumeID}
+			if pairs.isTarget {
+				request.TargetMibps = value
+			} else {
+				request.Weight = value
+			}
+			requests = append(requests, request)
+		}
+	}
+
+	allocation, err := qos.Allocate(qos.AvailableThroughputMibps(poolThroughput, throughputs, requests), requests)
+	if err != nil {
+		return err
+	}
+
+	utils.ConsoleOutput("Calculated allocation:")
+	for _, request := range requests {
+		utils.ConsoleOutput(fmt.Sprintf("\t%v: %.1f MiB/s", uri.GetANFVolume(request.VolumeID), allocation[request.VolumeID]))
+	}
+
+	if !*apply {
+		utils.ConsoleOutput("Run again with -apply to update the volumes")
+		return nil
+	}
+
+	journal, err := state.Load(*statePath)
+	if err != nil {
+		return err
+	}
+
+	utils.ConsoleOutput("Applying allocation...")
+	err = qos.ApplyAllocation(cntx, *poolID, allocation)
+
+	// Volumes may have been updated even when applying failed part way
+	allocatedIDs := []string{}
+	for volumeID := range allocation {
+		allocatedIDs = append(allocatedIDs, volumeID)
+	}
+	if recordErr := drift.RecordApplied(cntx, journal, allocatedIDs...); err == nil {
+		err = recordErr
+	}
+	if err != nil {
+		return err
+	}
+	utils.ConsoleOutput("Allocation successfully applied")
+
+	return nil
+}
@@ -0,0 +1,126 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/gc"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/logging"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/operations"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/state"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/utils"
+)
+
+// runReap tears down the resource trees whose expiresAt tag is in the past, once or every interval until interrupted,
+// this is destructive so it requires the -confirm flag
+func runReap(cntx context.Context, args []string) error {
+
+	flags := flag.NewFlagSet("reap", flag.ContinueOnError)
+	location := flags.String("location", "", "only looks at accounts of this region, and their children")
+	exclusionTags := flags.String("exclude-tags", gc.DefaultExclusionTagName, "comma separated tag names protecting a resource and its parents")
+	statePath := flags.String("state", state.Path(), "state journal deleted resources are removed from")
+	storePath := flags.String("operations", operations.DefaultStorePath, "operation store deletions in progress are recorded in, they can be resumed with the status command")
+	reportFile := flags.String("report", "", "file each run report is appended to as a json line")
+	interval := flags.Duration("interval", 15*time.Minute, "time between two runs")
+	once := flags.Bool("once", false, "runs a single time and exits")
+	confirm := flags.Bool("confirm", false, "confirms expired resources can be deleted")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	store, err := operations.Load(*storePath)
+	if err != nil {
+		return err
+	}
+
+	reap := func() error {
+		report, err := gc.Reap(cntx, *statePath, store, *location, splitList(*exclusionTags), *confirm)
+		printReapReport(report, *confirm)
+		if *reportFile != "" {
+			if writeErr := appendReapReport(*reportFile, report); writeErr != nil {
+				return writeErr
+			}
+		}
+		if err != nil {
+			return err
+		}
+		if len(report.Failed) > 0 {
+			return fmt.Errorf("%v resource(s) could not be deleted", len(report.Failed))
+		}
+		return nil
+	}
+
+	if *once {
+		return reap()
+	}
+
+	ticker := time.NewTicker(*interval)
+	defer ticker.Stop()
+
+	for {
+		// A failed run is reported and retried on the next one
+		if err := reap(); err != nil {
+			logging.Error("an error ocurred while reaping expired resources", logging.Operation("reap"), logging.Err(err))
+		}
+
+		select {
+		case <-cntx.Done():
+			return cntx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func printReapReport(report gc.ReapReport, confirmed bool) {
+
+	utils.ConsoleOutput(fmt.Sprintf("Reaper run at %v: %v expired, %v protected, %v removed, %v failed",
+		report.StartedAt.Format(time.RFC3339), len(report.Expired), len(report.Protected), len(report.Removed), len(report.Failed)))
+
+	for _, resourceID := range report.Protected {
+		utils.ConsoleOutput(fmt.Sprintf("\tProtected %v", resourceID))
+	}
+	if !confirmed {
+		for _, resourceID := range report.Expired {
+			utils.ConsoleOutput(fmt.Sprintf("\tExpired %v", resourceID))
+		}
+		if len(report.Expired) > 0 {
+			utils.ConsoleOutput("\tNothing was deleted, run again with -confirm to proceed")
+		}
+	}
+	for _, resourceID := range report.Removed {
+		utils.ConsoleOutput(fmt.Sprintf("\tRemoved %v", resourceID))
+	}
+	for _, failure := range report.Failed {
+		logging.Warn("cannot delete expired resource, the rest of its tree is kept", logging.Operation("reap"), logging.ResourceID(failure.ResourceID), logging.String("error", failure.Error))
+	}
+}
+
+func appendReapReport(path string, report gc.ReapReport) error {
+
+	reportJSON, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("cannot serialize reaper report: %v", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("cannot open reaper report: %v", err)
+	}
+	defer file.Close()
+
+	_, err = file.Write(append(reportJSON, '\n'))
+	if err != nil {
+		return fmt.Errorf("cannot write reaper report: %v", err)
+	}
+
+	return nil
+}
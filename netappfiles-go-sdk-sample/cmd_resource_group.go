@@ -0,0 +1,69 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/sdkutils"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/state"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/utils"
+)
+
+// runResourceGroup creates a resource group when it does not exist or deletes one created by this sample
+func runResourceGroup(cntx context.Context, args []string) error {
+
+	flags := flag.NewFlagSet("resource-group", flag.ContinueOnError)
+	name := flags.String("name", "", "name of the resource group")
+	region := flags.String("location", location, "region the resource group is created in")
+	deleteGroup := flags.Bool("delete", false, "deletes the resource group, only groups created by this sample can be deleted")
+	statePath := flags.String("state", state.Path(), "state journal the resource group is recorded in")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *name == "" {
+		return fmt.Errorf("-name must be provided")
+	}
+
+	config, err := utils.ReadAzureBasicInfoJSON(os.Getenv("AZURE_AUTH_LOCATION"))
+	if err != nil {
+		return fmt.Errorf("cannot get non-sensitive info from AzureAuthFile: %v", err)
+	}
+	resourceGroupID := fmt.Sprintf("/subscriptions/%v/resourceGroups/%v", *config.SubscriptionID, *name)
+
+	journal, err := state.Load(*statePath)
+	if err != nil {
+		return err
+	}
+
+	if *deleteGroup {
+		utils.ConsoleOutput(fmt.Sprintf("Deleting resource group %v and everything it contains...", *name))
+		err = sdkutils.DeleteResourceGroup(cntx, *name)
+		if err != nil {
+			return err
+		}
+		utils.ConsoleOutput("Resource group successfully deleted")
+
+		return journal.Remove(resourceGroupID)
+	}
+
+	_, created, err := sdkutils.EnsureResourceGroup(cntx, *region, *name, sampleTags)
+	if err != nil {
+		return err
+	}
+
+	if !created {
+		utils.ConsoleOutput(fmt.Sprintf("Resource group %v already exists", *name))
+		return nil
+	}
+	utils.ConsoleOutput(fmt.Sprintf("Resource group successfully created, resource id: %v", resourceGroupID))
+
+	return journal.Record(resourceGroupID, state.ResourceGroupType)
+}
@@ -0,0 +1,108 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/naming"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/sdkutils"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/uri"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/utils"
+	"github.com/Azure/go-autorest/autorest/to"
+)
+
+// runRevert reverts a volume to a snapshot, this is destructive so it requires the -confirm flag
+func runRevert(cntx context.Context, args []string) error {
+
+	flags := flag.NewFlagSet("revert", flag.ContinueOnError)
+	volumeID := flags.String("volume-id", "", "resource id of the volume to revert")
+	snapshotID := flags.String("snapshot-id", "", "resource id of the snapshot to revert the volume to")
+	confirm := flags.Bool("confirm", false, "confirms that snapshots newer than the selected one will be lost")
+	safetyBackup := flags.Bool("safety-backup", false, "backs up the current state of the volume before reverting it, backups must be enabled on the volume")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *volumeID == "" || *snapshotID == "" {
+		return fmt.Errorf("both -volume-id and -snapshot-id must be provided")
+	}
+	for _, resourceID := range []string{*volumeID, *snapshotID} {
+		if err := naming.ValidateResourceID(resourceID); err != nil {
+			return err
+		}
+	}
+
+	if !*confirm {
+		newerSnapshots, err := sdkutils.GetANFSnapshotsNewerThan(cntx, *volumeID, *snapshotID)
+		if err != nil {
+			return err
+		}
+
+		utils.ConsoleOutput(fmt.Sprintf("Reverting volume %v to snapshot %v would discard all changes made after the snapshot, this cannot be undone.", *volumeID, *snapshotID))
+		for _, s := range newerSnapshots {
+			utils.ConsoleOutput(fmt.Sprintf("\tsnapshot %v would be lost", *s.ID))
+		}
+		if *safetyBackup {
+			utils.ConsoleOutput("The current state of the volume would be backed up first.")
+		}
+
+		return fmt.Errorf("revert not confirmed, run again with -confirm to proceed")
+	}
+
+	if *safetyBackup {
+		err := createSafetyBackup(cntx, *volumeID)
+		if err != nil {
+			return err
+		}
+	}
+
+	utils.ConsoleOutput(fmt.Sprintf("Reverting volume %v to snapshot %v...", *volumeID, *snapshotID))
+	err := sdkutils.RevertANFVolume(cntx, *volumeID, *snapshotID)
+	if err != nil {
+		return err
+	}
+	utils.ConsoleOutput("Volume successfully reverted")
+
+	return nil
+}
+
+// createSafetyBackup backs up the current state of a volume before it is reverted, backups are kept in the
+// backup vault of the account and are not affected by the revert, unlike snapshots newer than the selected one
+func createSafetyBackup(cntx context.Context, volumeID string) error {
+
+	volume, err := sdkutils.GetANFVolumeByID(cntx, volumeID)
+	if err != nil {
+		return err
+	}
+
+	if volume.VolumeProperties == nil || volume.DataProtection == nil || volume.DataProtection.Backup == nil || !to.Bool(volume.DataProtection.Backup.BackupEnabled) {
+		return fmt.Errorf("backups are not enabled on volume %v, enable them (EnableANFVolumeBackup) or revert without -safety-backup", uri.GetANFVolume(volumeID))
+	}
+
+	backupName := fmt.Sprintf("pre-revert-%v", time.Now().UTC().Format("20060102-150405"))
+
+	utils.ConsoleOutput(fmt.Sprintf("Backing up volume %v as %v before reverting it...", uri.GetANFVolume(volumeID), backupName))
+	backup, err := sdkutils.CreateANFBackup(
+		cntx,
+		to.String(volume.Location),
+		uri.GetResourceGroup(volumeID),
+		uri.GetANFAccount(volumeID),
+		uri.GetANFCapacityPool(volumeID),
+		uri.GetANFVolume(volumeID),
+		backupName,
+		"pre-revert",
+	)
+	if err != nil {
+		return fmt.Errorf("the safety backup failed, the volume was not reverted: %v", err)
+	}
+	utils.ConsoleOutput(fmt.Sprintf("Safety backup %v created, it can be restored into a new volume with CreateANFVolumeFromBackup", to.String(backup.ID)))
+
+	return nil
+}
@@ -0,0 +1,125 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/drift"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/logging"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/operations"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/state"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/utils"
+)
+
+// runStatus reports the long-running operations left in progress by a previous run and optionally resumes
+// polling them until they complete, completed operations are reflected in the state journal
+func runStatus(cntx context.Context, args []string) error {
+
+	flags := flag.NewFlagSet("status", flag.ContinueOnError)
+	storePath := flags.String("operations", operations.DefaultStorePath, "operation store listing the operations in progress")
+	statePath := flags.String("state", state.Path(), "state journal updated when an operation completes")
+	id := flags.String("id", "", "only reports the operation with this id")
+	wait := flags.Bool("wait", false, "resumes polling until the operations complete")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	store, err := operations.Load(*storePath)
+	if err != nil {
+		return err
+	}
+
+	handles := store.Handles
+	if *id != "" {
+		handle, found := store.Get(*id)
+		if !found {
+			return fmt.Errorf("operation %v is not recorded in %v", *id, *storePath)
+		}
+		handles = []operations.Handle{handle}
+	}
+
+	if len(handles) == 0 {
+		utils.ConsoleOutput(fmt.Sprintf("No operations in progress recorded in %v", *storePath))
+		return nil
+	}
+
+	journal, err := state.Load(*statePath)
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, handle := range handles {
+		age := time.Since(handle.StartedAt).Round(time.Second)
+
+		if *wait {
+			utils.ConsoleOutput(fmt.Sprintf("Resuming %v of %v %v started %v ago...", handle.Operation, handle.ResourceType, handle.ResourceID, age))
+			err = operations.Wait(cntx, handle)
+			if err != nil && cntx.Err() != nil {
+				return err
+			}
+		}
+
+		// Operations are polled once without -wait, after a failed wait polling again tells a failed operation
+		// from a polling error, the handle is only kept in the latter case
+		if !*wait || err != nil {
+			var done bool
+			var status string
+			done, status, err = operations.Poll(cntx, handle)
+			if !done {
+				if err != nil {
+					logging.Warn(fmt.Sprintf("cannot poll operation %v", handle.ID), logging.Operation(handle.Operation), logging.ResourceID(handle.ResourceID), logging.Err(err))
+					failed++
+					continue
+				}
+				utils.ConsoleOutput(fmt.Sprintf("\t%v %v %v %v: %v, started %v ago", handle.ID, handle.Operation, handle.ResourceType, handle.ResourceID, status, age))
+				continue
+			}
+		}
+
+		if err != nil {
+			logging.Error(fmt.Sprintf("operation %v failed", handle.ID), logging.Operation(handle.Operation), logging.ResourceID(handle.ResourceID), logging.Err(err))
+			failed++
+		} else {
+			utils.ConsoleOutput(fmt.Sprintf("\t%v %v %v %v: completed", handle.ID, handle.Operation, handle.ResourceType, handle.ResourceID))
+			err = completeOperation(cntx, journal, handle)
+			if err != nil {
+				return err
+			}
+		}
+
+		err = store.Remove(handle.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%v operation(s) failed or could not be polled", failed)
+	}
+
+	return nil
+}
+
+// completeOperation records a created resource in the state journal, with its live properties as the last applied
+// ones drift detection compares it with, or drops a deleted one
+func completeOperation(cntx context.Context, journal *state.Journal, handle operations.Handle) error {
+
+	if handle.Operation == operations.Delete {
+		return journal.Remove(handle.ResourceID)
+	}
+
+	err := journal.Record(handle.ResourceID, handle.ResourceType)
+	if err != nil {
+		return err
+	}
+
+	return drift.RecordApplied(cntx, journal, handle.ResourceID)
+}
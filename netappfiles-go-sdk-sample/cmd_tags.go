@@ -0,0 +1,123 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/drift"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/gc"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/logging"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/state"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/tagging"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/utils"
+)
+
+// runTags adds, removes or replaces tags on the accounts, capacity pools, volumes and snapshot policies selected
+// by resource id, name glob or existing tags
+func runTags(cntx context.Context, args []string) error {
+
+	flags := flag.NewFlagSet("tags", flag.ContinueOnError)
+	resourceIDs := flags.String("ids", "", "comma separated resource ids of the resources to tag")
+	nameGlob := flags.String("name", "", "glob the resource names must match, e.g. dev-*")
+	tagFilter := flags.String("tag", "", "comma separated key=value tags the resources must have, a value of * matches any value")
+	types := flags.String("types", "", "comma separated resource types to select: account, capacityPool, volume, snapshotPolicy")
+	location := flags.String("location", "", "only selects accounts of this region, and their children")
+	add := flags.String("add", "", "comma separated key=value tags to add or overwrite")
+	remove := flags.String("remove", "", "comma separated tag keys to remove")
+	replace := flags.Bool("replace", false, "replaces all tags with the ones of -add, except the ownership and lifetime tags set by the sample")
+	propagate := flags.Bool("propagate", false, "also applies the change to the children of the selected resources")
+	dryRun := flags.Bool("dry-run", false, "lists the changes without applying them")
+	statePath := flags.String("state", state.Path(), "state journal whose last applied properties are updated")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *resourceIDs == "" && *nameGlob == "" && *tagFilter == "" {
+		return fmt.Errorf("at least one of -ids, -name or -tag must be provided")
+	}
+	if *add == "" && *remove == "" && !*replace {
+		return fmt.Errorf("at least one of -add, -remove or -replace must be provided")
+	}
+
+	selector := tagging.Selector{NameGlob: *nameGlob, ResourceIDs: splitList(*resourceIDs), Types: splitList(*types)}
+	filter, err := tagging.ParseTags(*tagFilter)
+	if err != nil {
+		return err
+	}
+	selector.TagFilter = filter
+
+	// Replacing must not drop the tags that teardown, resource-group, gc and reap rely on
+	change := tagging.Change{
+		Remove:   splitList(*remove),
+		Replace:  *replace,
+		Preserve: gc.LifecycleTagNames,
+	}
+	change.Add, err = tagging.ParseTags(*add)
+	if err != nil {
+		return err
+	}
+
+	utils.ConsoleOutput("Listing accounts, snapshot policies, capacity pools and volumes...")
+	inventory, err := tagging.Inventory(cntx, *location)
+	if err != nil {
+		return err
+	}
+
+	selected := tagging.Select(inventory, selector)
+	updates := tagging.Plan(inventory, selected, change, *propagate)
+	utils.ConsoleOutput(fmt.Sprintf("%v resource(s) selected, %v to update", len(selected), len(updates)))
+	for _, update := range updates {
+		utils.ConsoleOutput(fmt.Sprintf("\t%v %v: %v", update.Resource.Type, update.Resource.ResourceID, update.Describe()))
+	}
+
+	if *dryRun || len(updates) == 0 {
+		return nil
+	}
+
+	journal, err := state.Load(*statePath)
+	if err != nil {
+		return err
+	}
+
+	failures := tagging.Execute(cntx, updates)
+	failed := map[string]bool{}
+	for _, failure := range failures {
+		failed[failure.Update.Resource.ResourceID] = true
+		logging.Warn("cannot update tags", logging.Operation("tags"), logging.ResourceID(failure.Update.Resource.ResourceID), logging.Err(failure.Err))
+	}
+
+	updatedIDs := []string{}
+	for _, update := range updates {
+		if !failed[update.Resource.ResourceID] {
+			updatedIDs = append(updatedIDs, update.Resource.ResourceID)
+		}
+	}
+	err = drift.RecordApplied(cntx, journal, updatedIDs...)
+	if err != nil {
+		return err
+	}
+	if len(failures) > 0 {
+		return fmt.Errorf("%v of %v update(s) failed", len(failures), len(updates))
+	}
+
+	utils.ConsoleOutput("Tags updated")
+	return nil
+}
+
+// splitList splits a comma separated list, ignoring empty items
+func splitList(list string) []string {
+	items := []string{}
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,77 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/operations"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/state"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/teardown"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/utils"
+)
+
+// runTeardown deletes every resource owned by the state journal, this is destructive so it requires the -confirm flag
+func runTeardown(cntx context.Context, args []string) error {
+
+	flags := flag.NewFlagSet("teardown", flag.ContinueOnError)
+	statePath := flags.String("state", state.Path(), "state journal listing the resources to delete")
+	confirm := flags.Bool("confirm", false, "confirms the recorded resources can be deleted")
+	storePath := flags.String("operations", operations.DefaultStorePath, "operation store deletions in progress are recorded in, they can be resumed with the status command")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	journal, err := state.Load(*statePath)
+	if err != nil {
+		return err
+	}
+
+	if len(journal.Entries) == 0 {
+		utils.ConsoleOutput(fmt.Sprintf("No resources recorded in %v", *statePath))
+		return nil
+	}
+
+	owned, kept := teardown.Order(journal)
+	if !*confirm {
+		utils.ConsoleOutput(fmt.Sprintf("The following resources recorded in %v would be deleted:", *statePath))
+		for _, entry := range owned {
+			if entry.Imported {
+				utils.ConsoleOutput(fmt.Sprintf("\t%v %v (imported, adopted)", entry.Type, entry.ResourceID))
+				continue
+			}
+			utils.ConsoleOutput(fmt.Sprintf("\t%v %v", entry.Type, entry.ResourceID))
+		}
+		if len(kept) > 0 {
+			utils.ConsoleOutput("The following imported resources are not adopted and would be kept:")
+			for _, entry := range kept {
+				utils.ConsoleOutput(fmt.Sprintf("\t%v %v", entry.Type, entry.ResourceID))
+			}
+		}
+
+		return fmt.Errorf("teardown not confirmed, run again with -confirm to proceed")
+	}
+
+	store, err := operations.Load(*storePath)
+	if err != nil {
+		return err
+	}
+
+	utils.ConsoleOutput(fmt.Sprintf("Deleting resources recorded in %v...", *statePath))
+	err = teardown.Run(cntx, journal, store)
+	if err != nil {
+		return err
+	}
+	if len(kept) > 0 {
+		utils.ConsoleOutput(fmt.Sprintf("Teardown completed, %v imported resource(s) not adopted were kept", len(kept)))
+		return nil
+	}
+	utils.ConsoleOutput("Teardown completed!")
+
+	return nil
+}
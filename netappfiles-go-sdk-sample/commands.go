@@ -0,0 +1,166 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// Commands that can be executed instead of the end-to-end sample,
+// e.g. go run . revert -volume-id <id> -snapshot-id <id> -confirm
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/logging"
+)
+
+// command describes an operation available from the command line
+type command struct {
+	description string
+	run         func(cntx context.Context, args []string) error
+}
+
+// exitCodeError is returned by commands ending with an exit code other than 1, e.g. for CI pipelines
+type exitCodeError struct {
+	code int
+	err  error
+}
+
+func (e exitCodeError) Error() string {
+	return e.err.Error()
+}
+
+var (
+	commands = map[string]command{
+		"advise": {
+			description: "Reports over-provisioned capacity pools and estimated savings",
+			run:         runAdvise,
+		},
+		"autoscale": {
+			description: "Grows volume quotas, and their pools, when usage crosses a threshold",
+			run:         runAutoscale,
+		},
+		"backups": {
+			description: "Lists the backups of a volume with their size and creation date",
+			run:         runBackups,
+		},
+		"bootstrap": {
+			description: "Creates the resource group, virtual network and delegated subnet when missing",
+			run:         runBootstrap,
+		},
+		"drift": {
+			description: "Reports differences between managed resources and the spec or last applied state",
+			run:         runDrift,
+		},
+		"estimate": {
+			description: "Estimates the monthly cost of a deployment spec without calling Azure",
+			run:         runEstimate,
+		},
+		"export": {
+			description: "Exports an account and everything it contains as an ARM or Bicep template",
+			run:         runExport,
+		},
+		"gc": {
+			description: "Finds abandoned resources by tags, age and usage and deletes them",
+			run:         runGc,
+		},
+		"import": {
+			description: "Records an existing account, pool or volume in the state journal and a deployment spec",
+			run:         runImport,
+		},
+		"k8s-manifests": {
+			description: "Writes Kubernetes PersistentVolume and PersistentVolumeClaim manifests for volumes",
+			run:         runK8sManifests,
+		},
+		"mount-info": {
+			description: "Prints mount commands, fstab entries and systemd units for volumes",
+			run:         runMountInfo,
+		},
+		"move-volume": {
+			description: "Moves a volume to another capacity pool of the same account",
+			run:         runMoveVolume,
+		},
+		"network-check": {
+			description: "Verifies a subnet is ready to host Azure NetApp Files volumes",
+			run:         runNetworkCheck,
+		},
+		"preflight": {
+			description: "Reports the quotas and resource limits a deployment spec would exceed",
+			run:         runPreflight,
+		},
+		"qos": {
+			description: "Shows volume throughput limits and allocates manual QoS pool throughput",
+			run:         runQos,
+		},
+		"reap": {
+			description: "Tears down resource trees whose expiresAt tag is in the past, once or in a loop",
+			run:         runReap,
+		},
+		"resource-group": {
+			description: "Creates a resource group or deletes one created by this sample",
+			run:         runResourceGroup,
+		},
+		"revert": {
+			description: "Reverts a volume to one of its snapshots",
+			run:         runRevert,
+		},
+		"status": {
+			description: "Reports, or resumes polling, long-running operations left in progress by a previous run",
+			run:         runStatus,
+		},
+		"tags": {
+			description: "Adds, removes or replaces tags on accounts, pools, volumes and snapshot policies",
+			run:         runTags,
+		},
+		"teardown": {
+			description: "Deletes the resources recorded in the state journal",
+			run:         runTeardown,
+		},
+	}
+)
+
+// runCommand executes the named command and returns the process exit code
+func runCommand(cntx context.Context, name string, args []string) int {
+
+	if name == "help" || name == "-h" || name == "--help" {
+		printUsage()
+		return 0
+	}
+
+	cmd, found := commands[name]
+	if !found {
+		printUsage()
+		return 1
+	}
+
+	err := cmd.run(cntx, args)
+	if err != nil {
+		logging.Error(fmt.Sprintf("an error ocurred while running %v", name), logging.Operation(name), logging.Err(err))
+		var codeErr exitCodeError
+		if errors.As(err, &codeErr) {
+			return codeErr.code
+		}
+		return 1
+	}
+
+	return 0
+}
+
+// printUsage lists all available commands
+func printUsage() {
+	fmt.Println("Usage: go run . [command] [flags]")
+	fmt.Println("Runs the end-to-end sample when no command is provided, available commands:")
+
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		fmt.Printf("  %-16v %v\n", name, commands[name].description)
+	}
+}
@@ -6,7 +6,9 @@
 // This sample code creates an Azure Netapp Files Account, a Capacity Pool,
 // and two volumes, one NFSv3 and one NFSv4.1, then it takes a snapshot
 // of the first volume (NFSv3) and performs clean up if the variable
-// shouldCleanUp is changed to true.
+// shouldCleanUp is changed to true. When shouldBootstrapNetwork is
+// changed to true, the resource group, virtual network and delegated
+// subnet are created first if they do not exist.
 //
 // This package uses go-haikunator package (https://github.com/yelinaung/go-haikunator)
 // port from Python's haikunator module and therefore used here just for sample simplification,
@@ -21,39 +23,73 @@ import (
 	"os"
 	"time"
 
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/bootstrap"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/drift"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/gc"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/graph"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/logging"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/mount"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/naming"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/operations"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/preflight"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/progress"
 	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/sdkutils"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/state"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/teardown"
 	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/utils"
 	"github.com/Azure/azure-sdk-for-go/profiles/latest/netapp/mgmt/netapp"
 	"github.com/Azure/go-autorest/autorest/to"
 	"github.com/yelinaung/go-haikunator"
 )
 
-const (
-	virtualNetworksApiVersion string = "2019-09-01"
-)
-
 var (
-	shouldCleanUp           bool   = false
-	location                string = "eastus"
-	resourceGroupName       string = "anf01-rg"
-	vnetResourceGroupName   string = "anf01-rg"
-	vnetName                string = "vnet-01"
-	subnetName              string = "anf-sn"
-	anfAccountName          string = haikunator.New(time.Now().UTC().UnixNano()).Haikunate()
-	capacityPoolName        string = "Pool01"
-	serviceLevel            string = "Standard"          // Valid service levels are Standard, Premium and Ultra
-	capacityPoolSizeBytes   int64  = 4398046511104       // 4TiB (minimum capacity pool size)
-	volumeSizeBytes         int64  = 107374182400        // 100GiB (minimum volume size)
-	nfsv3ProtocolTypes             = []string{"NFSv3"}   // Multiple NFS protocol types are not supported at the moment this sample was written
-	nfsv41ProtocolTypes            = []string{"NFSv4.1"} // Multiple NFS protocol types are not supported at the moment this sample was written
-	nfsv3VolumeName         string = fmt.Sprintf("NFSv3-Vol-%v-%v", anfAccountName, capacityPoolName)
-	nfsv3SnapshotName       string = fmt.Sprintf("Snapshot-NFSv3-Vol-%v-%v", anfAccountName, capacityPoolName)
-	nfsv3VolumeNameFromSnap string = fmt.Sprintf("NFSv3-FromSnapshot-Vol-%v-%v", anfAccountName, capacityPoolName)
-	nfsv41VolumeName        string = fmt.Sprintf("NFSv41-Vol-%v-%v", anfAccountName, capacityPoolName)
+	shouldCleanUp          bool   = false
+	shouldBootstrapNetwork bool   = false
+	stateJournalPath       string = state.Path() // Set ANF_STATE_PATH to use another journal, commands use it too
+	operationStorePath     string = operations.DefaultStorePath
+	progressHistoryPath    string = progress.DefaultHistoryPath
+	location               string = "eastus"
+	resourceGroupName      string = "anf01-rg"
+	vnetResourceGroupName  string = "anf01-rg"
+	vnetName               string = "vnet-01"
+	subnetName             string = "anf-sn"
+	vnetAddressSpace              = []string{"10.0.0.0/16"} // Only used when the virtual network is created by the bootstrap
+	subnetAddressPrefix    string = "10.0.1.0/24"           // Only used when the subnet is created by the bootstrap
+	anfAccountName         string = haikunator.New(time.Now().UTC().UnixNano()).Haikunate()
+	capacityPoolName       string = "Pool01"
+	serviceLevel           string = "Standard"          // Valid service levels are Standard, Premium and Ultra
+	capacityPoolSizeBytes  int64  = 4398046511104       // 4TiB (minimum capacity pool size)
+	volumeSizeBytes        int64  = 107374182400        // 100GiB (minimum volume size)
+	nfsv3ProtocolTypes            = []string{"NFSv3"}   // Multiple NFS protocol types are not supported at the moment this sample was written
+	nfsv41ProtocolTypes           = []string{"NFSv4.1"} // Multiple NFS protocol types are not supported at the moment this sample was written
+	environmentName        string = "dev"
+	applicationName        string = "anfsample"
+	// Volume and snapshot names are built from this convention, e.g. dev-anfsample-NFSv3-1, any naming.Convention can be plugged in
+	namingConvention naming.Convention = naming.TemplateConvention{
+		Template: naming.DefaultTemplate,
+		Templates: map[naming.ResourceType]string{
+			naming.Snapshot: "{volume}-snap-{n}",
+		},
+		Defaults: naming.Fields{
+			"env": environmentName,
+			"app": applicationName,
+		},
+	}
+	nfsv3VolumeName         string = ""
+	nfsv3SnapshotName       string = ""
+	nfsv3VolumeNameFromSnap string = ""
+	nfsv41VolumeName        string = ""
 	sampleTags                     = map[string]*string{
 		"Author":  to.StringPtr("ANF Go SDK Sample"),
 		"Service": to.StringPtr("Azure Netapp Files"),
+		// Creation time, used by the gc command to find old resources
+		gc.CreatedAtTagName: gc.CreatedAtTag(),
 	}
+	// Maximum number of resources created at the same time, 0 removes the limit
+	maxParallelOperations int = 2
+	// When set, resources are tagged with an expiry time enforced by the reap command, e.g. 24 * time.Hour,
+	// ANF_RESOURCE_TTL overrides it
+	resourceTimeToLive        time.Duration = 0
 	exitCode                  int
 	snapshotID                string = ""
 	nfsv3VolumeID             string = ""
@@ -61,12 +97,48 @@ var (
 	nfsv3VolumeFromSnapshotID string = ""
 	capacityPoolID            string = ""
 	accountID                 string = ""
+	journal                   *state.Journal
+	operationStore            *operations.Store
+	bootstrappedResourceIDs   []string
 )
 
 func main() {
 
 	cntx := context.Background()
 
+	// Logging is configured from the ANF_LOG_LEVEL, ANF_LOG_FORMAT and ANF_LOG_QUIET environment variables,
+	// every record of this run carries the same correlation id
+	logOptions, err := logging.FromEnvironment()
+	logging.SetDefault(logging.New(logOptions).With(logging.CorrelationID(logging.NewCorrelationID())))
+	if err != nil {
+		logging.Warn("invalid logging configuration, defaults are used instead", logging.Err(err))
+	}
+
+	// Reporting the progress of long-running operations, the remaining time is estimated from previous runs,
+	// the live terminal display is replaced by log records in JSON mode and nothing is reported in quiet mode
+	if !logOptions.Quiet {
+		err = progress.Enable(progressHistoryPath, !logOptions.JSON)
+		if err != nil {
+			logging.Warn("progress of long-running operations is not reported", logging.Err(err))
+		}
+	}
+
+	// Ephemeral environments expire, the reap command tears them down afterwards. The expiry tag is set before
+	// running a command so the resources created by commands expire too
+	resourceTimeToLive, err = gc.TimeToLive(resourceTimeToLive)
+	if err != nil {
+		logging.Error("an error ocurred reading the resource time to live", logging.Err(err))
+		os.Exit(1)
+	}
+	if resourceTimeToLive > 0 {
+		sampleTags[gc.ExpiresAtTagName] = gc.ExpiresAtTag(resourceTimeToLive)
+	}
+
+	// Running a single command instead of the end-to-end sample
+	if len(os.Args) > 1 {
+		os.Exit(runCommand(cntx, os.Args[1], os.Args[2:]))
+	}
+
 	// Cleanup and exit handling
 	defer func() { exit(cntx); os.Exit(exitCode) }()
 
@@ -75,12 +147,27 @@ func main() {
 	// Getting subscription ID from authentication file
 	config, err := utils.ReadAzureBasicInfoJSON(os.Getenv("AZURE_AUTH_LOCATION"))
 	if err != nil {
-		utils.ConsoleOutput(fmt.Sprintf("an error ocurred getting non-sensitive info from AzureAuthFile: %v", err))
+		logging.Error("an error ocurred getting non-sensitive info from AzureAuthFile", logging.Err(err))
+		exitCode = 1
+		return
+	}
+
+	// Loading the state journal where every created resource is recorded
+	journal, err = state.Load(stateJournalPath)
+	if err != nil {
+		logging.Error("an error ocurred loading the state journal", logging.Err(err))
+		exitCode = 1
+		return
+	}
+
+	// Loading the store of long-running operations, an interrupted creation or deletion can be resumed with the status command
+	operationStore, err = operations.Load(operationStorePath)
+	if err != nil {
+		logging.Error("an error ocurred loading the operation store", logging.Err(err))
 		exitCode = 1
 		return
 	}
 
-	// Checking if subnet exists before any other operation starts
 	subnetID := fmt.Sprintf("/subscriptions/%v/resourceGroups/%v/providers/Microsoft.Network/virtualNetworks/%v/subnets/%v",
 		*config.SubscriptionID,
 		vnetResourceGroupName,
@@ -88,278 +175,518 @@ func main() {
 		subnetName,
 	)
 
-	utils.ConsoleOutput(fmt.Sprintf("Checking if subnet %v exists.", subnetID))
+	// Creating the resource groups, virtual network and delegated subnet when they do not exist
+	if shouldBootstrapNetwork {
+		logging.Info("Creating missing network resources...")
+		subnetID, bootstrappedResourceIDs, err = bootstrap.Network(
+			cntx,
+			*config.SubscriptionID,
+			bootstrap.NetworkSpec{
+				Location:            location,
+				ResourceGroupName:   vnetResourceGroupName,
+				VnetName:            vnetName,
+				VnetAddressSpace:    vnetAddressSpace,
+				SubnetName:          subnetName,
+				SubnetAddressPrefix: subnetAddressPrefix,
+				Tags:                sampleTags,
+			},
+			journal,
+		)
+		if err != nil {
+			logging.Error("an error ocurred while creating network resources", logging.Err(err))
+			exitCode = 1
+			return
+		}
 
-	_, err = sdkutils.GetResourceByID(cntx, subnetID, virtualNetworksApiVersion)
-	if err != nil {
-		if string(err.Error()) == "NotFound" {
-			utils.ConsoleOutput(fmt.Sprintf("error: subnet %v not found: %v", subnetID, err))
-		} else {
-			utils.ConsoleOutput(fmt.Sprintf("error: an error ocurred trying to check if %v exists: %v", subnetID, err))
+		createdResourceIDs, err := bootstrap.ResourceGroup(cntx, *config.SubscriptionID, location, resourceGroupName, sampleTags, journal)
+		bootstrappedResourceIDs = append(bootstrappedResourceIDs, createdResourceIDs...)
+		if err != nil {
+			logging.Error("an error ocurred while creating resource group", logging.Err(err))
+			exitCode = 1
+			return
 		}
+		logging.Info(fmt.Sprintf("%v network resource(s) created", len(bootstrappedResourceIDs)))
+	}
+
+	// Checking the subnet is ready for Azure NetApp Files before any other operation starts
+
+	logging.Info("Running network pre-flight checks...", logging.ResourceID(subnetID))
 
+	// Three volumes are created by this sample, each one needs an IP address from the subnet
+	networkReport, err := preflight.CheckNetwork(cntx, subnetID, location, 3)
+	if err != nil {
+		logging.Error("an error ocurred running network pre-flight checks", logging.ResourceID(subnetID), logging.Err(err))
 		exitCode = 1
 		return
 	}
 
+	networkReport.Print()
+	if networkReport.HasErrors() {
+		logging.Error("network pre-flight checks failed, fix the errors above before running this sample", logging.ResourceID(subnetID))
+		exitCode = 1
+		return
+	}
+
+	// Building names from the naming convention and checking they are valid and available,
+	// volume names are also used as their file path (creation token) which must be unique within the subnet
+	logging.Info("Checking resource names...")
+	err = buildResourceNames(cntx, subnetID)
+	if err != nil {
+		logging.Error("an error ocurred while checking resource names", logging.Err(err))
+		exitCode = 1
+		return
+	}
+	logging.Info(fmt.Sprintf("Resource names are valid and available, volumes: %v, %v, %v", nfsv3VolumeName, nfsv41VolumeName, nfsv3VolumeNameFromSnap))
+
+	// Azure NetApp Files resources are created as a dependency graph, the NFSv3 and NFSv4.1 volumes
+	// do not depend on each other and are created concurrently once their capacity pool exists
+	var snapshot netapp.Snapshot
+	provisioning := graph.New()
+
 	// Azure NetApp Files Account creation
-	utils.ConsoleOutput("Creating Azure NetApp Files account...")
-	account, err := sdkutils.CreateANFAccount(cntx, location, resourceGroupName, anfAccountName, nil, sampleTags)
+	err = provisioning.Add("creating account", func(cntx context.Context) error {
+		logging.Info("Creating Azure NetApp Files account...")
+		future, err := sdkutils.BeginCreateANFAccount(cntx, location, resourceGroupName, anfAccountName, nil, sampleTags)
+		if err != nil {
+			return err
+		}
+		resourceID := fmt.Sprintf("/subscriptions/%v/resourceGroups/%v/providers/Microsoft.NetApp/netAppAccounts/%v", *config.SubscriptionID, resourceGroupName, anfAccountName)
+		err = operationStore.Track(cntx, operations.Create, resourceID, state.AccountType, future)
+		if err != nil {
+			return err
+		}
+		accountID = resourceID
+		err = recordCreated(cntx, accountID, state.AccountType)
+		if err != nil {
+			return err
+		}
+		logging.Info("Account successfully created", logging.ResourceID(accountID))
+		return nil
+	})
 	if err != nil {
-		utils.ConsoleOutput(fmt.Sprintf("an error ocurred while creating account: %v", err))
+		logging.Error("an error ocurred while building the provisioning graph", logging.Err(err))
 		exitCode = 1
 		return
 	}
-	accountID = *account.ID
-	utils.ConsoleOutput(fmt.Sprintf("Account successfully created, resource id: %v", accountID))
 
 	// Capacity pool creation
-	utils.ConsoleOutput("Creating Capacity Pool...")
-	capacityPool, err := sdkutils.CreateANFCapacityPool(
-		cntx,
-		location,
-		resourceGroupName,
-		*account.Name,
-		capacityPoolName,
-		serviceLevel,
-		capacityPoolSizeBytes,
-		sampleTags,
-	)
+	err = provisioning.Add("creating capacity pool", func(cntx context.Context) error {
+		logging.Info("Creating Capacity Pool...")
+		future, err := sdkutils.BeginCreateANFCapacityPool(
+			cntx,
+			location,
+			resourceGroupName,
+			anfAccountName,
+			capacityPoolName,
+			serviceLevel,
+			capacityPoolSizeBytes,
+			sampleTags,
+		)
+		if err != nil {
+			return err
+		}
+		resourceID := fmt.Sprintf("%v/capacityPools/%v", accountID, capacityPoolName)
+		err = operationStore.Track(cntx, operations.Create, resourceID, state.CapacityPoolType, future)
+		if err != nil {
+			return err
+		}
+		capacityPoolID = resourceID
+		err = recordCreated(cntx, capacityPoolID, state.CapacityPoolType)
+		if err != nil {
+			return err
+		}
+		logging.Info("Capacity Pool successfully created", logging.ResourceID(capacityPoolID))
+		return nil
+	}, "creating account")
 	if err != nil {
-		utils.ConsoleOutput(fmt.Sprintf("an error ocurred while creating capacity pool: %v", err))
+		logging.Error("an error ocurred while building the provisioning graph", logging.Err(err))
 		exitCode = 1
 		return
 	}
-	capacityPoolID = *capacityPool.ID
-	utils.ConsoleOutput(fmt.Sprintf("Capacity Pool successfully created, resource id: %v", capacityPoolID))
 
 	// NFS v3 volume creation
-	utils.ConsoleOutput("Creating NFSv3 Volume...")
-	nfsv3Volume, err := sdkutils.CreateANFVolume(
-		cntx,
-		location,
-		resourceGroupName,
-		*account.Name,
-		capacityPoolName,
-		nfsv3VolumeName,
-		serviceLevel,
-		subnetID,
-		"",
-		nfsv3ProtocolTypes,
-		volumeSizeBytes,
-		false,
-		true,
-		sampleTags,
-		netapp.VolumePropertiesDataProtection{}, // This empty object is provided as nil since dataprotection is not scope of this sample
-	)
+	err = provisioning.Add("creating NFSv3 volume", func(cntx context.Context) error {
+		logging.Info("Creating NFSv3 Volume...")
+		future, err := sdkutils.BeginCreateANFVolume(
+			cntx,
+			location,
+			resourceGroupName,
+			anfAccountName,
+			capacityPoolName,
+			nfsv3VolumeName,
+			serviceLevel,
+			subnetID,
+			"",
+			nfsv3ProtocolTypes,
+			volumeSizeBytes,
+			false,
+			true,
+			sampleTags,
+			netapp.VolumePropertiesDataProtection{}, // This empty object is provided as nil since dataprotection is not scope of this sample
+		)
+		if err != nil {
+			return err
+		}
+		volumeID := fmt.Sprintf("%v/volumes/%v", capacityPoolID, nfsv3VolumeName)
+		err = operationStore.Track(cntx, operations.Create, volumeID, state.VolumeType, future)
+		if err != nil {
+			return err
+		}
+		nfsv3VolumeID = volumeID
+		err = recordCreated(cntx, nfsv3VolumeID, state.VolumeType)
+		if err != nil {
+			return err
+		}
+		logging.Info("NFSv3 volume successfully created", logging.ResourceID(nfsv3VolumeID))
+		nfsv3Volume, err := sdkutils.GetANFVolumeByID(cntx, nfsv3VolumeID)
+		if err != nil {
+			return err
+		}
+		printMountInstructions(nfsv3Volume)
+		return nil
+	}, "creating capacity pool")
 	if err != nil {
-		utils.ConsoleOutput(fmt.Sprintf("an error ocurred while creating NFSv3 volume: %v", err))
+		logging.Error("an error ocurred while building the provisioning graph", logging.Err(err))
 		exitCode = 1
 		return
 	}
-	nfsv3VolumeID = *nfsv3Volume.ID
-	utils.ConsoleOutput(fmt.Sprintf("NFSv3 volume successfully created, resource id: %v", nfsv3VolumeID))
 
 	// NFS v4.1 volume creation
-	utils.ConsoleOutput("Creating NFSv4.1 Volume...")
-	nfsv41Volume, err := sdkutils.CreateANFVolume(
-		cntx,
-		location,
-		resourceGroupName,
-		*account.Name,
-		capacityPoolName,
-		nfsv41VolumeName,
-		serviceLevel,
-		subnetID,
-		"",
-		nfsv41ProtocolTypes,
-		volumeSizeBytes,
-		false,
-		true,
-		sampleTags,
-		netapp.VolumePropertiesDataProtection{}, // This empty object is provided as nil since dataprotection is not scope of this sample
-	)
+	err = provisioning.Add("creating NFSv4.1 volume", func(cntx context.Context) error {
+		logging.Info("Creating NFSv4.1 Volume...")
+		future, err := sdkutils.BeginCreateANFVolume(
+			cntx,
+			location,
+			resourceGroupName,
+			anfAccountName,
+			capacityPoolName,
+			nfsv41VolumeName,
+			serviceLevel,
+			subnetID,
+			"",
+			nfsv41ProtocolTypes,
+			volumeSizeBytes,
+			false,
+			true,
+			sampleTags,
+			netapp.VolumePropertiesDataProtection{}, // This empty object is provided as nil since dataprotection is not scope of this sample
+		)
+		if err != nil {
+			return err
+		}
+		volumeID := fmt.Sprintf("%v/volumes/%v", capacityPoolID, nfsv41VolumeName)
+		err = operationStore.Track(cntx, operations.Create, volumeID, state.VolumeType, future)
+		if err != nil {
+			return err
+		}
+		nfsv41VolumeID = volumeID
+		err = recordCreated(cntx, nfsv41VolumeID, state.VolumeType)
+		if err != nil {
+			return err
+		}
+		logging.Info("NFSv4.1 volume successfully created", logging.ResourceID(nfsv41VolumeID))
+		nfsv41Volume, err := sdkutils.GetANFVolumeByID(cntx, nfsv41VolumeID)
+		if err != nil {
+			return err
+		}
+		printMountInstructions(nfsv41Volume)
+		return nil
+	}, "creating capacity pool")
 	if err != nil {
-		utils.ConsoleOutput(fmt.Sprintf("an error ocurred while creating NFSv4.1 volume: %v", err))
+		logging.Error("an error ocurred while building the provisioning graph", logging.Err(err))
 		exitCode = 1
 		return
 	}
-	nfsv41VolumeID = *nfsv41Volume.ID
-	utils.ConsoleOutput(fmt.Sprintf("NFSv4.1 volume successfully created, resource id: %v", nfsv41VolumeID))
 
 	// NFS v3 snapshot creation
 	// Note: there is no difference between protocol types when creating a snapshot
 	//       we're taking it from NFSv3 in this example just for convenience
-	utils.ConsoleOutput("Creating Snapshot from NFSv3 Volume...")
-	snapshot, err := sdkutils.CreateANFSnapshot(
-		cntx,
-		location,
-		resourceGroupName,
-		*account.Name,
-		capacityPoolName,
-		nfsv3VolumeName,
-		nfsv3SnapshotName,
-		sampleTags,
-	)
+	err = provisioning.Add("creating snapshot from NFSv3 volume", func(cntx context.Context) error {
+		logging.Info("Creating Snapshot from NFSv3 Volume...")
+		future, err := sdkutils.BeginCreateANFSnapshot(
+			cntx,
+			location,
+			resourceGroupName,
+			anfAccountName,
+			capacityPoolName,
+			nfsv3VolumeName,
+			nfsv3SnapshotName,
+			sampleTags,
+		)
+		if err != nil {
+			return err
+		}
+		resourceID := fmt.Sprintf("%v/snapshots/%v", nfsv3VolumeID, nfsv3SnapshotName)
+		err = operationStore.Track(cntx, operations.Create, resourceID, state.SnapshotType, future)
+		if err != nil {
+			return err
+		}
+		snapshot, err = sdkutils.GetANFSnapshotByID(cntx, resourceID)
+		if err != nil {
+			return err
+		}
+		snapshotID = resourceID
+		err = recordCreated(cntx, snapshotID, state.SnapshotType)
+		if err != nil {
+			return err
+		}
+		logging.Info("Snapshot successfully created", logging.ResourceID(snapshotID))
+		return nil
+	}, "creating NFSv3 volume")
 	if err != nil {
-		utils.ConsoleOutput(fmt.Sprintf("an error ocurred while creating snapshot from NFSv3 volume: %v", err))
+		logging.Error("an error ocurred while building the provisioning graph", logging.Err(err))
 		exitCode = 1
 		return
 	}
-	snapshotID = *snapshot.ID
-	utils.ConsoleOutput(fmt.Sprintf("Snapshot successfully created, resource id: %v", snapshotID))
 
 	// Creating new volume (NFSv3) from Snapshot
 	// Note: At the time when this sample code was written, creating a volume from snapshot with a different protocol
 	//       other than the protocol from the source volume is not supported.
-	utils.ConsoleOutput("Creating new NFSv3 Volume from Snapshot...")
-	newNFSv3Volume, err := sdkutils.CreateANFVolume(
-		cntx,
-		location,
-		resourceGroupName,
-		*account.Name,
-		capacityPoolName,
-		nfsv3VolumeNameFromSnap,
-		serviceLevel,
-		subnetID,
-		*snapshot.SnapshotID,
-		nfsv3ProtocolTypes,
-		volumeSizeBytes,
-		false,
-		true,
-		sampleTags,
-		netapp.VolumePropertiesDataProtection{}, // This empty object is provided as nil since dataprotection is not scope of this sample
-	)
+	err = provisioning.Add("creating NFSv3 volume from snapshot", func(cntx context.Context) error {
+		logging.Info("Creating new NFSv3 Volume from Snapshot...")
+		future, err := sdkutils.BeginCreateANFVolume(
+			cntx,
+			location,
+			resourceGroupName,
+			anfAccountName,
+			capacityPoolName,
+			nfsv3VolumeNameFromSnap,
+			serviceLevel,
+			subnetID,
+			*snapshot.SnapshotID,
+			nfsv3ProtocolTypes,
+			volumeSizeBytes,
+			false,
+			true,
+			sampleTags,
+			netapp.VolumePropertiesDataProtection{}, // This empty object is provided as nil since dataprotection is not scope of this sample
+		)
+		if err != nil {
+			return err
+		}
+		volumeID := fmt.Sprintf("%v/volumes/%v", capacityPoolID, nfsv3VolumeNameFromSnap)
+		err = operationStore.Track(cntx, operations.Create, volumeID, state.VolumeType, future)
+		if err != nil {
+			return err
+		}
+		nfsv3VolumeFromSnapshotID = volumeID
+		err = recordCreated(cntx, nfsv3VolumeFromSnapshotID, state.VolumeType)
+		if err != nil {
+			return err
+		}
+		logging.Info("NFSv3 volume from snapshot successfully created", logging.ResourceID(nfsv3VolumeFromSnapshotID))
+		return nil
+	}, "creating snapshot from NFSv3 volume")
 	if err != nil {
-		utils.ConsoleOutput(fmt.Sprintf("an error ocurred while creating NFSv3 volume from snapshot: %v", err))
+		logging.Error("an error ocurred while building the provisioning graph", logging.Err(err))
 		exitCode = 1
 		return
 	}
-	nfsv3VolumeFromSnapshotID = *newNFSv3Volume.ID
-	utils.ConsoleOutput(fmt.Sprintf("NFSv3 volume from snapshot successfully created, resource id: %v", nfsv3VolumeFromSnapshotID))
 
 	// Update NFS v4.1 volume size to double its size (200GiB in this example)
-	utils.ConsoleOutput("Updating NFSv4.1 volume size...")
+	err = provisioning.Add("updating NFSv4.1 volume", func(cntx context.Context) error {
+		logging.Info("Updating NFSv4.1 volume size...")
 
-	newVolumeSize := volumeSizeBytes * int64(2)
-	volumeChanges := netapp.VolumePatchProperties{
-		UsageThreshold: &newVolumeSize,
+		// The resize waits for the update to complete so the new size is recorded as the last applied one
+		newVolumeSize := volumeSizeBytes * int64(2)
+		_, err := sdkutils.ResizeANFVolume(cntx, nfsv41VolumeID, newVolumeSize)
+		if err != nil {
+			return err
+		}
+		err = drift.RecordApplied(cntx, journal, nfsv41VolumeID)
+		if err != nil {
+			return err
+		}
+		logging.Info(fmt.Sprintf("NFSv4.1 volume successfully update with new size %v", newVolumeSize), logging.ResourceID(nfsv41VolumeID))
+		return nil
+	}, "creating NFSv4.1 volume")
+	if err != nil {
+		logging.Error("an error ocurred while building the provisioning graph", logging.Err(err))
+		exitCode = 1
+		return
 	}
 
-	_, err = sdkutils.UpdateANFVolume(
-		cntx,
-		location,
-		resourceGroupName,
-		*account.Name,
-		capacityPoolName,
-		nfsv41VolumeName,
-		volumeChanges,
-		sampleTags,
-	)
+	results, err := provisioning.Run(cntx, maxParallelOperations)
 	if err != nil {
-		utils.ConsoleOutput(fmt.Sprintf("an error ocurred while updating NFSv4.1 volume: %v", err))
+		logging.Error("an error ocurred while building the provisioning graph", logging.Err(err))
 		exitCode = 1
 		return
 	}
-	utils.ConsoleOutput(fmt.Sprintf("NFSv4.1 volume successfully update with new size %v", newVolumeSize))
+	for _, result := range results {
+		if result.Cancelled {
+			logging.Warn(fmt.Sprintf("skipped %v", result.Name), logging.Operation(result.Name), logging.Err(result.Err))
+			exitCode = 1
+		} else if result.Err != nil {
+			logging.Error(fmt.Sprintf("an error ocurred while %v", result.Name), logging.Operation(result.Name), logging.Duration(result.Duration), logging.Err(result.Err))
+			exitCode = 1
+		} else {
+			logging.Debug(fmt.Sprintf("completed %v", result.Name), logging.Operation(result.Name), logging.Duration(result.Duration))
+		}
+	}
+}
+
+// buildResourceNames validates the account and capacity pool names and builds the volume and snapshot names
+func buildResourceNames(cntx context.Context, subnetID string) error {
+
+	err := naming.CheckNameAvailability(cntx, location, resourceGroupName, naming.Account, anfAccountName)
+	if err != nil {
+		return err
+	}
+
+	err = naming.ValidateName(naming.CapacityPool, capacityPoolName)
+	if err != nil {
+		return err
+	}
+
+	taken := []string{}
+	for _, volume := range []struct {
+		name  *string
+		proto string
+	}{
+		{&nfsv3VolumeName, nfsv3ProtocolTypes[0]},
+		{&nfsv41VolumeName, nfsv41ProtocolTypes[0]},
+		{&nfsv3VolumeNameFromSnap, nfsv3ProtocolTypes[0]},
+	} {
+		*volume.name, err = naming.NextVolumeName(cntx, namingConvention, naming.Fields{"proto": volume.proto}, location, subnetID, taken)
+		if err != nil {
+			return err
+		}
+		taken = append(taken, *volume.name)
+	}
+
+	nfsv3SnapshotName, err = namingConvention.Name(naming.Snapshot, naming.Fields{"volume": nfsv3VolumeName, "n": "1"})
+	return err
+}
+
+// recordCreated records a created resource in the state journal, with its live properties as the last applied ones
+// drift detection compares it with
+func recordCreated(cntx context.Context, resourceID, resourceType string) error {
+	err := journal.Record(resourceID, resourceType)
+	if err != nil {
+		return err
+	}
+	return drift.RecordApplied(cntx, journal, resourceID)
+}
 
+// printMountInstructions prints the commands to mount a newly created volume, mount targets
+// may not be reported yet in which case nothing is printed
+func printMountInstructions(volume netapp.Volume) {
+	instructions, err := mount.ForVolume(volume, "")
+	if err != nil {
+		return
+	}
+	instructions.Print(false, false)
 }
 
 func exit(cntx context.Context) {
-	utils.ConsoleOutput("Exiting")
+	logging.Info("Exiting")
 
 	if shouldCleanUp {
-		utils.ConsoleOutput("\tPerforming clean up")
+		logging.Info("Performing clean up")
 
 		// Volume restored from Snaphost cleanup
-		utils.ConsoleOutput("\tCleaning up NFSv3 Volume Restored from Snapshot ...")
+		logging.Info("Cleaning up NFSv3 Volume Restored from Snapshot ...")
 		time.Sleep(3 * time.Second)
-		err := sdkutils.DeleteANFVolume(
-			cntx,
-			resourceGroupName,
-			anfAccountName,
-			capacityPoolName,
-			nfsv3VolumeNameFromSnap,
-		)
+		err := teardown.DeleteResource(cntx, nfsv3VolumeFromSnapshotID, operationStore)
+		if err != nil {
+			logging.Error("an error ocurred while deleting volume", logging.ResourceID(nfsv3VolumeFromSnapshotID), logging.Err(err))
+			exitCode = 1
+			return
+		}
+		err = journal.Remove(nfsv3VolumeFromSnapshotID)
 		if err != nil {
-			utils.ConsoleOutput(fmt.Sprintf("an error ocurred while deleting volume: %v", err))
+			logging.Error("an error ocurred while updating the state journal", logging.ResourceID(nfsv3VolumeFromSnapshotID), logging.Err(err))
 			exitCode = 1
 			return
 		}
-		sdkutils.WaitForNoANFResource(cntx, nfsv3VolumeFromSnapshotID, 60, 60, false)
-		utils.ConsoleOutput("\tVolume successfully deleted")
+		logging.Info("Volume successfully deleted", logging.ResourceID(nfsv3VolumeFromSnapshotID))
 
 		// Snapshot Cleanup
-		utils.ConsoleOutput("\tCleaning up NFSv3 Volume Snapshot ...")
-		err = sdkutils.DeleteANFSnapshot(
-			cntx,
-			resourceGroupName,
-			anfAccountName,
-			capacityPoolName,
-			nfsv3VolumeName,
-			nfsv3SnapshotName,
-		)
+		logging.Info("Cleaning up NFSv3 Volume Snapshot ...")
+		err = teardown.DeleteResource(cntx, snapshotID, operationStore)
+		if err != nil {
+			logging.Error("an error ocurred while deleting NFSv3 volume snapshot", logging.ResourceID(snapshotID), logging.Err(err))
+			exitCode = 1
+			return
+		}
+		err = journal.Remove(snapshotID)
 		if err != nil {
-			utils.ConsoleOutput(fmt.Sprintf("an error ocurred while deleting NFSv3 volume snapshot: %v", err))
+			logging.Error("an error ocurred while updating the state journal", logging.ResourceID(snapshotID), logging.Err(err))
 			exitCode = 1
 			return
 		}
-		sdkutils.WaitForNoANFResource(cntx, snapshotID, 60, 60, false)
-		utils.ConsoleOutput("\tSnapshot successfully deleted")
+		logging.Info("Snapshot successfully deleted", logging.ResourceID(snapshotID))
 
 		// Other Volumes Cleanup
-		utils.ConsoleOutput("\tCleaning up other volumes...")
+		logging.Info("Cleaning up other volumes...")
 		volumes := map[string]string{
 			nfsv3VolumeName:  nfsv3VolumeID,
 			nfsv41VolumeName: nfsv41VolumeID,
 		}
 		for volumeName, resourceID := range volumes {
-			utils.ConsoleOutput(fmt.Sprintf("\tCleaning up volume %v", volumeName))
-			err := sdkutils.DeleteANFVolume(
-				cntx,
-				resourceGroupName,
-				anfAccountName,
-				capacityPoolName,
-				volumeName,
-			)
+			logging.Info(fmt.Sprintf("Cleaning up volume %v", volumeName), logging.ResourceID(resourceID))
+			err := teardown.DeleteResource(cntx, resourceID, operationStore)
+			if err != nil {
+				logging.Error("an error ocurred while deleting volume", logging.ResourceID(resourceID), logging.Err(err))
+				exitCode = 1
+				return
+			}
+			err = journal.Remove(resourceID)
 			if err != nil {
-				utils.ConsoleOutput(fmt.Sprintf("an error ocurred while deleting volume: %v", err))
+				logging.Error("an error ocurred while updating the state journal", logging.ResourceID(resourceID), logging.Err(err))
 				exitCode = 1
 				return
 			}
-			sdkutils.WaitForNoANFResource(cntx, resourceID, 60, 60, false)
-			utils.ConsoleOutput("\tVolume successfully deleted")
+			logging.Info("Volume successfully deleted", logging.ResourceID(resourceID))
 		}
 
 		// Pool Cleanup
-		utils.ConsoleOutput("\tCleaning up capacity pool...")
-		err = sdkutils.DeleteANFCapacityPool(
-			cntx,
-			resourceGroupName,
-			anfAccountName,
-			capacityPoolName,
-		)
+		logging.Info("Cleaning up capacity pool...")
+		err = teardown.DeleteResource(cntx, capacityPoolID, operationStore)
+		if err != nil {
+			logging.Error("an error ocurred while deleting capacity pool", logging.ResourceID(capacityPoolID), logging.Err(err))
+			exitCode = 1
+			return
+		}
+		err = journal.Remove(capacityPoolID)
 		if err != nil {
-			utils.ConsoleOutput(fmt.Sprintf("an error ocurred while deleting capacity pool: %v", err))
+			logging.Error("an error ocurred while updating the state journal", logging.ResourceID(capacityPoolID), logging.Err(err))
 			exitCode = 1
 			return
 		}
-		sdkutils.WaitForNoANFResource(cntx, capacityPoolID, 60, 60, false)
-		utils.ConsoleOutput("\tCapacity pool successfully deleted")
+		logging.Info("Capacity pool successfully deleted", logging.ResourceID(capacityPoolID))
 
 		// Account Cleanup
-		utils.ConsoleOutput("\tCleaning up account...")
-		err = sdkutils.DeleteANFAccount(
-			cntx,
-			resourceGroupName,
-			anfAccountName,
-		)
+		logging.Info("Cleaning up account...")
+		err = teardown.DeleteResource(cntx, accountID, operationStore)
+		if err != nil {
+			logging.Error("an error ocurred while deleting account", logging.ResourceID(accountID), logging.Err(err))
+			exitCode = 1
+			return
+		}
+		err = journal.Remove(accountID)
 		if err != nil {
-			utils.ConsoleOutput(fmt.Sprintf("an error ocurred while deleting account: %v", err))
+			logging.Error("an error ocurred while updating the state journal", logging.ResourceID(accountID), logging.Err(err))
 			exitCode = 1
 			return
 		}
-		utils.ConsoleOutput("\tAccount successfully deleted")
-		utils.ConsoleOutput("\tCleanup completed!")
+		logging.Info("Account successfully deleted", logging.ResourceID(accountID))
+
+		// Network resources created by the bootstrap cleanup, newest first
+		if len(bootstrappedResourceIDs) > 0 {
+			logging.Info("Cleaning up network resources created by the bootstrap...")
+			resourceIDs := make([]string, 0, len(bootstrappedResourceIDs))
+			for i := len(bootstrappedResourceIDs) - 1; i >= 0; i-- {
+				resourceIDs = append(resourceIDs, bootstrappedResourceIDs[i])
+			}
+			err = teardown.Resources(cntx, journal, resourceIDs, operationStore)
+			if err != nil {
+				logging.Error("an error ocurred while deleting network resources", logging.Err(err))
+				exitCode = 1
+				return
+			}
+			logging.Info("Network resources successfully deleted")
+		}
+		logging.Info("Cleanup completed!")
 	}
 }
@@ -0,0 +1,334 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// This package compares the provisioned size of capacity pools with
+// what their volumes allocate and actually consume, and recommends
+// changes that reduce the provisioned capacity with estimated savings.
+
+package advisor
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/metrics"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/pricing"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/qos"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/sdkutils"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/uri"
+	"github.com/Azure/azure-sdk-for-go/services/netapp/mgmt/2021-04-01/netapp"
+)
+
+const (
+	// RecommendationShrinkPool means a pool is larger than what its volumes allocate
+	RecommendationShrinkPool string = "shrink-pool"
+	// RecommendationConsolidatePools means volumes of pools with the same service level fit in fewer pools
+	RecommendationConsolidatePools string = "consolidate-pools"
+	// RecommendationLowerServiceLevel means a volume throughput would be met by a lower service level
+	RecommendationLowerServiceLevel string = "lower-service-level"
+
+	// MetricConsumption is the consumed size of a volume
+	MetricConsumption string = "consumption"
+	// MetricThroughput is the peak throughput of a volume
+	MetricThroughput string = "throughput"
+)
+
+var (
+	// service level that is one step cheaper than each service level
+	lowerServiceLevel = map[netapp.ServiceLevel]netapp.ServiceLevel{
+		netapp.ServiceLevelUltra:   netapp.ServiceLevelPremium,
+		netapp.ServiceLevelPremium: netapp.ServiceLevelStandard,
+	}
+)
+
+// PoolReport summarizes the capacity of a pool, ConsumedBytes is a lower bound when the consumption
+// of MissingConsumption volumes could not be read
+type PoolReport struct {
+	PoolID             string
+	Location           string
+	ServiceLevel       string
+	SizeBytes          int64
+	AllocatedBytes     int64
+	ConsumedBytes      int64
+	VolumeCount        int
+	MissingConsumption int
+}
+
+// Recommendation is a suggested change with its estimated monthly savings
+type Recommendation struct {
+	Kind           string
+	ResourceID     string
+	Description    string
+	MonthlySavings float64
+}
+
+// MissingMetric is a volume metric that could not be read, recommendations depending on it are not made
+type MissingMetric struct {
+	ResourceID string
+	Metric     string
+	Err        error
+}
+
+// Report is the outcome of an advise execution, it is incomplete when some metrics could not be read
+type Report struct {
+	Currency        string
+	Pools           []PoolReport
+	Recommendations []Recommendation
+	MissingMetrics  []MissingMetric
+}
+
+// Complete tells whether every metric needed by the advice could be read
+func (r Report) Complete() bool {
+	return len(r.MissingMetrics) == 0
+}
+
+// TotalMonthlySavings sums the savings of all recommendations
+func (r Report) TotalMonthlySavings() float64 {
+	var total float64
+	for _, recommendation := range r.Recommendations {
+		total += recommendation.MonthlySavings
+	}
+	return total
+}
+
+// Advise inspects all capacity pools of an account and returns recommendations to reduce provisioned capacity.
+// Consumption comes from the metrics source, if it also implements metrics.ThroughputSource volumes are
+// evaluated for a lower service level too. Metrics that cannot be read are listed in the report
+// MissingMetrics instead of failing the whole advice, callers must check Report.Complete.
+func Advise(ctx context.Context, accountID string, source metrics.Source, catalog pricing.Catalog) (Report, error) {
+
+	report := Report{
+		Currency: catalog.Currency,
+	}
+
+	pools, err := sdkutils.ListANFCapacityPools(ctx, accountID)
+	if err != nil {
+		return Report{}, err
+	}
+
+	throughputSource, hasThroughput := source.(metrics.ThroughputSource)
+
+	for _, pool := range pools {
+		if pool.PoolProperties == nil || pool.Size == nil {
+			continue
+		}
+
+		volumes, err := sdkutils.ListANFVolumes(ctx, *pool.ID)
+		if err != nil {
+			return Report{}, err
+		}
+
+		poolReport := PoolReport{
+			PoolID:       *pool.ID,
+			Location:     *pool.Location,
+			ServiceLevel: string(pool.ServiceLevel),
+			SizeBytes:    *pool.Size,
+			VolumeCount:  len(volumes),
+		}
+
+		for _, volume := range volumes {
+			if volume.VolumeProperties == nil || volume.UsageThreshold == nil {
+				continue
+			}
+			poolReport.AllocatedBytes += *volume.UsageThreshold
+
+			consumed, err := source.VolumeConsumedBytes(ctx, *volume.ID)
+			if err != nil {
+				poolReport.MissingConsumption++
+				report.MissingMetrics = append(report.MissingMetrics, MissingMetric{ResourceID: *volume.ID, Metric: MetricConsumption, Err: err})
+			} else {
+				poolReport.ConsumedBytes += consumed
+			}
+
+			if _, hasLowerLevel := lowerServiceLevel[pool.ServiceLevel]; hasThroughput && hasLowerLevel {
+				peak, err := throughputSource.VolumePeakThroughputMibps(ctx, *volume.ID)
+				if err != nil {
+					report.MissingMetrics = append(report.MissingMetrics, MissingMetric{ResourceID: *volume.ID, Metric: MetricThroughput, Err: err})
+					continue
+				}
+
+				recommendation, found, err := adviseServiceLevel(poolReport, volume, peak, catalog)
+				if err != nil {
+					return Report{}, err
+				}
+				if found {
+					report.Recommendations = append(report.Recommendations, recommendation)
+				}
+			}
+		}
+
+		report.Pools = append(report.Pools, poolReport)
+
+		recommendation, found, err := adviseShrink(poolReport, catalog)
+		if err != nil {
+			return Report{}, err
+		}
+		if found {
+			report.Recommendations = append(report.Recommendations, recommendation)
+		}
+	}
+
+	consolidations, err := adviseConsolidation(report.Pools, catalog)
+	if err != nil {
+		return Report{}, err
+	}
+	report.Recommendations = append(report.Recommendations, consolidations...)
+
+	sort.SliceStable(report.Recommendations, func(i, j int) bool {
+		return report.Recommendations[i].MonthlySavings > report.Recommendations[j].MonthlySavings
+	})
+
+	return report, nil
+}
+
+// minimumPoolSize returns the smallest valid pool size able to hold the provided bytes
+func minimumPoolSize(bytes int64) int64 {
+	size := (bytes + sdkutils.CapacityPoolSizeIncrementBytes - 1) / sdkutils.CapacityPoolSizeIncrementBytes * sdkutils.CapacityPoolSizeIncrementBytes
+	if size < sdkutils.MinCapacityPoolSizeBytes {
+		return sdkutils.MinCapacityPoolSizeBytes
+	}
+	return size
+}
+
+func adviseShrink(pool PoolReport, catalog pricing.Catalog) (Recommendation, bool, error) {
+
+	targetSize := minimumPoolSize(pool.AllocatedBytes)
+	if targetSize >= pool.SizeBytes {
+		return Recommendation{}, false, nil
+	}
+
+	price, err := catalog.Price(pool.Location, pool.ServiceLevel)
+	if err != nil {
+		return Recommendation{}, false, err
+	}
+
+	description := fmt.Sprintf(
+		"pool is %v TiB but its volumes allocate %.2f TiB, shrink it to %v TiB",
+		pool.SizeBytes/sdkutils.TiBInBytes,
+		float64(pool.AllocatedBytes)/float64(sdkutils.TiBInBytes),
+		targetSize/sdkutils.TiBInBytes,
+	)
+
+	// Consumption is only a hint when every volume reported it, otherwise it is under-counted
+	if consumedSize := minimumPoolSize(pool.ConsumedBytes); pool.MissingConsumption == 0 && consumedSize < targetSize {
+		description = fmt.Sprintf("%v, volumes only consume %.2f TiB so reducing their quotas would allow %v TiB",
+			description,
+			float64(pool.ConsumedBytes)/float64(sdkutils.TiBInBytes),
+			consumedSize/sdkutils.TiBInBytes,
+		)
+	}
+
+	return Recommendation{
+		Kind:           RecommendationShrinkPool,
+		ResourceID:     pool.PoolID,
+		Description:    description,
+		MonthlySavings: float64(pool.SizeBytes-targetSize) / float64(sdkutils.GiBInBytes) * price,
+	}, true, nil
+}
+
+func adviseConsolidation(pools []PoolReport, catalog pricing.Catalog) ([]Recommendation, error) {
+
+	// Pools can only be consolidated within the same account and service level
+	groups := map[string][]PoolReport{}
+	keys := []string{}
+	for _, pool := range pools {
+		key := strings.ToLower(fmt.Sprintf("%v/%v/%v", uri.GetResourceGroup(pool.PoolID), uri.GetANFAccount(pool.PoolID), pool.ServiceLevel))
+		if _, found := groups[key]; !found {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], pool)
+	}
+
+	recommendations := []Recommendation{}
+	for _, key := range keys {
+		group := groups[key]
+		if len(group) < 2 {
+			continue
+		}
+
+		var allocated, shrunkSize int64
+		largest := group[0]
+		names := []string{}
+		for _, pool := range group {
+			allocated += pool.AllocatedBytes
+			shrunkSize += minimumPoolSize(pool.AllocatedBytes)
+			names = append(names, uri.GetANFCapacityPool(pool.PoolID))
+			if pool.AllocatedBytes > largest.AllocatedBytes {
+				largest = pool
+			}
+		}
+
+		consolidatedSize := minimumPoolSize(allocated)
+		if consolidatedSize >= shrunkSize {
+			continue
+		}
+
+		price, err := catalog.Price(largest.Location, largest.ServiceLevel)
+		if err != nil {
+			return nil, err
+		}
+
+		recommendations = append(recommendations, Recommendation{
+			Kind:       RecommendationConsolidatePools,
+			ResourceID: largest.PoolID,
+			Description: fmt.Sprintf(
+				"%v pools (%v) allocate %.2f TiB together, move their volumes into %v and size it to %v TiB instead of %v TiB in separate pools",
+				largest.ServiceLevel,
+				strings.Join(names, ", "),
+				float64(allocated)/float64(sdkutils.TiBInBytes),
+				uri.GetANFCapacityPool(largest.PoolID),
+				consolidatedSize/sdkutils.TiBInBytes,
+				shrunkSize/sdkutils.TiBInBytes,
+			),
+			MonthlySavings: float64(shrunkSize-consolidatedSize) / float64(sdkutils.GiBInBytes) * price,
+		})
+	}
+
+	return recommendations, nil
+}
+
+func adviseServiceLevel(pool PoolReport, volume netapp.Volume, peak float64, catalog pricing.Catalog) (Recommendation, bool, error) {
+
+	currentLevel := netapp.ServiceLevel(pool.ServiceLevel)
+	lowerLevel, found := lowerServiceLevel[currentLevel]
+	if !found {
+		return Recommendation{}, false, nil
+	}
+
+	lowerThroughput, err := qos.ThroughputMibps(lowerLevel, *volume.UsageThreshold)
+	if err != nil {
+		return Recommendation{}, false, err
+	}
+
+	if peak > lowerThroughput {
+		return Recommendation{}, false, nil
+	}
+
+	currentPrice, err := catalog.Price(pool.Location, string(currentLevel))
+	if err != nil {
+		return Recommendation{}, false, err
+	}
+
+	lowerPrice, err := catalog.Price(pool.Location, string(lowerLevel))
+	if err != nil {
+		return Recommendation{}, false, err
+	}
+
+	return Recommendation{
+		Kind:       RecommendationLowerServiceLevel,
+		ResourceID: *volume.ID,
+		Description: fmt.Sprintf(
+			"peak throughput %.1f MiB/s fits within the %.1f MiB/s of %v, move the volume to a %v pool and shrink %v accordingly",
+			peak,
+			lowerThroughput,
+			lowerLevel,
+			lowerLevel,
+			uri.GetANFCapacityPool(pool.PoolID),
+		),
+		MonthlySavings: float64(*volume.UsageThreshold) / float64(sdkutils.GiBInBytes) * (currentPrice - lowerPrice),
+	}, true, nil
+}
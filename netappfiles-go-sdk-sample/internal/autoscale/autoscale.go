@@ -0,0 +1,317 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// This package periodically checks volume consumption and grows
+// the volume quota (usage threshold), and its capacity pool when
+// needed, once usage crosses a configured percentage.
+
+package autoscale
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/drift"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/logging"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/metrics"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/sdkutils"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/state"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/uri"
+)
+
+// Config defines when and how much volumes are grown, resized resources recorded in Journal get their
+// last applied properties updated so drift detection does not revert the growth, and the time of every growth
+// is kept in Journal so the cooldown also applies to later runs. Resources reads and resizes volumes and pools,
+// the Azure NetApp Files API is used when it is nil.
+type Config struct {
+	VolumeIDs          []string
+	ThresholdPercent   float64
+	GrowStepBytes      int64
+	MaxVolumeSizeBytes int64
+	MaxPoolSizeBytes   int64
+	Cooldown           time.Duration
+	Interval           time.Duration
+	Journal            *state.Journal
+	Resources          Resources
+}
+
+// Resources reads and resizes the volumes and capacity pools evaluated by the autoscaler
+type Resources interface {
+	VolumeQuotaBytes(ctx context.Context, volumeID string) (int64, error)
+	CapacityPoolSizeBytes(ctx context.Context, poolID string) (int64, error)
+	CapacityPoolAllocatedBytes(ctx context.Context, poolID string) (int64, error)
+	ResizeVolume(ctx context.Context, volumeID string, sizeBytes int64) error
+	ResizeCapacityPool(ctx context.Context, poolID string, sizeBytes int64) error
+}
+
+// AzureResources reads and resizes volumes and capacity pools with the Azure NetApp Files API
+type AzureResources struct{}
+
+// Autoscaler grows volumes based on the consumption reported by a metrics source, lastGrowth holds the growths
+// made by this autoscaler, the journal the ones of previous runs
+type Autoscaler struct {
+	config     Config
+	source     metrics.Source
+	lastGrowth map[string]time.Time
+}
+
+// New returns an Autoscaler after validating its configuration
+func New(config Config, source metrics.Source) (*Autoscaler, error) {
+
+	if len(config.VolumeIDs) == 0 {
+		return nil, fmt.Errorf("at least one volume must be provided")
+	}
+
+	for _, volumeID := range config.VolumeIDs {
+		if !uri.IsANFVolume(volumeID) {
+			return nil, fmt.Errorf("resource id %v is not a volume", volumeID)
+		}
+	}
+
+	if config.ThresholdPercent <= 0 || config.ThresholdPercent > 100 {
+		return nil, fmt.Errorf("threshold must be between 0 and 100 percent")
+	}
+
+	if config.GrowStepBytes <= 0 {
+		return nil, fmt.Errorf("grow step must be greater than zero")
+	}
+
+	if config.Resources == nil {
+		config.Resources = AzureResources{}
+	}
+
+	return &Autoscaler{
+		config:     config,
+		source:     source,
+		lastGrowth: map[string]time.Time{},
+	}, nil
+}
+
+// Run evaluates all volumes every configured interval until the context is cancelled
+func (a *Autoscaler) Run(ctx context.Context) error {
+
+	ticker := time.NewTicker(a.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		// Failures are logged by EvaluateAll, the next evaluation retries them
+		_ = a.EvaluateAll(ctx)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// EvaluateAll evaluates every configured volume once, errors are logged and don't stop other volumes from being evaluated,
+// an error is returned when at least one volume could not be evaluated
+func (a *Autoscaler) EvaluateAll(ctx context.Context) error {
+
+	failures := 0
+	for _, volumeID := range a.config.VolumeIDs {
+		err := a.Evaluate(ctx, volumeID)
+		if err != nil {
+			logging.Error("an error ocurred while evaluating volume", logging.Operation("autoscale"), logging.ResourceID(volumeID), logging.Err(err))
+			failures++
+		}
+	}
+
+	if failures > 0 {
+		return fmt.Errorf("%v of %v volume(s) could not be evaluated", failures, len(a.config.VolumeIDs))
+	}
+
+	return nil
+}
+
+// Evaluate checks a single volume and grows it, and its pool, if usage crossed the threshold
+func (a *Autoscaler) Evaluate(ctx context.Context, volumeID string) error {
+
+	quota, err := a.config.Resources.VolumeQuotaBytes(ctx, volumeID)
+	if err != nil {
+		return err
+	}
+
+	// Usage cannot be computed without a quota, growing from it would resize the volume to a single step
+	if quota <= 0 {
+		return fmt.Errorf("volume %v reports a quota of %v bytes, usage cannot be evaluated", uri.GetANFVolume(volumeID), quota)
+	}
+
+	consumed, err := a.source.VolumeConsumedBytes(ctx, volumeID)
+	if err != nil {
+		return err
+	}
+
+	usage := float64(consumed) / float64(quota) * 100
+	usageFields := []logging.Field{
+		logging.Any("usagePercent", math.Round(usage*10)/10),
+		logging.Any("thresholdPercent", a.config.ThresholdPercent),
+		logging.Any("quotaBytes", quota),
+	}
+	if usage < a.config.ThresholdPercent {
+		a.log("usage is below the threshold, no action", volumeID, usageFields...)
+		return nil
+	}
+
+	if last, found := a.grownAt(volumeID); found && time.Since(last) < a.config.Cooldown {
+		a.log("usage is above the threshold but the volume is in its cooldown, skipping", volumeID,
+			append(usageFields, logging.Any("lastGrowth", last), logging.Any("cooldown", a.config.Cooldown))...)
+		return nil
+	}
+
+	newQuota := quota + a.config.GrowStepBytes
+	if a.config.MaxVolumeSizeBytes > 0 && newQuota > a.config.MaxVolumeSizeBytes {
+		if quota >= a.config.MaxVolumeSizeBytes {
+			a.log("usage is above the threshold but the volume is at its maximum size, skipping", volumeID,
+				append(usageFields, logging.Any("maxSizeBytes", a.config.MaxVolumeSizeBytes))...)
+			return nil
+		}
+		newQuota = a.config.MaxVolumeSizeBytes
+	}
+
+	err = a.ensurePoolCapacity(ctx, uri.GetANFCapacityPoolID(volumeID), newQuota-quota)
+	if err != nil {
+		return err
+	}
+
+	a.log("usage is above the threshold, growing volume quota", volumeID,
+		append(usageFields, logging.Any("oldSizeBytes", quota), logging.Any("newSizeBytes", newQuota))...)
+	err = a.config.Resources.ResizeVolume(ctx, volumeID, newQuota)
+	if err != nil {
+		return err
+	}
+	err = a.recordGrowth(volumeID)
+	if err != nil {
+		return err
+	}
+	a.log("volume quota grown", volumeID, logging.Any("oldSizeBytes", quota), logging.Any("newSizeBytes", newQuota))
+
+	return drift.RecordApplied(ctx, a.config.Journal, volumeID)
+}
+
+// ensurePoolCapacity grows the pool, in 1TiB increments, when it does not have the additional bytes free
+func (a *Autoscaler) ensurePoolCapacity(ctx context.Context, poolID string, additionalBytes int64) error {
+
+	poolName := uri.GetANFCapacityPool(poolID)
+
+	poolSize, err := a.config.Resources.CapacityPoolSizeBytes(ctx, poolID)
+	if err != nil {
+		return err
+	}
+
+	allocated, err := a.config.Resources.CapacityPoolAllocatedBytes(ctx, poolID)
+	if err != nil {
+		return err
+	}
+
+	required := allocated + additionalBytes
+	if required <= poolSize {
+		return nil
+	}
+
+	newPoolSize := (required + sdkutils.CapacityPoolSizeIncrementBytes - 1) / sdkutils.CapacityPoolSizeIncrementBytes * sdkutils.CapacityPoolSizeIncrementBytes
+	if a.config.MaxPoolSizeBytes > 0 && newPoolSize > a.config.MaxPoolSizeBytes {
+		return fmt.Errorf("capacity pool %v needs %v bytes which exceeds the maximum pool size of %v bytes", poolName, newPoolSize, a.config.MaxPoolSizeBytes)
+	}
+
+	if last, found := a.grownAt(poolID); found && time.Since(last) < a.config.Cooldown {
+		return fmt.Errorf("capacity pool %v needs to grow but was grown %v ago, cooldown is %v", poolName, time.Since(last).Round(time.Second), a.config.Cooldown)
+	}
+
+	a.log("growing capacity pool to fit the volume growth", poolID,
+		logging.Any("oldSizeBytes", poolSize), logging.Any("newSizeBytes", newPoolSize), logging.Any("requiredBytes", required))
+	err = a.config.Resources.ResizeCapacityPool(ctx, poolID, newPoolSize)
+	if err != nil {
+		return err
+	}
+	err = a.recordGrowth(poolID)
+	if err != nil {
+		return err
+	}
+
+	return drift.RecordApplied(ctx, a.config.Journal, poolID)
+}
+
+// grownAt returns when a volume or pool was last grown, by this autoscaler or by a previous run recorded in the journal
+func (a *Autoscaler) grownAt(resourceID string) (time.Time, bool) {
+
+	if last, found := a.lastGrowth[resourceID]; found {
+		return last, true
+	}
+
+	if a.config.Journal == nil {
+		return time.Time{}, false
+	}
+
+	return a.config.Journal.LastGrowth(resourceID)
+}
+
+// recordGrowth keeps the time of a growth for the cooldown of this autoscaler and of later runs
+func (a *Autoscaler) recordGrowth(resourceID string) error {
+
+	now := time.Now()
+	a.lastGrowth[resourceID] = now
+
+	if a.config.Journal == nil {
+		return nil
+	}
+
+	return a.config.Journal.RecordGrowth(resourceID, now)
+}
+
+// log writes an autoscale decision about a resource with the values it is based on
+func (a *Autoscaler) log(decision, resourceID string, fields ...logging.Field) {
+	logging.Info(decision, append([]logging.Field{logging.Operation("autoscale"), logging.ResourceID(resourceID)}, fields...)...)
+}
+
+// VolumeQuotaBytes returns the usage threshold of a volume
+func (AzureResources) VolumeQuotaBytes(ctx context.Context, volumeID string) (int64, error) {
+
+	volume, err := sdkutils.GetANFVolumeByID(ctx, volumeID)
+	if err != nil {
+		return 0, err
+	}
+
+	if volume.VolumeProperties == nil || volume.UsageThreshold == nil {
+		return 0, fmt.Errorf("cannot determine volume quota")
+	}
+
+	return *volume.UsageThreshold, nil
+}
+
+// CapacityPoolSizeBytes returns the provisioned size of a capacity pool
+func (AzureResources) CapacityPoolSizeBytes(ctx context.Context, poolID string) (int64, error) {
+
+	pool, err := sdkutils.GetANFCapacityPoolByID(ctx, poolID)
+	if err != nil {
+		return 0, err
+	}
+
+	if pool.PoolProperties == nil || pool.Size == nil {
+		return 0, fmt.Errorf("cannot determine capacity pool size")
+	}
+
+	return *pool.Size, nil
+}
+
+// CapacityPoolAllocatedBytes returns the sum of the quotas of the volumes of a capacity pool
+func (AzureResources) CapacityPoolAllocatedBytes(ctx context.Context, poolID string) (int64, error) {
+	return sdkutils.GetANFCapacityPoolAllocatedBytes(ctx, poolID)
+}
+
+// ResizeVolume sets the usage threshold of a volume
+func (AzureResources) ResizeVolume(ctx context.Context, volumeID string, sizeBytes int64) error {
+	_, err := sdkutils.ResizeANFVolume(ctx, volumeID, sizeBytes)
+	return err
+}
+
+// ResizeCapacityPool sets the size of a capacity pool
+func (AzureResources) ResizeCapacityPool(ctx context.Context, poolID string, sizeBytes int64) error {
+	_, err := sdkutils.ResizeANFCapacityPool(ctx, poolID, sizeBytes)
+	return err
+}
@@ -0,0 +1,249 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package autoscale
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/metrics"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/sdkutils"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/state"
+)
+
+const (
+	poolID   = "/subscriptions/s/resourceGroups/rg/providers/Microsoft.NetApp/netAppAccounts/account/capacityPools/pool"
+	volumeID = poolID + "/volumes/volume"
+)
+
+// fakeResources keeps sizes in memory and records the resizes
+type fakeResources struct {
+	quota         int64
+	poolSize      int64
+	allocated     int64
+	volumeResizes []int64
+	poolResizes   []int64
+}
+
+func (f *fakeResources) VolumeQuotaBytes(ctx context.Context, volumeID string) (int64, error) {
+	return f.quota, nil
+}
+
+func (f *fakeResources) CapacityPoolSizeBytes(ctx context.Context, poolID string) (int64, error) {
+	return f.poolSize, nil
+}
+
+func (f *fakeResources) CapacityPoolAllocatedBytes(ctx context.Context, poolID string) (int64, error) {
+	return f.allocated, nil
+}
+
+func (f *fakeResources) ResizeVolume(ctx context.Context, volumeID string, sizeBytes int64) error {
+	f.volumeResizes = append(f.volumeResizes, sizeBytes)
+	f.allocated += sizeBytes - f.quota
+	f.quota = sizeBytes
+	return nil
+}
+
+func (f *fakeResources) ResizeCapacityPool(ctx context.Context, poolID string, sizeBytes int64) error {
+	f.poolResizes = append(f.poolResizes, sizeBytes)
+	f.poolSize = sizeBytes
+	return nil
+}
+
+func TestEvaluate(t *testing.T) {
+
+	const (
+		gib = sdkutils.GiBInBytes
+		tib = sdkutils.TiBInBytes
+	)
+
+	tests := []struct {
+		name              string
+		resources         fakeResources
+		consumed          map[string]int64
+		maxVolumeSize     int64
+		maxPoolSize       int64
+		wantVolumeResizes []int64
+		wantPoolResizes   []int64
+		wantErr           bool
+	}{
+		{
+			name:      "usage below the threshold",
+			resources: fakeResources{quota: 100 * gib, poolSize: 4 * tib, allocated: tib},
+			consumed:  map[string]int64{volumeID: 50 * gib},
+		},
+		{
+			name:              "usage above the threshold grows the volume by a step",
+			resources:         fakeResources{quota: 100 * gib, poolSize: 4 * tib, allocated: tib},
+			consumed:          map[string]int64{volumeID: 90 * gib},
+			wantVolumeResizes: []int64{110 * gib},
+		},
+		{
+			name:              "growth is capped at the maximum volume size",
+			resources:         fakeResources{quota: 100 * gib, poolSize: 4 * tib, allocated: tib},
+			consumed:          map[string]int64{volumeID: 90 * gib},
+			maxVolumeSize:     105 * gib,
+			wantVolumeResizes: []int64{105 * gib},
+		},
+		{
+			name:          "volume already at its maximum size",
+			resources:     fakeResources{quota: 100 * gib, poolSize: 4 * tib, allocated: tib},
+			consumed:      map[string]int64{volumeID: 90 * gib},
+			maxVolumeSize: 100 * gib,
+		},
+		{
+			name:              "full pool is grown by one increment first",
+			resources:         fakeResources{quota: 100 * gib, poolSize: 4 * tib, allocated: 4 * tib},
+			consumed:          map[string]int64{volumeID: 90 * gib},
+			wantPoolResizes:   []int64{5 * tib},
+			wantVolumeResizes: []int64{110 * gib},
+		},
+		{
+			name:        "pool growth beyond the maximum pool size",
+			resources:   fakeResources{quota: 100 * gib, poolSize: 4 * tib, allocated: 4 * tib},
+			consumed:    map[string]int64{volumeID: 90 * gib},
+			maxPoolSize: 4 * tib,
+			wantErr:     true,
+		},
+		{
+			name:      "volume without quota",
+			resources: fakeResources{quota: 0, poolSize: 4 * tib, allocated: tib},
+			consumed:  map[string]int64{volumeID: 90 * gib},
+			wantErr:   true,
+		},
+		{
+			name:      "metrics not available",
+			resources: fakeResources{quota: 100 * gib, poolSize: 4 * tib, allocated: tib},
+			consumed:  map[string]int64{},
+			wantErr:   true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resources := test.resources
+			autoscaler, err := New(Config{
+				VolumeIDs:          []string{volumeID},
+				ThresholdPercent:   80,
+				GrowStepBytes:      10 * gib,
+				MaxVolumeSizeBytes: test.maxVolumeSize,
+				MaxPoolSizeBytes:   test.maxPoolSize,
+				Cooldown:           time.Hour,
+				Resources:          &resources,
+			}, metrics.FixedSource{ConsumedBytes: test.consumed})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			err = autoscaler.Evaluate(context.Background(), volumeID)
+			if test.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error")
+				}
+			} else if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			assertSizes(t, "volume", resources.volumeResizes, test.wantVolumeResizes)
+			assertSizes(t, "pool", resources.poolResizes, test.wantPoolResizes)
+		})
+	}
+}
+
+func TestEvaluateCooldown(t *testing.T) {
+
+	resources := &fakeResources{quota: 100 * sdkutils.GiBInBytes, poolSize: 4 * sdkutils.TiBInBytes, allocated: sdkutils.TiBInBytes}
+	source := metrics.FixedSource{ConsumedBytes: map[string]int64{volumeID: 100 * sdkutils.GiBInBytes}}
+
+	autoscaler, err := New(Config{
+		VolumeIDs:        []string{volumeID},
+		ThresholdPercent: 80,
+		GrowStepBytes:    10 * sdkutils.GiBInBytes,
+		Cooldown:         time.Hour,
+		Resources:        resources,
+	}, source)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Usage stays above the threshold after the first growth, the second evaluation is within the cooldown
+	for i := 0; i < 2; i++ {
+		if err := autoscaler.Evaluate(context.Background(), volumeID); err != nil {
+			t.Fatalf("evaluation %v: unexpected error: %v", i, err)
+		}
+	}
+	assertSizes(t, "volume", resources.volumeResizes, []int64{110 * sdkutils.GiBInBytes})
+
+	// Once the cooldown is over the volume grows again
+	autoscaler.lastGrowth[volumeID] = time.Now().Add(-2 * time.Hour)
+	if err := autoscaler.Evaluate(context.Background(), volumeID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assertSizes(t, "volume", resources.volumeResizes, []int64{110 * sdkutils.GiBInBytes, 120 * sdkutils.GiBInBytes})
+}
+
+func TestEvaluateCooldownAcrossRuns(t *testing.T) {
+
+	path := filepath.Join(t.TempDir(), "state.json")
+	resources := &fakeResources{quota: 100 * sdkutils.GiBInBytes, poolSize: 4 * sdkutils.TiBInBytes, allocated: sdkutils.TiBInBytes}
+	source := metrics.FixedSource{ConsumedBytes: map[string]int64{volumeID: 100 * sdkutils.GiBInBytes}}
+
+	// Each run, e.g. autoscale -once from a scheduled job, loads the journal saved by the previous one
+	for i := 0; i < 2; i++ {
+		journal, err := state.Load(path)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		autoscaler, err := New(Config{
+			VolumeIDs:        []string{volumeID},
+			ThresholdPercent: 80,
+			GrowStepBytes:    10 * sdkutils.GiBInBytes,
+			Cooldown:         time.Hour,
+			Journal:          journal,
+			Resources:        resources,
+		}, source)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if err := autoscaler.EvaluateAll(context.Background()); err != nil {
+			t.Fatalf("run %v: unexpected error: %v", i, err)
+		}
+	}
+
+	assertSizes(t, "volume", resources.volumeResizes, []int64{110 * sdkutils.GiBInBytes})
+}
+
+func TestNew(t *testing.T) {
+
+	source := metrics.FixedSource{}
+	for name, config := range map[string]Config{
+		"no volumes":      {ThresholdPercent: 80, GrowStepBytes: 1},
+		"not a volume id": {VolumeIDs: []string{poolID}, ThresholdPercent: 80, GrowStepBytes: 1},
+		"zero threshold":  {VolumeIDs: []string{volumeID}, GrowStepBytes: 1},
+		"threshold >100":  {VolumeIDs: []string{volumeID}, ThresholdPercent: 120, GrowStepBytes: 1},
+		"no grow step":    {VolumeIDs: []string{volumeID}, ThresholdPercent: 80},
+	} {
+		if _, err := New(config, source); err == nil {
+			t.Errorf("%v: expected an error", name)
+		}
+	}
+}
+
+func assertSizes(t *testing.T, kind string, got, want []int64) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("%v resized to %v, want %v", kind, got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("%v resized to %v, want %v", kind, got, want)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// This package creates the resource group, virtual network and
+// delegated subnet needed by Azure NetApp Files volumes when they
+// do not exist yet, so throwaway environments do not have to be
+// prepared by hand. Everything created is recorded in the state
+// journal so it can be removed by a teardown.
+
+package bootstrap
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/logging"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/sdkutils"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/state"
+)
+
+// NetworkSpec describes the network volumes are created in
+type NetworkSpec struct {
+	Location            string
+	ResourceGroupName   string
+	VnetName            string
+	VnetAddressSpace    []string
+	SubnetName          string
+	SubnetAddressPrefix string
+	Tags                map[string]*string
+}
+
+// ResourceGroup creates a resource group when it does not exist, it returns the
+// resource ids created, which is empty when the resource group was already there
+func ResourceGroup(ctx context.Context, subscriptionID, location, resourceGroupName string, tags map[string]*string, journal *state.Journal) ([]string, error) {
+
+	resourceGroupID := fmt.Sprintf("/subscriptions/%v/resourceGroups/%v", subscriptionID, resourceGroupName)
+
+	_, created, err := sdkutils.EnsureResourceGroup(ctx, location, resourceGroupName, tags)
+	if err != nil {
+		return nil, err
+	}
+	if !created {
+		return nil, nil
+	}
+	logging.Info(fmt.Sprintf("Resource group %v created", resourceGroupName))
+
+	err = journal.Record(resourceGroupID, state.ResourceGroupType)
+	if err != nil {
+		return nil, err
+	}
+
+	return []string{resourceGroupID}, nil
+}
+
+// Network creates the resource group, virtual network and delegated subnet described by spec
+// when they do not exist, it returns the subnet resource id and the resource ids created in creation order
+func Network(ctx context.Context, subscriptionID string, spec NetworkSpec, journal *state.Journal) (string, []string, error) {
+
+	vnetID := fmt.Sprintf("/subscriptions/%v/resourceGroups/%v/providers/Microsoft.Network/virtualNetworks/%v",
+		subscriptionID,
+		spec.ResourceGroupName,
+		spec.VnetName,
+	)
+	subnetID := fmt.Sprintf("%v/subnets/%v", vnetID, spec.SubnetName)
+
+	created, err := ResourceGroup(ctx, subscriptionID, spec.Location, spec.ResourceGroupName, spec.Tags, journal)
+	if err != nil {
+		return "", created, err
+	}
+
+	exists, err := sdkutils.VirtualNetworkExists(ctx, vnetID)
+	if err != nil {
+		return "", created, err
+	}
+
+	if !exists {
+		logging.Info(fmt.Sprintf("Creating virtual network %v with address space %v...", spec.VnetName, spec.VnetAddressSpace))
+		_, err = sdkutils.CreateVirtualNetwork(ctx, spec.Location, spec.ResourceGroupName, spec.VnetName, spec.VnetAddressSpace, spec.Tags)
+		if err != nil {
+			return "", created, err
+		}
+
+		err = journal.Record(vnetID, state.VirtualNetworkType)
+		if err != nil {
+			return "", created, err
+		}
+		created = append(created, vnetID)
+	}
+
+	exists, err = sdkutils.SubnetExists(ctx, subnetID)
+	if err != nil {
+		return "", created, err
+	}
+
+	if !exists {
+		logging.Info(fmt.Sprintf("Creating subnet %v with address prefix %v delegated to Microsoft.NetApp/volumes...", spec.SubnetName, spec.SubnetAddressPrefix))
+		_, err = sdkutils.CreateDelegatedSubnet(ctx, spec.ResourceGroupName, spec.VnetName, spec.SubnetName, spec.SubnetAddressPrefix)
+		if err != nil {
+			return "", created, err
+		}
+
+		err = journal.Record(subnetID, state.SubnetType)
+		if err != nil {
+			return "", created, err
+		}
+		created = append(created, subnetID)
+	}
+
+	return subnetID, created, nil
+}
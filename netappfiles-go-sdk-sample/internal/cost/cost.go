@@ -0,0 +1,207 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// This package estimates the monthly cost of a deployment spec from
+// a price catalog, without calling any Azure API. Capacity is charged
+// per provisioned pool size, backups per estimated backup storage and
+// replication per replicated volume quota plus its destination capacity.
+
+package cost
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/models"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/pricing"
+)
+
+const (
+	// FeatureCapacity is the provisioned capacity of a pool
+	FeatureCapacity string = "capacity"
+	// FeatureBackup is the backup storage of a volume
+	FeatureBackup string = "backup"
+	// FeatureReplication is the replication of a volume, including its destination capacity
+	FeatureReplication string = "replication"
+)
+
+// LineItem is the monthly cost of a feature of a resource
+type LineItem struct {
+	Account     string  `json:"account"`
+	Pool        string  `json:"pool"`
+	Volume      string  `json:"volume,omitempty"`
+	Feature     string  `json:"feature"`
+	Region      string  `json:"region"`
+	QuantityGiB int64   `json:"quantityGiB"`
+	UnitPrice   float64 `json:"unitPrice"`
+	MonthlyCost float64 `json:"monthlyCost"`
+}
+
+// Estimate is the monthly cost breakdown of a deployment spec
+type Estimate struct {
+	Currency  string             `json:"currency"`
+	Items     []LineItem         `json:"items"`
+	ByAccount map[string]float64 `json:"byAccount"`
+	ByPool    map[string]float64 `json:"byPool"`
+	ByFeature map[string]float64 `json:"byFeature"`
+	Total     float64            `json:"total"`
+}
+
+// EstimateDeployment calculates the monthly cost of every account, pool and feature of a deployment spec
+func EstimateDeployment(spec models.DeploymentSpec, catalog pricing.Catalog) (Estimate, error) {
+
+	estimate := Estimate{
+		Currency:  catalog.Currency,
+		Items:     []LineItem{},
+		ByAccount: map[string]float64{},
+		ByPool:    map[string]float64{},
+		ByFeature: map[string]float64{},
+	}
+
+	for _, account := range spec.Accounts {
+		region := account.Location
+		if region == "" {
+			region = spec.Location
+		}
+
+		for _, pool := range account.Pools {
+			err := estimate.add(catalog, LineItem{
+				Account:     account.Name,
+				Pool:        pool.Name,
+				Feature:     FeatureCapacity,
+				Region:      region,
+				QuantityGiB: pool.SizeTiB * 1024,
+			}, pool.ServiceLevel)
+			if err != nil {
+				return Estimate{}, err
+			}
+
+			for _, volume := range pool.Volumes {
+				if volume.Backup != nil {
+					backupGiB := volume.Backup.EstimatedSizeGiB
+					if backupGiB == 0 {
+						backupGiB = volume.QuotaGiB
+					}
+
+					err = estimate.add(catalog, LineItem{
+						Account:     account.Name,
+						Pool:        pool.Name,
+						Volume:      volume.Name,
+						Feature:     FeatureBackup,
+						Region:      region,
+						QuantityGiB: backupGiB,
+					}, pricing.Backup)
+					if err != nil {
+						return Estimate{}, err
+					}
+				}
+
+				if volume.Replication != nil {
+					err = estimate.add(catalog, LineItem{
+						Account:     account.Name,
+						Pool:        pool.Name,
+						Volume:      volume.Name,
+						Feature:     FeatureReplication,
+						Region:      region,
+						QuantityGiB: volume.QuotaGiB,
+					}, pricing.Replication)
+					if err != nil {
+						return Estimate{}, err
+					}
+
+					// Destination volume needs capacity at the remote location too
+					err = estimate.add(catalog, LineItem{
+						Account:     account.Name,
+						Pool:        pool.Name,
+						Volume:      volume.Name,
+						Feature:     FeatureReplication,
+						Region:      volume.Replication.RemoteLocation,
+						QuantityGiB: volume.QuotaGiB,
+					}, volume.Replication.RemoteServiceLevel)
+					if err != nil {
+						return Estimate{}, err
+					}
+				}
+			}
+		}
+	}
+
+	return estimate, nil
+}
+
+// add prices a line item and adds it to the estimate totals
+func (e *Estimate) add(catalog pricing.Catalog, item LineItem, priceItem string) error {
+
+	price, err := catalog.Price(item.Region, priceItem)
+	if err != nil {
+		return fmt.Errorf("account %v, pool %v: %v", item.Account, item.Pool, err)
+	}
+
+	item.UnitPrice = price
+	item.MonthlyCost = float64(item.QuantityGiB) * price
+
+	e.Items = append(e.Items, item)
+	e.ByAccount[item.Account] += item.MonthlyCost
+	e.ByPool[fmt.Sprintf("%v/%v", item.Account, item.Pool)] += item.MonthlyCost
+	e.ByFeature[item.Feature] += item.MonthlyCost
+	e.Total += item.MonthlyCost
+
+	return nil
+}
+
+// WriteJSON writes the estimate as indented json
+func (e Estimate) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(e)
+}
+
+// WriteTable writes the estimate line items and totals as a text table
+func (e Estimate) WriteTable(w io.Writer) error {
+
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(table, "ACCOUNT\tPOOL\tVOLUME\tFEATURE\tREGION\tGiB\tPRICE/GiB\tMONTHLY (%v)\n", e.Currency)
+	for _, item := range e.Items {
+		fmt.Fprintf(table, "%v\t%v\t%v\t%v\t%v\t%v\t%.5f\t%.2f\n",
+			item.Account,
+			item.Pool,
+			map[bool]string{true: item.Volume, false: "-"}[item.Volume != ""],
+			item.Feature,
+			item.Region,
+			item.QuantityGiB,
+			item.UnitPrice,
+			item.MonthlyCost,
+		)
+	}
+	fmt.Fprintln(table)
+
+	for _, totals := range []struct {
+		title  string
+		values map[string]float64
+	}{{"ACCOUNT", e.ByAccount}, {"POOL", e.ByPool}, {"FEATURE", e.ByFeature}} {
+		fmt.Fprintf(table, "%v\tMONTHLY (%v)\n", totals.title, e.Currency)
+		for _, key := range sortedKeys(totals.values) {
+			fmt.Fprintf(table, "%v\t%.2f\n", key, totals.values[key])
+		}
+		fmt.Fprintln(table)
+	}
+
+	fmt.Fprintf(table, "TOTAL\t%.2f %v\n", e.Total, e.Currency)
+
+	return table.Flush()
+}
+
+func sortedKeys(values map[string]float64) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
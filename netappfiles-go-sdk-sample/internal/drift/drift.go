@@ -0,0 +1,382 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// This package detects drift between the accounts, capacity pools
+// and volumes managed by this sample and their desired state, which
+// is either a deployment spec or the last applied properties stored
+// in the state journal. Differences can be reconciled back, except
+// for the ones Azure cannot change in place such as service levels
+// or protocol types.
+
+package drift
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/gc"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/models"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/sdkutils"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/state"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/uri"
+	"github.com/Azure/azure-sdk-for-go/services/netapp/mgmt/2021-04-01/netapp"
+	"github.com/Azure/go-autorest/autorest/to"
+)
+
+// Properties are the managed properties of an account, capacity pool or volume. Zero values, nil tags and nil
+// export rules mean the property is not part of the desired state and is not compared.
+type Properties struct {
+	Tags          map[string]string `json:"tags"`
+	ServiceLevel  string            `json:"serviceLevel,omitempty"`
+	SizeTiB       int64             `json:"sizeTiB,omitempty"`
+	QosType       string            `json:"qosType,omitempty"`
+	QuotaGiB      int64             `json:"quotaGiB,omitempty"`
+	ProtocolTypes []string          `json:"protocolTypes,omitempty"`
+	ExportRules   []ExportRule      `json:"exportRules"`
+}
+
+// ExportRule is a volume export policy rule
+type ExportRule struct {
+	RuleIndex      int32  `json:"ruleIndex"`
+	AllowedClients string `json:"allowedClients"`
+	Nfsv3          bool   `json:"nfsv3"`
+	Nfsv41         bool   `json:"nfsv41"`
+	Cifs           bool   `json:"cifs"`
+	UnixReadOnly   bool   `json:"unixReadOnly"`
+	UnixReadWrite  bool   `json:"unixReadWrite"`
+	HasRootAccess  bool   `json:"hasRootAccess"`
+}
+
+// Difference is a property whose live value is not the desired one
+type Difference struct {
+	ResourceID string
+	Property   string
+	Desired    string
+	Actual     string
+}
+
+// Report is the outcome of a drift check
+type Report struct {
+	Differences []Difference
+	Missing     []string
+	NoBaseline  []string
+	Unmanaged   []string
+
+	// desired and live properties of the resources with differences, by resource id
+	desired map[string]Properties
+	actual  map[string]Properties
+}
+
+// HasDrift checks if live resources differ from their desired state, are missing or cannot be checked
+// because they have no last applied properties
+func (r Report) HasDrift() bool {
+	return len(r.Differences) > 0 || len(r.Missing) > 0 || len(r.NoBaseline) > 0
+}
+
+// AccountProperties returns the managed properties of an account
+func AccountProperties(account netapp.Account) Properties {
+	return Properties{Tags: toTags(account.Tags)}
+}
+
+// PoolProperties returns the managed properties of a capacity pool
+func PoolProperties(pool netapp.CapacityPool) Properties {
+
+	properties := Properties{Tags: toTags(pool.Tags)}
+	if pool.PoolProperties != nil {
+		properties.ServiceLevel = string(pool.ServiceLevel)
+		properties.SizeTiB = to.Int64(pool.Size) / sdkutils.TiBInBytes
+		properties.QosType = string(pool.QosType)
+	}
+
+	return properties
+}
+
+// VolumeProperties returns the managed properties of a volume
+func VolumeProperties(volume netapp.Volume) Properties {
+
+	properties := Properties{Tags: toTags(volume.Tags), ExportRules: []ExportRule{}}
+	if volume.VolumeProperties == nil {
+		return properties
+	}
+
+	properties.ServiceLevel = string(volume.ServiceLevel)
+	properties.QuotaGiB = to.Int64(volume.UsageThreshold) / sdkutils.GiBInBytes
+	if volume.ProtocolTypes != nil {
+		properties.ProtocolTypes = *volume.ProtocolTypes
+	}
+	if volume.ExportPolicy != nil && volume.ExportPolicy.Rules != nil {
+		for _, rule := range *volume.ExportPolicy.Rules {
+			properties.ExportRules = append(properties.ExportRules, ExportRule{
+				RuleIndex:      to.Int32(rule.RuleIndex),
+				AllowedClients: to.String(rule.AllowedClients),
+				Nfsv3:          to.Bool(rule.Nfsv3),
+				Nfsv41:         to.Bool(rule.Nfsv41),
+				Cifs:           to.Bool(rule.Cifs),
+				UnixReadOnly:   to.Bool(rule.UnixReadOnly),
+				UnixReadWrite:  to.Bool(rule.UnixReadWrite),
+				HasRootAccess:  to.Bool(rule.HasRootAccess),
+			})
+		}
+		sort.Slice(properties.ExportRules, func(i, j int) bool {
+			return properties.ExportRules[i].RuleIndex < properties.ExportRules[j].RuleIndex
+		})
+	}
+
+	return properties
+}
+
+// Live reads the managed properties of an account, capacity pool or volume, found is false when it no longer exists
+func Live(ctx context.Context, resourceID string) (properties Properties, found bool, err error) {
+
+	exists, err := sdkutils.ResourceExists(ctx, resourceID, sdkutils.NetAppAPIVersion)
+	if err != nil || !exists {
+		return Properties{}, false, err
+	}
+
+	switch {
+	case uri.IsANFVolume(resourceID):
+		volume, err := sdkutils.GetANFVolumeByID(ctx, resourceID)
+		return VolumeProperties(volume), true, err
+	case uri.IsANFCapacityPool(resourceID):
+		pool, err := sdkutils.GetANFCapacityPoolByID(ctx, resourceID)
+		return PoolProperties(pool), true, err
+	case uri.IsANFAccount(resourceID):
+		account, err := sdkutils.GetANFAccountByID(ctx, resourceID)
+		return AccountProperties(account), true, err
+	}
+
+	return Properties{}, false, fmt.Errorf("drift detection does not support %v", resourceID)
+}
+
+// FromSpec returns the desired properties of every resource of a spec, keyed by account, account/pool and
+// account/pool/volume names in lower case. Spec tags are merged with the tags of each resource.
+func FromSpec(spec models.DeploymentSpec) map[string]Properties {
+
+	desired := map[string]Properties{}
+	tags := func(resourceTags map[string]string) map[string]string {
+		if spec.Tags == nil && resourceTags == nil {
+			return nil
+		}
+		merged := map[string]string{}
+		for key, value := range spec.Tags {
+			merged[key] = value
+		}
+		for key, value := range resourceTags {
+			merged[key] = value
+		}
+		return merged
+	}
+
+	for _, account := range spec.Accounts {
+		desired[strings.ToLower(account.Name)] = Properties{Tags: tags(account.Tags)}
+
+		for _, pool := range account.Pools {
+			poolKey := strings.ToLower(fmt.Sprintf("%v/%v", account.Name, pool.Name))
+			desired[poolKey] = Properties{
+				Tags:         tags(pool.Tags),
+				ServiceLevel: pool.ServiceLevel,
+				SizeTiB:      pool.SizeTiB,
+				QosType:      pool.QosType,
+			}
+
+			for _, volume := range pool.Volumes {
+				desired[fmt.Sprintf("%v/%v", poolKey, strings.ToLower(volume.Name))] = Properties{
+					Tags:          tags(volume.Tags),
+					ServiceLevel:  pool.ServiceLevel,
+					QuotaGiB:      volume.QuotaGiB,
+					ProtocolTypes: volume.ProtocolTypes,
+				}
+			}
+		}
+	}
+
+	return desired
+}
+
+// specKey returns the FromSpec key of a resource id
+func specKey(resourceID string) string {
+	names := []string{uri.GetANFAccount(resourceID)}
+	if pool := uri.GetANFCapacityPool(resourceID); pool != "" {
+		names = append(names, pool)
+	}
+	if volume := uri.GetANFVolume(resourceID); volume != "" {
+		names = append(names, volume)
+	}
+	return strings.ToLower(strings.Join(names, "/"))
+}
+
+// Compare lists the desired properties whose live value differs, ignoredTags are not compared, e.g. the lifecycle
+// tags set by the sample which a deployment spec does not hold
+func Compare(resourceID string, desired, actual Properties, ignoredTags []string) []Difference {
+
+	differences := []Difference{}
+	add := func(property string, desiredValue, actualValue interface{}) {
+		d, a := fmt.Sprintf("%v", desiredValue), fmt.Sprintf("%v", actualValue)
+		if d != a {
+			differences = append(differences, Difference{ResourceID: resourceID, Property: property, Desired: d, Actual: a})
+		}
+	}
+
+	if desired.Tags != nil {
+		keys := map[string]bool{}
+		for key := range desired.Tags {
+			keys[key] = true
+		}
+		for key := range actual.Tags {
+			keys[key] = true
+		}
+		for _, key := range ignoredTags {
+			delete(keys, key)
+		}
+		for key := range keys {
+			desiredValue, desiredFound := desired.Tags[key]
+			actualValue, actualFound := actual.Tags[key]
+			if desiredFound != actualFound || desiredValue != actualValue {
+				add(fmt.Sprintf("tags.%v", key), tagValue(desiredValue, desiredFound), tagValue(actualValue, actualFound))
+			}
+		}
+	}
+	if desired.ServiceLevel != "" {
+		add("serviceLevel", desired.ServiceLevel, actual.ServiceLevel)
+	}
+	if desired.SizeTiB != 0 {
+		add("sizeTiB", desired.SizeTiB, actual.SizeTiB)
+	}
+	if desired.QosType != "" {
+		add("qosType", desired.QosType, actual.QosType)
+	}
+	if desired.QuotaGiB != 0 {
+		add("quotaGiB", desired.QuotaGiB, actual.QuotaGiB)
+	}
+	if desired.ProtocolTypes != nil {
+		add("protocolTypes", strings.Join(desired.ProtocolTypes, ","), strings.Join(actual.ProtocolTypes, ","))
+	}
+	if desired.ExportRules != nil {
+		add("exportPolicy", exportRulesString(desired.ExportRules), exportRulesString(actual.ExportRules))
+	}
+
+	sort.Slice(differences, func(i, j int) bool { return differences[i].Property < differences[j].Property })
+	return differences
+}
+
+// Check compares every account, capacity pool and volume recorded in the journal with the spec, or with the
+// last applied properties when spec is nil. Resources without last applied properties are reported, their live
+// properties are not adopted as baseline since they may already have drifted, see RecordApplied.
+func Check(ctx context.Context, journal *state.Journal, spec *models.DeploymentSpec) (Report, error) {
+
+	report := Report{desired: map[string]Properties{}, actual: map[string]Properties{}}
+
+	// Last applied properties hold the lifecycle tags, a spec does not
+	var desiredFromSpec map[string]Properties
+	var ignoredTags []string
+	if spec != nil {
+		desiredFromSpec = FromSpec(*spec)
+		ignoredTags = gc.LifecycleTagNames
+	}
+
+	for _, entry := range journal.Entries {
+		if entry.Type != state.AccountType && entry.Type != state.CapacityPoolType && entry.Type != state.VolumeType {
+			continue
+		}
+
+		actual, found, err := Live(ctx, entry.ResourceID)
+		if err != nil {
+			return Report{}, err
+		}
+		if !found {
+			report.Missing = append(report.Missing, entry.ResourceID)
+			continue
+		}
+
+		var desired Properties
+		if spec != nil {
+			var inSpec bool
+			desired, inSpec = desiredFromSpec[specKey(entry.ResourceID)]
+			if !inSpec {
+				report.Unmanaged = append(report.Unmanaged, entry.ResourceID)
+				continue
+			}
+		} else {
+			if len(entry.Applied) == 0 {
+				report.NoBaseline = append(report.NoBaseline, entry.ResourceID)
+				continue
+			}
+			err = json.Unmarshal(entry.Applied, &desired)
+			if err != nil {
+				return Report{}, fmt.Errorf("cannot parse applied properties of %v: %v", entry.ResourceID, err)
+			}
+		}
+
+		differences := Compare(entry.ResourceID, desired, actual, ignoredTags)
+		if len(differences) > 0 {
+			report.Differences = append(report.Differences, differences...)
+			report.desired[entry.ResourceID] = desired
+			report.actual[entry.ResourceID] = actual
+		}
+	}
+
+	return report, nil
+}
+
+// RecordApplied records the live properties of resources as their last applied properties, every command changing
+// accounts, capacity pools or volumes calls it so drift detection neither reports nor reverts the changes of the tool.
+// Resources that are not accounts, capacity pools or volumes recorded in the journal are skipped, as is a nil journal.
+func RecordApplied(ctx context.Context, journal *state.Journal, resourceIDs ...string) error {
+
+	if journal == nil {
+		return nil
+	}
+
+	for _, resourceID := range resourceIDs {
+		if !journal.Contains(resourceID) || !(uri.IsANFAccount(resourceID) || uri.IsANFCapacityPool(resourceID) || uri.IsANFVolume(resourceID)) {
+			continue
+		}
+
+		actual, found, err := Live(ctx, resourceID)
+		if err != nil {
+			return err
+		}
+		if !found {
+			continue
+		}
+		err = journal.SetApplied(resourceID, actual)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func tagValue(value string, found bool) string {
+	if !found {
+		return "<none>"
+	}
+	return value
+}
+
+func exportRulesString(rules []ExportRule) string {
+	if len(rules) == 0 {
+		return "<none>"
+	}
+	descriptions := []string{}
+	for _, rule := range rules {
+		descriptions = append(descriptions, fmt.Sprintf("%v:%v nfsv3=%v nfsv41=%v cifs=%v ro=%v rw=%v root=%v",
+			rule.RuleIndex, rule.AllowedClients, rule.Nfsv3, rule.Nfsv41, rule.Cifs, rule.UnixReadOnly, rule.UnixReadWrite, rule.HasRootAccess))
+	}
+	return strings.Join(descriptions, "; ")
+}
+
+// toTags converts SDK tags, an empty map means the resource has no tags
+func toTags(tags map[string]*string) map[string]string {
+	result := map[string]string{}
+	for key, value := range tags {
+		result[key] = to.String(value)
+	}
+	return result
+}
@@ -0,0 +1,89 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package drift
+
+import (
+	"testing"
+
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/gc"
+	"github.com/Azure/go-autorest/autorest/to"
+)
+
+const volumeID = "/subscriptions/s/resourceGroups/rg/providers/Microsoft.NetApp/netAppAccounts/account/capacityPools/pool/volumes/volume"
+
+func TestCompareTags(t *testing.T) {
+
+	desired := Properties{Tags: map[string]string{"env": "prod", "owner": "alice"}}
+	actual := Properties{Tags: map[string]string{
+		"env":               "dev",
+		"owner":             "alice",
+		"CreatedBy":         "anf-go-sdk-sample",
+		gc.CreatedAtTagName: "2021-07-30T00:00:00Z",
+		gc.ExpiresAtTagName: "2021-07-31T00:00:00Z",
+	}}
+
+	tests := []struct {
+		name        string
+		ignoredTags []string
+		want        []string
+	}{
+		{
+			name:        "lifecycle tags ignored",
+			ignoredTags: gc.LifecycleTagNames,
+			want:        []string{"tags.env"},
+		},
+		{
+			name: "every tag compared",
+			want: []string{"tags.env", "tags.CreatedBy", "tags.createdAt", "tags.expiresAt"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			differences := map[string]bool{}
+			for _, difference := range Compare(volumeID, desired, actual, test.ignoredTags) {
+				differences[difference.Property] = true
+			}
+			if len(differences) != len(test.want) {
+				t.Fatalf("got differences %v, want %v", differences, test.want)
+			}
+			for _, property := range test.want {
+				if !differences[property] {
+					t.Errorf("missing %v difference, got %v", property, differences)
+				}
+			}
+		})
+	}
+}
+
+func TestReconciledTags(t *testing.T) {
+
+	desired := Properties{Tags: map[string]string{"env": "prod"}}
+	actual := Properties{Tags: map[string]string{
+		"env":               "dev",
+		"owner":             "alice",
+		"CreatedBy":         "anf-go-sdk-sample",
+		gc.CreatedAtTagName: "2021-07-30T00:00:00Z",
+		gc.ExpiresAtTagName: "2021-07-31T00:00:00Z",
+	}}
+
+	want := map[string]string{
+		"env":               "prod",
+		"CreatedBy":         "anf-go-sdk-sample",
+		gc.CreatedAtTagName: "2021-07-30T00:00:00Z",
+		gc.ExpiresAtTagName: "2021-07-31T00:00:00Z",
+	}
+
+	got := reconciledTags(desired, actual)
+	if len(got) != len(want) {
+		t.Fatalf("got tags %v, want %v", got, want)
+	}
+	for key, value := range want {
+		if to.String(got[key]) != value {
+			t.Errorf("tag %v = %q, want %q", key, to.String(got[key]), value)
+		}
+	}
+}
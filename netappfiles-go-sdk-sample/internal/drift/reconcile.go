@@ -0,0 +1,123 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package drift
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/gc"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/sdkutils"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/state"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/tagging"
+	"github.com/Azure/azure-sdk-for-go/services/netapp/mgmt/2021-04-01/netapp"
+	"github.com/Azure/go-autorest/autorest/to"
+)
+
+// Failure is a difference that could not be reconciled
+type Failure struct {
+	Difference Difference
+	Err        error
+}
+
+// ReconcileResult lists the differences reconciled and the ones that failed
+type ReconcileResult struct {
+	Reconciled []Difference
+	Failed     []Failure
+}
+
+// Reconcile changes live resources back to their desired properties, in journal order so capacity pools grow
+// before their volumes. Reconciled resources get their live properties recorded as last applied properties.
+func Reconcile(ctx context.Context, journal *state.Journal, report Report) ReconcileResult {
+
+	result := ReconcileResult{}
+
+	byResource := map[string][]Difference{}
+	resourceIDs := []string{}
+	for _, difference := range report.Differences {
+		if _, found := byResource[difference.ResourceID]; !found {
+			resourceIDs = append(resourceIDs, difference.ResourceID)
+		}
+		byResource[difference.ResourceID] = append(byResource[difference.ResourceID], difference)
+	}
+
+	for _, resourceID := range resourceIDs {
+		desired := report.desired[resourceID]
+		tagsReconciled := false
+		failed := false
+
+		for _, difference := range byResource[resourceID] {
+			var err error
+			switch {
+			case strings.HasPrefix(difference.Property, "tags."):
+				// All tags are replaced at once, except the lifecycle tags
+				if !tagsReconciled {
+					err = sdkutils.UpdateANFResourceTags(ctx, resourceID, reconciledTags(desired, report.actual[resourceID]))
+					tagsReconciled = err == nil
+				}
+			case difference.Property == "sizeTiB":
+				_, err = sdkutils.ResizeANFCapacityPool(ctx, resourceID, desired.SizeTiB*sdkutils.TiBInBytes)
+			case difference.Property == "qosType":
+				_, err = sdkutils.UpdateANFCapacityPool(ctx, resourceID, "", desired.QosType, nil)
+			case difference.Property == "quotaGiB":
+				_, err = sdkutils.ResizeANFVolume(ctx, resourceID, desired.QuotaGiB*sdkutils.GiBInBytes)
+			case difference.Property == "exportPolicy":
+				_, err = sdkutils.SetANFVolumeExportPolicy(ctx, resourceID, toSDKExportRules(desired.ExportRules))
+			case difference.Property == "serviceLevel":
+				err = fmt.Errorf("service level cannot be changed in place, move the volume to a pool with the desired service level")
+			default:
+				err = fmt.Errorf("%v cannot be changed in place", difference.Property)
+			}
+
+			if err != nil {
+				failed = true
+				result.Failed = append(result.Failed, Failure{Difference: difference, Err: err})
+				continue
+			}
+			result.Reconciled = append(result.Reconciled, difference)
+		}
+
+		if !failed {
+			err := RecordApplied(ctx, journal, resourceID)
+			if err != nil {
+				result.Failed = append(result.Failed, Failure{Difference: Difference{ResourceID: resourceID, Property: "applied"}, Err: err})
+			}
+		}
+	}
+
+	return result
+}
+
+// reconciledTags returns the desired tags with the live lifecycle tags the desired state does not set
+func reconciledTags(desired, actual Properties) map[string]*string {
+	return tagging.Change{Add: desired.Tags, Replace: true, Preserve: gc.LifecycleTagNames}.Apply(toSDKTags(actual.Tags))
+}
+
+func toSDKTags(tags map[string]string) map[string]*string {
+	result := map[string]*string{}
+	for key, value := range tags {
+		result[key] = to.StringPtr(value)
+	}
+	return result
+}
+
+func toSDKExportRules(rules []ExportRule) []netapp.ExportPolicyRule {
+	result := []netapp.ExportPolicyRule{}
+	for _, rule := range rules {
+		result = append(result, netapp.ExportPolicyRule{
+			RuleIndex:      to.Int32Ptr(rule.RuleIndex),
+			AllowedClients: to.StringPtr(rule.AllowedClients),
+			Nfsv3:          to.BoolPtr(rule.Nfsv3),
+			Nfsv41:         to.BoolPtr(rule.Nfsv41),
+			Cifs:           to.BoolPtr(rule.Cifs),
+			UnixReadOnly:   to.BoolPtr(rule.UnixReadOnly),
+			UnixReadWrite:  to.BoolPtr(rule.UnixReadWrite),
+			HasRootAccess:  to.BoolPtr(rule.HasRootAccess),
+		})
+	}
+	return result
+}
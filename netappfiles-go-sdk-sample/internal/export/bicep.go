@@ -0,0 +1,117 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package export
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var identifier = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// WriteBicep writes the template as a Bicep file, nested resources use parent and volumes reference
+// the policies they use by symbolic name so Bicep infers the dependencies
+func (t Template) WriteBicep(w io.Writer) error {
+
+	b := bufio.NewWriter(w)
+
+	for _, p := range t.parameters {
+		fmt.Fprintf(b, "@description(%v)\n", bicepString(p.description))
+		fmt.Fprintf(b, "param %v string = %v\n\n", p.name, bicepString(p.defaultValue))
+	}
+
+	for i, r := range t.resources {
+		if i > 0 {
+			fmt.Fprintln(b)
+		}
+
+		fmt.Fprintf(b, "resource %v '%v@%v' = {\n", r.symbol, r.resourceType, apiVersion)
+		if r.parent != nil {
+			fmt.Fprintf(b, "  parent: %v\n", r.parent.symbol)
+		}
+		fmt.Fprintf(b, "  name: %v\n", r.nameParam)
+		fmt.Fprintln(b, "  location: location")
+		if len(r.tags) > 0 {
+			fmt.Fprint(b, "  tags: ")
+			writeBicepValue(b, r.tags, 1)
+			fmt.Fprintln(b)
+		}
+		fmt.Fprint(b, "  properties: ")
+		writeBicepValue(b, r.properties, 1)
+		fmt.Fprintln(b)
+		fmt.Fprintln(b, "}")
+	}
+
+	return b.Flush()
+}
+
+// writeBicepValue writes a value decoded from SDK properties, nested objects and arrays are indented by two spaces per level
+func writeBicepValue(b *bufio.Writer, value interface{}, level int) {
+
+	indent := strings.Repeat("  ", level)
+
+	switch v := value.(type) {
+	case nil:
+		fmt.Fprint(b, "null")
+	case string:
+		fmt.Fprint(b, bicepString(v))
+	case *string:
+		if v == nil {
+			fmt.Fprint(b, "null")
+			return
+		}
+		fmt.Fprint(b, bicepString(*v))
+	case bool, json.Number:
+		fmt.Fprint(b, v)
+	case expression:
+		fmt.Fprint(b, v.bicep)
+	case map[string]*string:
+		values := map[string]interface{}{}
+		for key, value := range v {
+			values[key] = value
+		}
+		writeBicepValue(b, values, level)
+	case map[string]interface{}:
+		keys := []string{}
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		fmt.Fprintln(b, "{")
+		for _, key := range keys {
+			name := key
+			if !identifier.MatchString(key) {
+				name = bicepString(key)
+			}
+			fmt.Fprintf(b, "%v  %v: ", indent, name)
+			writeBicepValue(b, v[key], level+1)
+			fmt.Fprintln(b)
+		}
+		fmt.Fprintf(b, "%v}", indent)
+	case []interface{}:
+		fmt.Fprintln(b, "[")
+		for _, item := range v {
+			fmt.Fprintf(b, "%v  ", indent)
+			writeBicepValue(b, item, level+1)
+			fmt.Fprintln(b)
+		}
+		fmt.Fprintf(b, "%v]", indent)
+	default:
+		fmt.Fprintf(b, "%v", v)
+	}
+}
+
+// bicepString quotes a string the Bicep way, with single quotes and escaped interpolations
+func bicepString(value string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `'`, `\'`, "${", `\${`, "\n", `\n`, "\r", `\r`, "\t", `\t`)
+	return fmt.Sprintf("'%v'", replacer.Replace(value))
+}
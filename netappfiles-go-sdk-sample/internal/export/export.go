@@ -0,0 +1,402 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// This package exports a deployed account, with its snapshot and
+// backup policies, capacity pools and volumes, as an ARM json
+// template or a Bicep file. Resource names and subnets become
+// parameters, tags and data protection settings are kept and
+// dependencies are expressed with dependsOn (ARM) or parent and
+// symbolic references (Bicep). Active Directory connections are
+// not exported since they hold credentials.
+
+package export
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/sdkutils"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/uri"
+	"github.com/Azure/azure-sdk-for-go/services/netapp/mgmt/2021-04-01/netapp"
+)
+
+const (
+	apiVersion string = "2021-04-01"
+
+	accountType        string = "Microsoft.NetApp/netAppAccounts"
+	poolType           string = "Microsoft.NetApp/netAppAccounts/capacityPools"
+	volumeType         string = "Microsoft.NetApp/netAppAccounts/capacityPools/volumes"
+	snapshotPolicyType string = "Microsoft.NetApp/netAppAccounts/snapshotPolicies"
+	backupPolicyType   string = "Microsoft.NetApp/netAppAccounts/backupPolicies"
+	vaultType          string = "Microsoft.NetApp/netAppAccounts/vaults"
+)
+
+// Tree is a deployed account with everything it contains
+type Tree struct {
+	Account          netapp.Account
+	SnapshotPolicies []netapp.SnapshotPolicy
+	BackupPolicies   []netapp.BackupPolicy
+	Pools            []PoolTree
+}
+
+// PoolTree is a deployed capacity pool with its volumes
+type PoolTree struct {
+	Pool    netapp.CapacityPool
+	Volumes []netapp.Volume
+}
+
+// expression is a template language expression, written as [arm] in ARM templates and as is in Bicep
+type expression struct {
+	arm   string
+	bicep string
+}
+
+// MarshalJSON writes the expression the way ARM templates expect it
+func (e expression) MarshalJSON() ([]byte, error) {
+	return json.Marshal(fmt.Sprintf("[%v]", e.arm))
+}
+
+// parameter is a template parameter, all of them are strings
+type parameter struct {
+	name         string
+	defaultValue string
+	description  string
+}
+
+// resource is a template resource
+type resource struct {
+	symbol       string
+	resourceType string
+	nameParam    string
+	parent       *resource
+	tags         map[string]*string
+	properties   map[string]interface{}
+	dependsOn    []*resource
+}
+
+// Template is an account tree ready to be written as ARM json or Bicep
+type Template struct {
+	parameters []parameter
+	resources  []*resource
+}
+
+// ReadTree reads an account, its policies, capacity pools and volumes
+func ReadTree(ctx context.Context, accountID string) (Tree, error) {
+
+	account, err := sdkutils.GetANFAccountByID(ctx, accountID)
+	if err != nil {
+		return Tree{}, err
+	}
+
+	resourceGroupName := uri.GetResourceGroup(accountID)
+	accountName := uri.GetANFAccount(accountID)
+
+	snapshotPolicies, err := sdkutils.ListANFSnapshotPolicies(ctx, resourceGroupName, accountName)
+	if err != nil {
+		return Tree{}, err
+	}
+
+	backupPolicies, err := sdkutils.ListANFBackupPolicies(ctx, resourceGroupName, accountName)
+	if err != nil {
+		return Tree{}, err
+	}
+
+	pools, err := sdkutils.ListANFCapacityPools(ctx, accountID)
+	if err != nil {
+		return Tree{}, err
+	}
+
+	tree := Tree{
+		Account:          account,
+		SnapshotPolicies: snapshotPolicies,
+		BackupPolicies:   backupPolicies,
+	}
+
+	for _, pool := range pools {
+		volumes, err := sdkutils.ListANFVolumes(ctx, *pool.ID)
+		if err != nil {
+			return Tree{}, err
+		}
+		tree.Pools = append(tree.Pools, PoolTree{Pool: pool, Volumes: volumes})
+	}
+
+	return tree, nil
+}
+
+// Build turns an account tree into a template
+func Build(tree Tree) (Template, error) {
+
+	if tree.Account.ID == nil || tree.Account.Location == nil {
+		return Template{}, fmt.Errorf("account id and location are required")
+	}
+
+	t := Template{}
+	t.addParameter("location", *tree.Account.Location, "Region of all resources")
+
+	account := &resource{
+		symbol:       "account",
+		resourceType: accountType,
+		nameParam:    t.addParameter("accountName", uri.GetANFAccount(*tree.Account.ID), "Name of the NetApp account"),
+		tags:         tree.Account.Tags,
+	}
+	properties, err := toMap(tree.Account.AccountProperties)
+	if err != nil {
+		return Template{}, err
+	}
+	delete(properties, "activeDirectories")
+	account.properties = properties
+	t.resources = append(t.resources, account)
+
+	// Policies are referenced by volumes through their resource ids
+	policies := map[string]*resource{}
+
+	for i, policy := range tree.SnapshotPolicies {
+		r := &resource{
+			symbol:       fmt.Sprintf("snapshotPolicy%v", i+1),
+			resourceType: snapshotPolicyType,
+			parent:       account,
+			tags:         policy.Tags,
+			dependsOn:    []*resource{account},
+		}
+		r.nameParam = t.addParameter(fmt.Sprintf("%vName", r.symbol), uri.GetANFSnapshotPolicy(*policy.ID), "Name of a snapshot policy")
+		if r.properties, err = toMap(policy.SnapshotPolicyProperties); err != nil {
+			return Template{}, err
+		}
+		policies[strings.ToLower(*policy.ID)] = r
+		t.resources = append(t.resources, r)
+	}
+
+	for i, policy := range tree.BackupPolicies {
+		r := &resource{
+			symbol:       fmt.Sprintf("backupPolicy%v", i+1),
+			resourceType: backupPolicyType,
+			parent:       account,
+			tags:         policy.Tags,
+			dependsOn:    []*resource{account},
+		}
+		r.nameParam = t.addParameter(fmt.Sprintf("%vName", r.symbol), uri.GetANFBackupPolicy(*policy.ID), "Name of a backup policy")
+		if r.properties, err = toMap(policy.BackupPolicyProperties); err != nil {
+			return Template{}, err
+		}
+		delete(r.properties, "volumeBackups")
+		policies[strings.ToLower(*policy.ID)] = r
+		t.resources = append(t.resources, r)
+	}
+
+	subnets := map[string]string{}
+
+	for i, poolTree := range tree.Pools {
+		pool := &resource{
+			symbol:       fmt.Sprintf("pool%v", i+1),
+			resourceType: poolType,
+			parent:       account,
+			tags:         poolTree.Pool.Tags,
+			dependsOn:    []*resource{account},
+		}
+		pool.nameParam = t.addParameter(fmt.Sprintf("%vName", pool.symbol), uri.GetANFCapacityPool(*poolTree.Pool.ID), "Name of a capacity pool")
+		if pool.properties, err = toMap(poolTree.Pool.PoolProperties); err != nil {
+			return Template{}, err
+		}
+		delete(pool.properties, "poolId")
+		t.resources = append(t.resources, pool)
+
+		for j, v := range poolTree.Volumes {
+			volume := &resource{
+				symbol:       fmt.Sprintf("%vVolume%v", pool.symbol, j+1),
+				resourceType: volumeType,
+				parent:       pool,
+				tags:         v.Tags,
+				dependsOn:    []*resource{pool},
+			}
+			volume.nameParam = t.addParameter(fmt.Sprintf("%vName", volume.symbol), uri.GetANFVolume(*v.ID), "Name of a volume")
+			if volume.properties, err = toMap(v.VolumeProperties); err != nil {
+				return Template{}, err
+			}
+
+			// Identifiers assigned by the service or only meaningful at creation time
+			for _, key := range []string{"fileSystemId", "snapshotId", "backupId"} {
+				delete(volume.properties, key)
+			}
+
+			if subnetID, ok := volume.properties["subnetId"].(string); ok {
+				subnetParam, found := subnets[strings.ToLower(subnetID)]
+				if !found {
+					subnetParam = t.addParameter(fmt.Sprintf("subnet%vId", len(subnets)+1), subnetID, "Resource id of a subnet delegated to Microsoft.NetApp/volumes")
+					subnets[strings.ToLower(subnetID)] = subnetParam
+				}
+				volume.properties["subnetId"] = expression{
+					arm:   fmt.Sprintf("parameters('%v')", subnetParam),
+					bicep: subnetParam,
+				}
+			}
+
+			volume.dependsOn = append(volume.dependsOn, linkDataProtection(volume.properties, policies, account)...)
+			t.resources = append(t.resources, volume)
+		}
+	}
+
+	return t, nil
+}
+
+// linkDataProtection replaces policy and vault resource ids of a volume with references to the
+// exported resources, it returns the resources the volume depends on
+func linkDataProtection(properties map[string]interface{}, policies map[string]*resource, account *resource) []*resource {
+
+	dataProtection, ok := properties["dataProtection"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	dependsOn := []*resource{}
+	link := func(settings map[string]interface{}, key string) {
+		policyID, ok := settings[key].(string)
+		if !ok {
+			return
+		}
+		if policy, found := policies[strings.ToLower(policyID)]; found {
+			settings[key] = expression{
+				arm:   policy.resourceIDExpression(),
+				bicep: fmt.Sprintf("%v.id", policy.symbol),
+			}
+			dependsOn = append(dependsOn, policy)
+		}
+	}
+
+	if snapshot, ok := dataProtection["snapshot"].(map[string]interface{}); ok {
+		link(snapshot, "snapshotPolicyId")
+	}
+
+	if backup, ok := dataProtection["backup"].(map[string]interface{}); ok {
+		link(backup, "backupPolicyId")
+		if vaultID, ok := backup["vaultId"].(string); ok {
+			vaultName := uri.GetResourceName(vaultID)
+			backup["vaultId"] = expression{
+				arm:   fmt.Sprintf("resourceId('%v', parameters('%v'), '%v')", vaultType, account.nameParam, vaultName),
+				bicep: fmt.Sprintf("resourceId('%v', %v, '%v')", vaultType, account.nameParam, vaultName),
+			}
+		}
+	}
+
+	return dependsOn
+}
+
+// WriteARM writes the template as an ARM json template
+func (t Template) WriteARM(w io.Writer) error {
+
+	type armParameter struct {
+		Type         string            `json:"type"`
+		DefaultValue string            `json:"defaultValue"`
+		Metadata     map[string]string `json:"metadata"`
+	}
+
+	type armResource struct {
+		Type       string                 `json:"type"`
+		APIVersion string                 `json:"apiVersion"`
+		Name       expression             `json:"name"`
+		Location   expression             `json:"location"`
+		Tags       map[string]*string     `json:"tags,omitempty"`
+		DependsOn  []expression           `json:"dependsOn,omitempty"`
+		Properties map[string]interface{} `json:"properties"`
+	}
+
+	type armTemplate struct {
+		Schema         string                  `json:"$schema"`
+		ContentVersion string                  `json:"contentVersion"`
+		Parameters     map[string]armParameter `json:"parameters"`
+		Resources      []armResource           `json:"resources"`
+	}
+
+	template := armTemplate{
+		Schema:         "https://schema.management.azure.com/schemas/2019-04-01/deploymentTemplate.json#",
+		ContentVersion: "1.0.0.0",
+		Parameters:     map[string]armParameter{},
+	}
+
+	for _, p := range t.parameters {
+		template.Parameters[p.name] = armParameter{
+			Type:         "string",
+			DefaultValue: p.defaultValue,
+			Metadata:     map[string]string{"description": p.description},
+		}
+	}
+
+	for _, r := range t.resources {
+		dependsOn := []expression{}
+		for _, d := range r.dependsOn {
+			dependsOn = append(dependsOn, expression{arm: d.resourceIDExpression()})
+		}
+
+		template.Resources = append(template.Resources, armResource{
+			Type:       r.resourceType,
+			APIVersion: apiVersion,
+			Name:       expression{arm: r.nameExpression()},
+			Location:   expression{arm: "parameters('location')"},
+			Tags:       r.tags,
+			DependsOn:  dependsOn,
+			Properties: r.properties,
+		})
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(template)
+}
+
+func (t *Template) addParameter(name, defaultValue, description string) string {
+	t.parameters = append(t.parameters, parameter{name: name, defaultValue: defaultValue, description: description})
+	return name
+}
+
+// nameParams returns the name parameters of a resource and its parents, outermost first
+func (r *resource) nameParams() []string {
+	if r.parent == nil {
+		return []string{fmt.Sprintf("parameters('%v')", r.nameParam)}
+	}
+	return append(r.parent.nameParams(), fmt.Sprintf("parameters('%v')", r.nameParam))
+}
+
+// nameExpression returns the ARM name of a resource, e.g. format('{0}/{1}', parameters('accountName'), parameters('pool1Name'))
+func (r *resource) nameExpression() string {
+	params := r.nameParams()
+	if len(params) == 1 {
+		return params[0]
+	}
+
+	placeholders := []string{}
+	for i := range params {
+		placeholders = append(placeholders, fmt.Sprintf("{%v}", i))
+	}
+	return fmt.Sprintf("format('%v', %v)", strings.Join(placeholders, "/"), strings.Join(params, ", "))
+}
+
+// resourceIDExpression returns the ARM expression of the resource id of a resource
+func (r *resource) resourceIDExpression() string {
+	return fmt.Sprintf("resourceId('%v', %v)", r.resourceType, strings.Join(r.nameParams(), ", "))
+}
+
+// toMap turns SDK properties into a generic map, read-only properties are left out by the SDK
+func toMap(properties interface{}) (map[string]interface{}, error) {
+
+	propertiesJSON, err := json.Marshal(properties)
+	if err != nil {
+		return nil, fmt.Errorf("cannot serialize properties: %v", err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(propertiesJSON))
+	decoder.UseNumber()
+
+	result := map[string]interface{}{}
+	err = decoder.Decode(&result)
+	if err != nil {
+		return nil, fmt.Errorf("cannot deserialize properties: %v", err)
+	}
+
+	return result, nil
+}
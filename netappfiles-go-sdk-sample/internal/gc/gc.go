@@ -0,0 +1,225 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// This package finds abandoned Azure NetApp Files resources, e.g.
+// accounts left behind by test runs, by tags, by age, based on the
+// creation timestamp tag stamped by the sample, and by heuristics
+// such as capacity pools without volumes or volumes without any
+// throughput. Resources carrying an exclusion tag, or containing one
+// that does, are never collected.
+
+package gc
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/sdkutils"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/state"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/tagging"
+	"github.com/Azure/go-autorest/autorest/to"
+)
+
+const (
+	// CreatedAtTagName is the tag holding the creation time of a resource, in RFC 3339 format
+	CreatedAtTagName string = "createdAt"
+	// DefaultExclusionTagName is the tag protecting a resource, and its parents, from garbage collection
+	DefaultExclusionTagName string = "DoNotDelete"
+)
+
+var (
+	// LifecycleTagNames are the tags set by the sample that resource-group, gc and reap rely on, tag replacements
+	// and drift reconciliation keep them and they are not expected in a deployment spec
+	LifecycleTagNames = []string{sdkutils.OwnershipTagName, CreatedAtTagName, ExpiresAtTagName}
+)
+
+// Criteria select the resources to collect, filters must all match and when heuristics are enabled
+// a resource must also satisfy one of them
+type Criteria struct {
+	TagFilter     map[string]string
+	OlderThan     time.Duration
+	Types         []string
+	EmptyAccounts bool
+	EmptyPools    bool
+	UnusedVolumes bool
+	ExclusionTags []string
+}
+
+// Candidate is a resource to collect and the reasons it was selected
+type Candidate struct {
+	Resource tagging.Resource
+	Reasons  []string
+}
+
+// Unchecked is a resource the heuristics could not be evaluated for, it is never collected
+type Unchecked struct {
+	ResourceID string
+	Err        error
+}
+
+// Report lists the resources to collect, the ones protected by an exclusion tag, the ones whose age is unknown
+// and the ones the heuristics could not be evaluated for
+type Report struct {
+	Candidates []Candidate
+	Protected  []Candidate
+	UnknownAge []string
+	Unchecked  []Unchecked
+}
+
+// CreatedAtTag returns the value of the creation timestamp tag for the current time
+func CreatedAtTag() *string {
+	return to.StringPtr(time.Now().UTC().Format(time.RFC3339))
+}
+
+// CreatedAt reads the creation timestamp tag of a resource
+func CreatedAt(tags map[string]*string) (time.Time, bool) {
+	value, found := tags[CreatedAtTagName]
+	if !found || value == nil {
+		return time.Time{}, false
+	}
+	createdAt, err := time.Parse(time.RFC3339, *value)
+	return createdAt, err == nil
+}
+
+// ParseAge parses a duration that may also be expressed in days, e.g. 7d or 36h
+func ParseAge(value string) (time.Duration, error) {
+	if strings.HasSuffix(value, "d") {
+		days, err := strconv.Atoi(strings.TrimSuffix(value, "d"))
+		if err != nil {
+			return 0, fmt.Errorf("invalid age %v: %v", value, err)
+		}
+		return time.Duration(days) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(value)
+}
+
+// Find selects the resources of the inventory to collect, parents come before their children in the inventory
+// and a resource already covered by a selected parent is not listed again. A resource whose heuristic cannot be
+// evaluated, e.g. when its metrics are not available, is skipped and reported.
+func Find(ctx context.Context, inventory []tagging.Resource, criteria Criteria) (Report, error) {
+
+	report := Report{}
+	now := time.Now().UTC()
+	selector := tagging.Selector{TagFilter: criteria.TagFilter, Types: criteria.Types}
+
+	for _, resource := range inventory {
+		if covered(report, resource.ResourceID) || !selector.Matches(resource) {
+			continue
+		}
+
+		reasons := []string{}
+		if len(criteria.TagFilter) > 0 {
+			reasons = append(reasons, "tags match")
+		}
+
+		if criteria.OlderThan > 0 {
+			createdAt, found := CreatedAt(resource.Tags)
+			if !found {
+				report.UnknownAge = append(report.UnknownAge, resource.ResourceID)
+				continue
+			}
+			age := now.Sub(createdAt)
+			if age < criteria.OlderThan {
+				continue
+			}
+			reasons = append(reasons, fmt.Sprintf("created %v ago", age.Round(time.Hour)))
+		}
+
+		if criteria.EmptyAccounts || criteria.EmptyPools || criteria.UnusedVolumes {
+			reason, err := heuristic(ctx, inventory, resource, criteria)
+			if err != nil {
+				report.Unchecked = append(report.Unchecked, Unchecked{ResourceID: resource.ResourceID, Err: err})
+				continue
+			}
+			if reason == "" {
+				continue
+			}
+			reasons = append(reasons, reason)
+		}
+
+		candidate := Candidate{Resource: resource, Reasons: reasons}
+		if tag, found := exclusionTag(inventory, resource.ResourceID, criteria.ExclusionTags); found {
+			candidate.Reasons = append(candidate.Reasons, fmt.Sprintf("protected by tag %v", tag))
+			report.Protected = append(report.Protected, candidate)
+			continue
+		}
+		report.Candidates = append(report.Candidates, candidate)
+	}
+
+	return report, nil
+}
+
+// heuristic checks if a resource looks unused, it returns why or an empty string
+func heuristic(ctx context.Context, inventory []tagging.Resource, resource tagging.Resource, criteria Criteria) (string, error) {
+
+	switch resource.Type {
+	case state.AccountType:
+		if criteria.EmptyAccounts && len(children(inventory, resource.ResourceID, state.CapacityPoolType)) == 0 {
+			return "account has no capacity pools", nil
+		}
+	case state.CapacityPoolType:
+		if criteria.EmptyPools && len(children(inventory, resource.ResourceID, state.VolumeType)) == 0 {
+			return "capacity pool has no volumes", nil
+		}
+	case state.VolumeType:
+		if criteria.UnusedVolumes {
+			throughput, err := sdkutils.GetANFVolumePeakThroughputMibps(ctx, resource.ResourceID)
+			if err != nil {
+				return "", err
+			}
+			if throughput == 0 {
+				return "volume had no throughput in the last 24 hours", nil
+			}
+		}
+	}
+
+	return "", nil
+}
+
+// exclusionTag checks if the resource, one of its parents or one of its children carries an exclusion tag,
+// a protected child prevents its parents from being deleted recursively
+func exclusionTag(inventory []tagging.Resource, resourceID string, exclusionTags []string) (string, bool) {
+
+	id := strings.ToLower(resourceID)
+	for _, resource := range inventory {
+		other := strings.ToLower(resource.ResourceID)
+		if other != id && !strings.HasPrefix(id, other+"/") && !strings.HasPrefix(other, id+"/") {
+			continue
+		}
+		for _, tag := range exclusionTags {
+			if _, found := resource.Tags[tag]; found {
+				return tag, true
+			}
+		}
+	}
+
+	return "", false
+}
+
+// children returns the direct or indirect children of a resource of the given type
+func children(inventory []tagging.Resource, resourceID, resourceType string) []tagging.Resource {
+	result := []tagging.Resource{}
+	prefix := strings.ToLower(resourceID) + "/"
+	for _, resource := range inventory {
+		if resource.Type == resourceType && strings.HasPrefix(strings.ToLower(resource.ResourceID), prefix) {
+			result = append(result, resource)
+		}
+	}
+	return result
+}
+
+// covered checks if a parent of the resource is already a candidate
+func covered(report Report, resourceID string) bool {
+	id := strings.ToLower(resourceID)
+	for _, candidate := range report.Candidates {
+		if strings.HasPrefix(id, strings.ToLower(candidate.Resource.ResourceID)+"/") {
+			return true
+		}
+	}
+	return false
+}
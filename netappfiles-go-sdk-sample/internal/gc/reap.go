@@ -0,0 +1,158 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package gc
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/logging"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/operations"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/state"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/tagging"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/teardown"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/uri"
+	"github.com/Azure/go-autorest/autorest/to"
+)
+
+const (
+	// ExpiresAtTagName is the tag holding the time after which a resource tree can be reaped, in RFC 3339 format
+	ExpiresAtTagName string = "expiresAt"
+
+	// TimeToLiveEnvironmentVariable overrides the time to live of the resources created by the sample and every command, e.g. 24h
+	TimeToLiveEnvironmentVariable string = "ANF_RESOURCE_TTL"
+)
+
+// ReapFailure is a resource that could not be deleted, the rest of its tree is kept
+type ReapFailure struct {
+	ResourceID string `json:"resourceId"`
+	Error      string `json:"error"`
+}
+
+// ReapReport lists what a reaper run removed and what failed
+type ReapReport struct {
+	StartedAt time.Time     `json:"startedAt"`
+	Expired   []string      `json:"expired"`
+	Protected []string      `json:"protected"`
+	Removed   []string      `json:"removed"`
+	Failed    []ReapFailure `json:"failed"`
+}
+
+// TimeToLive returns the time to live from the ANF_RESOURCE_TTL environment variable, defaultTimeToLive when it is not set,
+// 0 means resources do not expire
+func TimeToLive(defaultTimeToLive time.Duration) (time.Duration, error) {
+
+	value := os.Getenv(TimeToLiveEnvironmentVariable)
+	if value == "" {
+		return defaultTimeToLive, nil
+	}
+
+	timeToLive, err := time.ParseDuration(value)
+	if err != nil || timeToLive < 0 {
+		return 0, fmt.Errorf("invalid %v %q, expected a positive duration such as 24h", TimeToLiveEnvironmentVariable, value)
+	}
+
+	return timeToLive, nil
+}
+
+// ExpiresAtTag returns the value of the expiry tag for a resource living for the given duration
+func ExpiresAtTag(timeToLive time.Duration) *string {
+	return to.StringPtr(time.Now().UTC().Add(timeToLive).Format(time.RFC3339))
+}
+
+// ExpiresAt reads the expiry tag of a resource
+func ExpiresAt(tags map[string]*string) (time.Time, bool) {
+	value, found := tags[ExpiresAtTagName]
+	if !found || value == nil {
+		return time.Time{}, false
+	}
+	expiresAt, err := time.Parse(time.RFC3339, *value)
+	return expiresAt, err == nil
+}
+
+// FindExpired selects the resources of the inventory whose expiry tag is in the past, a resource already
+// covered by an expired parent is not listed again
+func FindExpired(inventory []tagging.Resource, now time.Time, exclusionTags []string) Report {
+
+	report := Report{}
+	for _, resource := range inventory {
+		if covered(report, resource.ResourceID) {
+			continue
+		}
+
+		expiresAt, found := ExpiresAt(resource.Tags)
+		if !found || expiresAt.After(now) {
+			continue
+		}
+
+		candidate := Candidate{Resource: resource, Reasons: []string{fmt.Sprintf("expired at %v", expiresAt.Format(time.RFC3339))}}
+		if tag, found := exclusionTag(inventory, resource.ResourceID, exclusionTags); found {
+			candidate.Reasons = append(candidate.Reasons, fmt.Sprintf("protected by tag %v", tag))
+			report.Protected = append(report.Protected, candidate)
+			continue
+		}
+		report.Candidates = append(report.Candidates, candidate)
+	}
+
+	return report
+}
+
+// Reap tears down every expired resource tree of a location, or of the subscription when location is empty,
+// children first. Without confirm expired resources are only reported. Deletions are tracked in store unless it is nil.
+// The journal at statePath is read again for each deleted resource so entries recorded meanwhile by other runs are kept.
+func Reap(ctx context.Context, statePath string, store *operations.Store, location string, exclusionTags []string, confirm bool) (ReapReport, error) {
+
+	report := ReapReport{StartedAt: time.Now().UTC(), Expired: []string{}, Protected: []string{}, Removed: []string{}, Failed: []ReapFailure{}}
+
+	inventory, err := tagging.Inventory(ctx, location)
+	if err != nil {
+		return report, err
+	}
+
+	expired := FindExpired(inventory, report.StartedAt, exclusionTags)
+	for _, candidate := range expired.Protected {
+		report.Protected = append(report.Protected, candidate.Resource.ResourceID)
+	}
+	for _, candidate := range expired.Candidates {
+		report.Expired = append(report.Expired, candidate.Resource.ResourceID)
+	}
+
+	if !confirm {
+		return report, nil
+	}
+
+	for _, candidate := range expired.Candidates {
+		resourceIDs, err := teardown.Tree(ctx, candidate.Resource.ResourceID)
+		if err != nil {
+			report.Failed = append(report.Failed, ReapFailure{ResourceID: candidate.Resource.ResourceID, Error: err.Error()})
+			continue
+		}
+
+		for _, resourceID := range resourceIDs {
+			logging.Info(fmt.Sprintf("Deleting %v...", uri.GetResourceName(resourceID)), logging.Operation("reap"), logging.ResourceID(resourceID))
+			err = teardown.DeleteResource(ctx, resourceID, store)
+			if err != nil {
+				// Parents cannot be deleted while one of their children remains
+				report.Failed = append(report.Failed, ReapFailure{ResourceID: resourceID, Error: err.Error()})
+				break
+			}
+			report.Removed = append(report.Removed, resourceID)
+
+			journal, err := state.Load(statePath)
+			if err != nil {
+				return report, err
+			}
+			err = journal.Remove(resourceID)
+			if err != nil {
+				return report, err
+			}
+		}
+	}
+
+	return report, nil
+}
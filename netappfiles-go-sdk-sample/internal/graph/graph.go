@@ -0,0 +1,187 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// This package runs provisioning steps as a dependency graph, e.g. an
+// account, its capacity pools and their volumes. Steps that do not
+// depend on each other, such as sibling volumes, run concurrently up
+// to a configurable limit while a step only starts once all of its
+// dependencies succeeded. When a step fails its dependents are
+// cancelled and the independent branches carry on. Steps can be added in
+// any order, the graph is checked for unknown dependencies and cycles
+// before anything runs.
+
+package graph
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Step is the work done by a node of the graph, e.g. creating a volume and waiting for it
+type Step func(ctx context.Context) error
+
+// Result is the outcome of a node, Cancelled is set when the node never ran because a dependency failed
+type Result struct {
+	Name      string
+	Err       error
+	Cancelled bool
+	Duration  time.Duration
+}
+
+// Graph is a set of steps and their dependencies
+type Graph struct {
+	nodes []*node
+	index map[string]*node
+}
+
+type node struct {
+	name         string
+	step         Step
+	dependencies []string
+	dependsOn    []*node
+	done         chan struct{}
+	result       Result
+}
+
+// New creates an empty graph
+func New() *Graph {
+	return &Graph{index: map[string]*node{}}
+}
+
+// Add adds a named step to the graph, its dependencies can be added later on
+func (g *Graph) Add(name string, step Step, dependsOn ...string) error {
+
+	if _, found := g.index[name]; found {
+		return fmt.Errorf("step %v is already part of the graph", name)
+	}
+
+	n := &node{name: name, step: step, dependencies: dependsOn}
+	g.nodes = append(g.nodes, n)
+	g.index[name] = n
+
+	return nil
+}
+
+// Validate checks that every dependency is part of the graph and that no step depends on itself,
+// directly or through other steps
+func (g *Graph) Validate() error {
+
+	for _, n := range g.nodes {
+		n.dependsOn = nil
+		for _, dependency := range n.dependencies {
+			parent, found := g.index[dependency]
+			if !found {
+				return fmt.Errorf("step %v depends on %v which is not part of the graph", n.name, dependency)
+			}
+			n.dependsOn = append(n.dependsOn, parent)
+		}
+	}
+
+	// Depth-first search, a step found again while its own dependencies are visited closes a cycle
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	states := map[*node]int{}
+	path := []string{}
+	var visit func(n *node) error
+	visit = func(n *node) error {
+		switch states[n] {
+		case visiting:
+			return fmt.Errorf("dependency cycle: %v -> %v", strings.Join(path, " -> "), n.name)
+		case visited:
+			return nil
+		}
+
+		states[n] = visiting
+		path = append(path, n.name)
+		for _, parent := range n.dependsOn {
+			if err := visit(parent); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		states[n] = visited
+
+		return nil
+	}
+
+	for _, n := range g.nodes {
+		if err := visit(n); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Run executes the graph with at most concurrency steps running at the same time, no limit is applied
+// when concurrency is 0 or less. Nothing runs when the graph is not valid. Results are returned in the
+// order the steps were added.
+func (g *Graph) Run(ctx context.Context, concurrency int) ([]Result, error) {
+
+	if err := g.Validate(); err != nil {
+		return nil, err
+	}
+
+	if concurrency <= 0 {
+		concurrency = len(g.nodes)
+	}
+	slots := make(chan struct{}, concurrency)
+
+	var wg sync.WaitGroup
+	for _, n := range g.nodes {
+		n.done = make(chan struct{})
+		n.result = Result{Name: n.name}
+	}
+
+	for _, n := range g.nodes {
+		wg.Add(1)
+		go func(n *node) {
+			defer wg.Done()
+			defer close(n.done)
+
+			for _, parent := range n.dependsOn {
+				<-parent.done
+				if parent.result.Err != nil {
+					n.result.Cancelled = true
+					n.result.Err = fmt.Errorf("cancelled, %v did not complete", parent.name)
+					return
+				}
+			}
+
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				n.result.Cancelled = true
+				n.result.Err = ctx.Err()
+				return
+			}
+			defer func() { <-slots }()
+
+			if ctx.Err() != nil {
+				n.result.Cancelled = true
+				n.result.Err = ctx.Err()
+				return
+			}
+
+			start := time.Now()
+			n.result.Err = n.step(ctx)
+			n.result.Duration = time.Since(start)
+		}(n)
+	}
+	wg.Wait()
+
+	results := make([]Result, 0, len(g.nodes))
+	for _, n := range g.nodes {
+		results = append(results, n.result)
+	}
+
+	return results, nil
+}
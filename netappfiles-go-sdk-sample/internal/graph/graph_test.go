@@ -0,0 +1,205 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package graph
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// recorder keeps the order in which steps started and finished
+type recorder struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (r *recorder) step(name string, err error) Step {
+	return func(ctx context.Context) error {
+		r.record("start " + name)
+		r.record("end " + name)
+		return err
+	}
+}
+
+func (r *recorder) record(event string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+func (r *recorder) index(event string) int {
+	for i, e := range r.events {
+		if e == event {
+			return i
+		}
+	}
+	return -1
+}
+
+func TestRunOrdering(t *testing.T) {
+
+	r := &recorder{}
+	g := New()
+
+	// Steps are added before their dependencies on purpose
+	mustAdd(t, g, "volume1", r.step("volume1", nil), "pool")
+	mustAdd(t, g, "volume2", r.step("volume2", nil), "pool")
+	mustAdd(t, g, "snapshot", r.step("snapshot", nil), "volume1")
+	mustAdd(t, g, "pool", r.step("pool", nil), "account")
+	mustAdd(t, g, "account", r.step("account", nil))
+
+	results, err := g.Run(context.Background(), 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for i, name := range []string{"volume1", "volume2", "snapshot", "pool", "account"} {
+		if results[i].Name != name || results[i].Err != nil || results[i].Cancelled {
+			t.Errorf("result %v = %+v, want %v to succeed", i, results[i], name)
+		}
+	}
+
+	for _, dependency := range [][2]string{
+		{"account", "pool"},
+		{"pool", "volume1"},
+		{"pool", "volume2"},
+		{"volume1", "snapshot"},
+	} {
+		if r.index("end "+dependency[0]) > r.index("start "+dependency[1]) {
+			t.Errorf("%v started before %v ended, events: %v", dependency[1], dependency[0], r.events)
+		}
+	}
+}
+
+func TestRunFailurePropagation(t *testing.T) {
+
+	r := &recorder{}
+	g := New()
+	failure := errors.New("quota exceeded")
+
+	mustAdd(t, g, "account", r.step("account", nil))
+	mustAdd(t, g, "pool", r.step("pool", failure), "account")
+	mustAdd(t, g, "volume", r.step("volume", nil), "pool")
+	mustAdd(t, g, "snapshot", r.step("snapshot", nil), "volume")
+	mustAdd(t, g, "policy", r.step("policy", nil), "account")
+
+	results, err := g.Run(context.Background(), 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	byName := map[string]Result{}
+	for _, result := range results {
+		byName[result.Name] = result
+	}
+
+	if !errors.Is(byName["pool"].Err, failure) || byName["pool"].Cancelled {
+		t.Errorf("pool = %+v, want the step error", byName["pool"])
+	}
+	for _, name := range []string{"volume", "snapshot"} {
+		if !byName[name].Cancelled || byName[name].Err == nil {
+			t.Errorf("%v = %+v, want it cancelled", name, byName[name])
+		}
+		if r.index("start "+name) != -1 {
+			t.Errorf("%v ran although a dependency failed", name)
+		}
+	}
+	for _, name := range []string{"account", "policy"} {
+		if byName[name].Err != nil || byName[name].Cancelled {
+			t.Errorf("%v = %+v, want it to succeed", name, byName[name])
+		}
+	}
+}
+
+func TestRunCancelledContext(t *testing.T) {
+
+	r := &recorder{}
+	g := New()
+	mustAdd(t, g, "account", r.step("account", nil))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	results, err := g.Run(ctx, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !results[0].Cancelled || r.index("start account") != -1 {
+		t.Errorf("account = %+v, want it cancelled without running", results[0])
+	}
+}
+
+func TestValidate(t *testing.T) {
+
+	tests := []struct {
+		name    string
+		steps   map[string][]string
+		wantErr string
+	}{
+		{
+			name:  "valid graph",
+			steps: map[string][]string{"account": nil, "pool": {"account"}, "volume": {"pool"}},
+		},
+		{
+			name:    "unknown dependency",
+			steps:   map[string][]string{"pool": {"account"}},
+			wantErr: "not part of the graph",
+		},
+		{
+			name:    "self dependency",
+			steps:   map[string][]string{"account": {"account"}},
+			wantErr: "dependency cycle",
+		},
+		{
+			name:    "cycle through other steps",
+			steps:   map[string][]string{"account": {"volume"}, "pool": {"account"}, "volume": {"pool"}},
+			wantErr: "dependency cycle",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := &recorder{}
+			g := New()
+			for name, dependsOn := range test.steps {
+				mustAdd(t, g, name, r.step(name, nil), dependsOn...)
+			}
+
+			results, err := g.Run(context.Background(), 0)
+			if test.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+				t.Fatalf("got error %v, want an error containing %q", err, test.wantErr)
+			}
+			if results != nil || len(r.events) > 0 {
+				t.Errorf("steps ran although the graph is not valid: %v", r.events)
+			}
+		})
+	}
+}
+
+func TestAddDuplicate(t *testing.T) {
+
+	g := New()
+	mustAdd(t, g, "account", func(ctx context.Context) error { return nil })
+	if err := g.Add("account", func(ctx context.Context) error { return nil }); err == nil {
+		t.Errorf("expected an error when adding a step twice")
+	}
+}
+
+func mustAdd(t *testing.T, g *Graph, name string, step Step, dependsOn ...string) {
+	t.Helper()
+	if err := g.Add(name, step, dependsOn...); err != nil {
+		t.Fatalf("cannot add step %v: %v", name, err)
+	}
+}
@@ -12,12 +12,11 @@ package iam
 
 import (
 	"encoding/json"
-	"fmt"
 	"io/ioutil"
 	"os"
 
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/logging"
 	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/models"
-	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/utils"
 
 	"github.com/Azure/go-autorest/autorest"
 	"github.com/Azure/go-autorest/autorest/azure/auth"
@@ -31,7 +30,7 @@ func GetAuthorizer() (autorest.Authorizer, string, error) {
 
 	authorizer, err := auth.NewAuthorizerFromFile(*info.ResourceManagerEndpointURL)
 	if err != nil {
-		utils.ConsoleOutput(fmt.Sprintf("%v", err))
+		logging.Error("cannot create authorizer from the authentication file", logging.String("path", os.Getenv("AZURE_AUTH_LOCATION")), logging.Err(err))
 		return nil, "", err
 	}
 
@@ -42,7 +41,7 @@ func GetAuthorizer() (autorest.Authorizer, string, error) {
 func readAuthJSON(path string) (*models.AzureAuthInfo, error) {
 	infoJSON, err := ioutil.ReadFile(path)
 	if err != nil {
-		utils.ConsoleOutput(fmt.Sprintf("failed to read file: %v", err))
+		logging.Error("cannot read the authentication file", logging.String("path", path), logging.Err(err))
 		return &models.AzureAuthInfo{}, err
 	}
 	var authInfo models.AzureAuthInfo
@@ -0,0 +1,286 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// This package brings resources created outside of this sample,
+// e.g. from the portal or by other teams, under its management. An
+// account, capacity pool or volume is read with its children and
+// recorded in the state journal and in a deployment spec, so drift
+// detection and the spec based commands (estimate, preflight) cover
+// them. Imported resources are only deleted by teardown once adopted.
+
+package importer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/drift"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/models"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/sdkutils"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/state"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/uri"
+	"github.com/Azure/azure-sdk-for-go/services/netapp/mgmt/2021-04-01/netapp"
+	"github.com/Azure/go-autorest/autorest/to"
+)
+
+// Result lists the resources imported, in parent first order, and what could not be represented in the spec
+type Result struct {
+	ResourceIDs []string
+	Warnings    []string
+}
+
+// Import reads an account, capacity pool or volume and its children, including the snapshot and backup policies
+// of an account, records them in the journal and merges them into the spec. Parents of a pool or volume are added
+// to the spec but not to the journal, so a teardown never deletes more than what was imported, and only adopted
+// resources are deleted at all.
+func Import(ctx context.Context, resourceID string, journal *state.Journal, spec *models.DeploymentSpec, adopt bool) (Result, error) {
+
+	if !uri.IsANFAccount(resourceID) && !uri.IsANFCapacityPool(resourceID) && !uri.IsANFVolume(resourceID) {
+		return Result{}, fmt.Errorf("%v is not the resource id of an account, capacity pool or volume", resourceID)
+	}
+
+	resourceGroupName := uri.GetResourceGroup(resourceID)
+	if spec.ResourceGroup == "" {
+		spec.ResourceGroup = resourceGroupName
+	}
+	if !strings.EqualFold(spec.ResourceGroup, resourceGroupName) {
+		return Result{}, fmt.Errorf("the deployment spec describes resource group %v, %v belongs to %v", spec.ResourceGroup, resourceID, resourceGroupName)
+	}
+
+	accountID := fmt.Sprintf("/subscriptions/%v/resourceGroups/%v/providers/Microsoft.NetApp/netAppAccounts/%v", uri.GetSubscription(resourceID), resourceGroupName, uri.GetANFAccount(resourceID))
+	account, err := sdkutils.GetANFAccountByID(ctx, accountID)
+	if err != nil {
+		return Result{}, err
+	}
+
+	result := Result{}
+	applied := map[string]drift.Properties{}
+	accountSpec := mergeAccount(spec, account)
+
+	backupPolicies, err := sdkutils.ListANFBackupPolicies(ctx, resourceGroupName, uri.GetANFAccount(accountID))
+	if err != nil {
+		return Result{}, err
+	}
+
+	var pools []netapp.CapacityPool
+	if uri.IsANFAccount(resourceID) {
+		result.ResourceIDs = append(result.ResourceIDs, *account.ID)
+		applied[*account.ID] = drift.AccountProperties(account)
+
+		// Policies are children of the account, it cannot be deleted while they exist
+		snapshotPolicies, err := sdkutils.ListANFSnapshotPolicies(ctx, resourceGroupName, uri.GetANFAccount(accountID))
+		if err != nil {
+			return Result{}, err
+		}
+		for _, policy := range snapshotPolicies {
+			result.ResourceIDs = append(result.ResourceIDs, *policy.ID)
+		}
+		for _, policy := range backupPolicies {
+			result.ResourceIDs = append(result.ResourceIDs, *policy.ID)
+		}
+
+		pools, err = sdkutils.ListANFCapacityPools(ctx, accountID)
+	} else {
+		poolID := resourceID
+		if uri.IsANFVolume(resourceID) {
+			poolID = uri.GetANFCapacityPoolID(resourceID)
+		}
+		var pool netapp.CapacityPool
+		pool, err = sdkutils.GetANFCapacityPoolByID(ctx, poolID)
+		pools = []netapp.CapacityPool{pool}
+	}
+	if err != nil {
+		return Result{}, err
+	}
+
+	for _, pool := range pools {
+		poolSpec, err := mergePool(accountSpec, pool)
+		if err != nil {
+			return Result{}, err
+		}
+
+		var volumes []netapp.Volume
+		if uri.IsANFVolume(resourceID) {
+			var volume netapp.Volume
+			volume, err = sdkutils.GetANFVolumeByID(ctx, resourceID)
+			volumes = []netapp.Volume{volume}
+		} else {
+			result.ResourceIDs = append(result.ResourceIDs, *pool.ID)
+			applied[*pool.ID] = drift.PoolProperties(pool)
+			volumes, err = sdkutils.ListANFVolumes(ctx, *pool.ID)
+		}
+		if err != nil {
+			return Result{}, err
+		}
+
+		for _, volume := range volumes {
+			warnings, err := mergeVolume(spec, poolSpec, volume, backupPolicies)
+			if err != nil {
+				return Result{}, err
+			}
+			result.ResourceIDs = append(result.ResourceIDs, *volume.ID)
+			applied[*volume.ID] = drift.VolumeProperties(volume)
+			result.Warnings = append(result.Warnings, warnings...)
+		}
+	}
+
+	// Live properties are the drift detection baseline, policies have none
+	for _, id := range result.ResourceIDs {
+		err = journal.RecordImported(id, state.TypeFromID(id), adopt)
+		if err != nil {
+			return Result{}, err
+		}
+		if properties, found := applied[id]; found {
+			err = journal.SetApplied(id, properties)
+			if err != nil {
+				return Result{}, err
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// mergeAccount adds an account to the spec, or updates the one with the same name
+func mergeAccount(spec *models.DeploymentSpec, account netapp.Account) *models.AccountSpec {
+
+	location := to.String(account.Location)
+	if spec.Location == "" {
+		spec.Location = location
+	}
+
+	name := uri.GetANFAccount(*account.ID)
+	var accountSpec *models.AccountSpec
+	for i := range spec.Accounts {
+		if strings.EqualFold(spec.Accounts[i].Name, name) {
+			accountSpec = &spec.Accounts[i]
+		}
+	}
+	if accountSpec == nil {
+		spec.Accounts = append(spec.Accounts, models.AccountSpec{Name: name, Pools: []models.PoolSpec{}})
+		accountSpec = &spec.Accounts[len(spec.Accounts)-1]
+	}
+
+	accountSpec.Location = ""
+	if !strings.EqualFold(spec.Location, location) {
+		accountSpec.Location = location
+	}
+	accountSpec.Tags = toTags(account.Tags)
+
+	return accountSpec
+}
+
+// mergePool adds a capacity pool to an account spec, or updates the one with the same name
+func mergePool(accountSpec *models.AccountSpec, pool netapp.CapacityPool) (*models.PoolSpec, error) {
+
+	if pool.PoolProperties == nil || pool.Size == nil {
+		return nil, fmt.Errorf("capacity pool %v has no properties", to.String(pool.ID))
+	}
+
+	name := uri.GetANFCapacityPool(*pool.ID)
+	var poolSpec *models.PoolSpec
+	for i := range accountSpec.Pools {
+		if strings.EqualFold(accountSpec.Pools[i].Name, name) {
+			poolSpec = &accountSpec.Pools[i]
+		}
+	}
+	if poolSpec == nil {
+		accountSpec.Pools = append(accountSpec.Pools, models.PoolSpec{Name: name, Volumes: []models.VolumeSpec{}})
+		poolSpec = &accountSpec.Pools[len(accountSpec.Pools)-1]
+	}
+
+	poolSpec.ServiceLevel = string(pool.ServiceLevel)
+	poolSpec.SizeTiB = *pool.Size / sdkutils.TiBInBytes
+	poolSpec.QosType = string(pool.QosType)
+	poolSpec.Tags = toTags(pool.Tags)
+
+	return poolSpec, nil
+}
+
+// mergeVolume adds a volume to a pool spec, or replaces the one with the same name, and returns the
+// settings that cannot be represented in the spec
+func mergeVolume(spec *models.DeploymentSpec, poolSpec *models.PoolSpec, volume netapp.Volume, backupPolicies []netapp.BackupPolicy) ([]string, error) {
+
+	if volume.VolumeProperties == nil || volume.UsageThreshold == nil {
+		return nil, fmt.Errorf("volume %v has no properties", to.String(volume.ID))
+	}
+
+	warnings := []string{}
+	name := uri.GetANFVolume(*volume.ID)
+
+	volumeSpec := models.VolumeSpec{
+		Name:          name,
+		ProtocolTypes: []string{},
+		QuotaGiB:      *volume.UsageThreshold / sdkutils.GiBInBytes,
+		Tags:          toTags(volume.Tags),
+	}
+	if creationToken := to.String(volume.CreationToken); !strings.EqualFold(creationToken, name) {
+		volumeSpec.CreationToken = creationToken
+	}
+	if volume.ProtocolTypes != nil {
+		volumeSpec.ProtocolTypes = *volume.ProtocolTypes
+	}
+
+	subnetID := to.String(volume.SubnetID)
+	if spec.SubnetID == "" {
+		spec.SubnetID = subnetID
+	}
+	if !strings.EqualFold(spec.SubnetID, subnetID) {
+		warnings = append(warnings, fmt.Sprintf("volume %v is in subnet %v while the spec uses %v", name, subnetID, spec.SubnetID))
+	}
+
+	if dataProtection := volume.DataProtection; dataProtection != nil {
+		if backup := dataProtection.Backup; backup != nil && backup.BackupEnabled != nil && *backup.BackupEnabled {
+			policy := findBackupPolicy(backupPolicies, to.String(backup.BackupPolicyID))
+			if policy == nil || policy.BackupPolicyProperties == nil {
+				warnings = append(warnings, fmt.Sprintf("volume %v uses backup policy %v which was not found", name, to.String(backup.BackupPolicyID)))
+			} else {
+				volumeSpec.Backup = &models.BackupSpec{
+					DailyBackupsToKeep:   to.Int32(policy.DailyBackupsToKeep),
+					WeeklyBackupsToKeep:  to.Int32(policy.WeeklyBackupsToKeep),
+					MonthlyBackupsToKeep: to.Int32(policy.MonthlyBackupsToKeep),
+				}
+			}
+		}
+		if dataProtection.Replication != nil {
+			warnings = append(warnings, fmt.Sprintf("volume %v has cross region replication settings, they are not imported", name))
+		}
+		if dataProtection.Snapshot != nil && dataProtection.Snapshot.SnapshotPolicyID != nil {
+			warnings = append(warnings, fmt.Sprintf("volume %v uses snapshot policy %v, snapshot policies are not part of the spec", name, *dataProtection.Snapshot.SnapshotPolicyID))
+		}
+	}
+
+	for i := range poolSpec.Volumes {
+		if strings.EqualFold(poolSpec.Volumes[i].Name, name) {
+			poolSpec.Volumes[i] = volumeSpec
+			return warnings, nil
+		}
+	}
+	poolSpec.Volumes = append(poolSpec.Volumes, volumeSpec)
+
+	return warnings, nil
+}
+
+func findBackupPolicy(policies []netapp.BackupPolicy, policyID string) *netapp.BackupPolicy {
+	for i, policy := range policies {
+		if policy.ID != nil && strings.EqualFold(*policy.ID, policyID) {
+			return &policies[i]
+		}
+	}
+	return nil
+}
+
+// toTags converts SDK tags to spec tags
+func toTags(tags map[string]*string) map[string]string {
+	if len(tags) == 0 {
+		return nil
+	}
+	result := map[string]string{}
+	for key, value := range tags {
+		result[key] = to.String(value)
+	}
+	return result
+}
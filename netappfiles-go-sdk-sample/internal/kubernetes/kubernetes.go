@@ -0,0 +1,204 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// This package generates Kubernetes PersistentVolume and
+// PersistentVolumeClaim manifests for existing NFS volumes so they
+// can be mounted into AKS (or any other) clusters through static
+// provisioning, each claim is pre-bound to its persistent volume.
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/mount"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/sdkutils"
+	"github.com/Azure/azure-sdk-for-go/services/netapp/mgmt/2021-04-01/netapp"
+)
+
+const (
+	nfsv3  string = "NFSv3"
+	nfsv41 string = "NFSv4.1"
+)
+
+var (
+	invalidInObjectName = regexp.MustCompile(`[^a-z0-9\-]+`)
+
+	manifestTemplate = template.Must(template.New("manifest").Parse(`apiVersion: v1
+kind: PersistentVolume
+metadata:
+  name: {{ .PersistentVolumeName }}
+  labels:
+    app.kubernetes.io/managed-by: anf-go-sdk-sample
+  annotations:
+    netapp.azure.com/volume-id: {{ .VolumeID }}
+spec:
+  capacity:
+    storage: {{ .Capacity }}
+  accessModes:
+    - {{ .AccessMode }}
+  persistentVolumeReclaimPolicy: {{ .ReclaimPolicy }}
+  storageClassName: ""
+  mountOptions:
+{{- range .MountOptions }}
+    - {{ . }}
+{{- end }}
+  nfs:
+    server: {{ .Server }}
+    path: {{ .Path }}
+    readOnly: {{ .ReadOnly }}
+  claimRef:
+    namespace: {{ .Namespace }}
+    name: {{ .PersistentVolumeClaimName }}
+---
+apiVersion: v1
+kind: PersistentVolumeClaim
+metadata:
+  name: {{ .PersistentVolumeClaimName }}
+  namespace: {{ .Namespace }}
+spec:
+  accessModes:
+    - {{ .AccessMode }}
+  storageClassName: ""
+  volumeName: {{ .PersistentVolumeName }}
+  resources:
+    requests:
+      storage: {{ .Capacity }}
+`))
+)
+
+// Options of the generated manifests
+type Options struct {
+	Namespace     string
+	ReclaimPolicy string
+}
+
+// manifest holds the values of a PersistentVolume and PersistentVolumeClaim pair
+type manifest struct {
+	VolumeID                  string
+	PersistentVolumeName      string
+	PersistentVolumeClaimName string
+	Namespace                 string
+	Capacity                  string
+	AccessMode                string
+	ReadOnly                  bool
+	ReclaimPolicy             string
+	MountOptions              []string
+	Server                    string
+	Path                      string
+}
+
+// WriteManifests reads volumes and writes their PersistentVolume and PersistentVolumeClaim manifests
+func WriteManifests(ctx context.Context, w io.Writer, volumeIDs []string, options Options) error {
+
+	for i, volumeID := range volumeIDs {
+		volume, err := sdkutils.GetANFVolumeByID(ctx, volumeID)
+		if err != nil {
+			return err
+		}
+
+		if i > 0 {
+			fmt.Fprintln(w, "---")
+		}
+
+		err = WriteVolumeManifests(w, volume, options)
+		if err != nil {
+			return fmt.Errorf("cannot generate manifests of %v: %v", volumeID, err)
+		}
+	}
+
+	return nil
+}
+
+// WriteVolumeManifests writes the PersistentVolume and PersistentVolumeClaim manifests of a volume
+func WriteVolumeManifests(w io.Writer, volume netapp.Volume, options Options) error {
+
+	if volume.VolumeProperties == nil || volume.Name == nil || volume.CreationToken == nil || volume.UsageThreshold == nil {
+		return fmt.Errorf("volume properties are missing")
+	}
+	if volume.MountTargets == nil || len(*volume.MountTargets) == 0 || (*volume.MountTargets)[0].IPAddress == nil {
+		return fmt.Errorf("volume has no mount targets yet")
+	}
+
+	protocol := nfsv3
+	if volume.ProtocolTypes != nil && len(*volume.ProtocolTypes) > 0 {
+		protocol = (*volume.ProtocolTypes)[0]
+	}
+	if protocol != nfsv3 && protocol != nfsv41 {
+		return fmt.Errorf("protocol type %v is not supported, only NFSv3 and NFSv4.1 volumes can be mounted with the Kubernetes NFS volume plugin", protocol)
+	}
+
+	namespace := options.Namespace
+	if namespace == "" {
+		namespace = "default"
+	}
+	reclaimPolicy := options.ReclaimPolicy
+	if reclaimPolicy == "" {
+		reclaimPolicy = "Retain"
+	}
+
+	// Volume names are account/pool/volume
+	segments := strings.Split(*volume.Name, "/")
+	claimName := objectName(segments[len(segments)-1])
+
+	readOnly := isReadOnly(volume)
+	accessMode := "ReadWriteMany"
+	if readOnly {
+		accessMode = "ReadOnlyMany"
+	}
+
+	kerberosEnabled := volume.KerberosEnabled != nil && *volume.KerberosEnabled
+	mountOptions := []string{}
+	for _, option := range strings.Split(mount.NFSOptions(protocol, kerberosEnabled), ",") {
+		// Read/write access is driven by the access mode
+		if option != "rw" {
+			mountOptions = append(mountOptions, option)
+		}
+	}
+
+	return manifestTemplate.Execute(w, manifest{
+		VolumeID:                  *volume.ID,
+		PersistentVolumeName:      objectName(fmt.Sprintf("anf-%v", claimName)),
+		PersistentVolumeClaimName: claimName,
+		Namespace:                 namespace,
+		Capacity:                  fmt.Sprintf("%vGi", *volume.UsageThreshold/sdkutils.GiBInBytes),
+		AccessMode:                accessMode,
+		ReadOnly:                  readOnly,
+		ReclaimPolicy:             reclaimPolicy,
+		MountOptions:              mountOptions,
+		Server:                    *(*volume.MountTargets)[0].IPAddress,
+		Path:                      fmt.Sprintf("/%v", *volume.CreationToken),
+	})
+}
+
+// isReadOnly checks whether no export policy rule grants read/write access
+func isReadOnly(volume netapp.Volume) bool {
+
+	if volume.ExportPolicy == nil || volume.ExportPolicy.Rules == nil || len(*volume.ExportPolicy.Rules) == 0 {
+		return false
+	}
+
+	for _, rule := range *volume.ExportPolicy.Rules {
+		if rule.UnixReadWrite != nil && *rule.UnixReadWrite {
+			return false
+		}
+	}
+
+	return true
+}
+
+// objectName turns a volume name into a valid Kubernetes object name (lower case RFC 1123 subdomain)
+func objectName(name string) string {
+	objectName := strings.Trim(invalidInObjectName.ReplaceAllString(strings.ToLower(name), "-"), "-")
+	if len(objectName) > 253 {
+		objectName = strings.Trim(objectName[:253], "-")
+	}
+	return objectName
+}
@@ -0,0 +1,76 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package logging
+
+import (
+	"crypto/rand"
+	"fmt"
+	"time"
+)
+
+const (
+	// Keys of the common fields
+	ResourceIDKey    string = "resourceId"
+	OperationKey     string = "operation"
+	CorrelationIDKey string = "correlationId"
+	DurationKey      string = "duration"
+	ErrorKey         string = "error"
+)
+
+// Field is a key value pair attached to a record
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// ResourceID is the Azure resource id a record is about
+func ResourceID(resourceID string) Field {
+	return Field{Key: ResourceIDKey, Value: resourceID}
+}
+
+// Operation is the operation a record is about, e.g. create
+func Operation(operation string) Field {
+	return Field{Key: OperationKey, Value: operation}
+}
+
+// CorrelationID ties together the records of a run
+func CorrelationID(correlationID string) Field {
+	return Field{Key: CorrelationIDKey, Value: correlationID}
+}
+
+// Duration is how long an operation took, written in seconds in JSON
+func Duration(duration time.Duration) Field {
+	return Field{Key: DurationKey, Value: duration}
+}
+
+// Err is the error a record reports
+func Err(err error) Field {
+	return Field{Key: ErrorKey, Value: err}
+}
+
+// String is any other text field
+func String(key, value string) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Any is any other field, its value must be serializable to JSON
+func Any(key string, value interface{}) Field {
+	return Field{Key: key, Value: value}
+}
+
+// NewCorrelationID returns a random identifier in the GUID format used by Azure correlation ids
+func NewCorrelationID() string {
+
+	id := make([]byte, 16)
+	_, err := rand.Read(id)
+	if err != nil {
+		return fmt.Sprintf("%032x", time.Now().UnixNano())
+	}
+	id[6] = id[6]&0x0f | 0x40
+	id[8] = id[8]&0x3f | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", id[0:4], id[4:6], id[6:8], id[8:10], id[10:])
+}
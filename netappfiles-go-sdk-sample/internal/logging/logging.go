@@ -0,0 +1,274 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// This package provides structured logging with levels, in the spirit
+// of log/slog which is not available with the Go version this sample
+// targets. Records carry fields such as the resource id, the operation,
+// a correlation id shared by a whole run and durations, and are written
+// either as text lines through the standard log package or as JSON lines
+// for log pipelines. A quiet mode only keeps errors.
+
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// Environment variables read by FromEnvironment
+	LevelEnvironmentVariable  string = "ANF_LOG_LEVEL"
+	FormatEnvironmentVariable string = "ANF_LOG_FORMAT"
+	QuietEnvironmentVariable  string = "ANF_LOG_QUIET"
+)
+
+// Level is the severity of a record
+type Level int
+
+// Levels from the most to the least verbose
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+// Logger writes records with a message and structured fields
+type Logger interface {
+	Debug(msg string, fields ...Field)
+	Info(msg string, fields ...Field)
+	Warn(msg string, fields ...Field)
+	Error(msg string, fields ...Field)
+	// With returns a logger adding the fields to every record
+	With(fields ...Field) Logger
+}
+
+// Options configure a logger, Quiet only keeps errors whatever the level
+type Options struct {
+	Level Level
+	JSON  bool
+	Quiet bool
+}
+
+type logger struct {
+	options Options
+	fields  []Field
+}
+
+var (
+	defaultLogger Logger = New(Options{Level: LevelInfo})
+	defaultMu     sync.RWMutex
+
+	// jsonMu keeps JSON lines written from several goroutines from interleaving
+	jsonMu sync.Mutex
+)
+
+// New creates a logger writing to the output of the standard log package
+func New(options Options) Logger {
+	return &logger{options: options}
+}
+
+// FromEnvironment reads the options from ANF_LOG_LEVEL (debug, info, warn or error),
+// ANF_LOG_FORMAT (text or json) and ANF_LOG_QUIET (true or false)
+func FromEnvironment() (Options, error) {
+
+	options := Options{Level: LevelInfo}
+
+	if value := os.Getenv(LevelEnvironmentVariable); value != "" {
+		level, err := ParseLevel(value)
+		if err != nil {
+			return options, err
+		}
+		options.Level = level
+	}
+
+	switch strings.ToLower(os.Getenv(FormatEnvironmentVariable)) {
+	case "", "text":
+	case "json":
+		options.JSON = true
+	default:
+		return options, fmt.Errorf("invalid log format %v, valid formats are text and json", os.Getenv(FormatEnvironmentVariable))
+	}
+
+	switch strings.ToLower(os.Getenv(QuietEnvironmentVariable)) {
+	case "", "false", "0":
+	case "true", "1":
+		options.Quiet = true
+	default:
+		return options, fmt.Errorf("invalid value %v for %v, expected true or false", os.Getenv(QuietEnvironmentVariable), QuietEnvironmentVariable)
+	}
+
+	return options, nil
+}
+
+// ParseLevel parses a level name, e.g. warn
+func ParseLevel(value string) (Level, error) {
+	for level := LevelDebug; level <= LevelError; level++ {
+		if strings.EqualFold(value, level.String()) {
+			return level, nil
+		}
+	}
+	return LevelInfo, fmt.Errorf("invalid log level %v, valid levels are debug, info, warn and error", value)
+}
+
+// String returns the name of the level
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "debug"
+	case LevelWarn:
+		return "warn"
+	case LevelError:
+		return "error"
+	default:
+		return "info"
+	}
+}
+
+// Default returns the logger used by the package level functions
+func Default() Logger {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultLogger
+}
+
+// SetDefault replaces the logger used by the package level functions
+func SetDefault(l Logger) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultLogger = l
+}
+
+// Debug writes a debug record with the default logger
+func Debug(msg string, fields ...Field) { Default().Debug(msg, fields...) }
+
+// Info writes an informational record with the default logger
+func Info(msg string, fields ...Field) { Default().Info(msg, fields...) }
+
+// Warn writes a warning with the default logger
+func Warn(msg string, fields ...Field) { Default().Warn(msg, fields...) }
+
+// Error writes an error with the default logger
+func Error(msg string, fields ...Field) { Default().Error(msg, fields...) }
+
+func (l *logger) Debug(msg string, fields ...Field) { l.write(LevelDebug, msg, fields) }
+func (l *logger) Info(msg string, fields ...Field)  { l.write(LevelInfo, msg, fields) }
+func (l *logger) Warn(msg string, fields ...Field)  { l.write(LevelWarn, msg, fields) }
+func (l *logger) Error(msg string, fields ...Field) { l.write(LevelError, msg, fields) }
+
+func (l *logger) With(fields ...Field) Logger {
+	return &logger{options: l.options, fields: append(append([]Field{}, l.fields...), fields...)}
+}
+
+func (l *logger) enabled(level Level) bool {
+	if l.options.Quiet {
+		return level >= LevelError
+	}
+	return level >= l.options.Level
+}
+
+// write renders a record, fields of the logger come before the fields of the record
+func (l *logger) write(level Level, msg string, fields []Field) {
+
+	if !l.enabled(level) {
+		return
+	}
+
+	all := append(append([]Field{}, l.fields...), fields...)
+	if l.options.JSON {
+		writeJSON(level, msg, all)
+		return
+	}
+	writeText(level, msg, all)
+}
+
+// writeText writes a line such as "2021/07/01 10:00:00 warning: message key=value" through the standard log package,
+// informational records have no level prefix so they read like plain console output
+func writeText(level Level, msg string, fields []Field) {
+
+	var line strings.Builder
+	switch level {
+	case LevelDebug:
+		line.WriteString("debug: ")
+	case LevelWarn:
+		line.WriteString("warning: ")
+	case LevelError:
+		line.WriteString("error: ")
+	}
+	line.WriteString(msg)
+
+	for _, field := range fields {
+		value := textValue(field.Value)
+		if value == "" || strings.ContainsAny(value, " \t\"=") {
+			value = fmt.Sprintf("%q", value)
+		}
+		line.WriteString(fmt.Sprintf(" %v=%v", field.Key, value))
+	}
+
+	log.Print(line.String())
+}
+
+// writeJSON writes a single JSON object per line, the console indentation of messages is dropped
+// and durations are written in seconds
+func writeJSON(level Level, msg string, fields []Field) {
+
+	var line bytes.Buffer
+	line.WriteString("{")
+	writeJSONField(&line, "time", time.Now().UTC().Format(time.RFC3339Nano))
+	line.WriteString(",")
+	writeJSONField(&line, "level", level.String())
+	line.WriteString(",")
+	writeJSONField(&line, "msg", strings.TrimSpace(msg))
+	for _, field := range fields {
+		line.WriteString(",")
+		writeJSONField(&line, field.Key, jsonValue(field.Value))
+	}
+	line.WriteString("}\n")
+
+	jsonMu.Lock()
+	defer jsonMu.Unlock()
+	log.Writer().Write(line.Bytes())
+}
+
+func writeJSONField(line *bytes.Buffer, key string, value interface{}) {
+
+	keyJSON, _ := json.Marshal(key)
+	valueJSON, err := json.Marshal(value)
+	if err != nil {
+		valueJSON, _ = json.Marshal(fmt.Sprintf("%v", value))
+	}
+
+	line.Write(keyJSON)
+	line.WriteString(":")
+	line.Write(valueJSON)
+}
+
+func textValue(value interface{}) string {
+	switch v := value.(type) {
+	case time.Duration:
+		return v.Round(time.Millisecond).String()
+	case error:
+		return v.Error()
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+func jsonValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case time.Duration:
+		return v.Seconds()
+	case error:
+		return v.Error()
+	default:
+		return v
+	}
+}
@@ -0,0 +1,67 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// This package defines where volume consumption and throughput metrics come from,
+// the default source queries Azure Monitor but any other source
+// (e.g. a fake one with fixed values) can be provided instead.
+
+package metrics
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/sdkutils"
+)
+
+// Source provides the consumed size of volumes
+type Source interface {
+	// VolumeConsumedBytes returns the number of bytes currently used within a volume
+	VolumeConsumedBytes(ctx context.Context, volumeID string) (int64, error)
+}
+
+// ThroughputSource provides the throughput of volumes, sources may optionally implement it
+type ThroughputSource interface {
+	// VolumePeakThroughputMibps returns the highest throughput observed recently on a volume in MiB/s
+	VolumePeakThroughputMibps(ctx context.Context, volumeID string) (float64, error)
+}
+
+// AzureMonitorSource gets volume consumption and throughput from Azure Monitor metrics
+type AzureMonitorSource struct{}
+
+// VolumeConsumedBytes returns the most recent logical size reported by Azure Monitor for the volume
+func (AzureMonitorSource) VolumeConsumedBytes(ctx context.Context, volumeID string) (int64, error) {
+	return sdkutils.GetANFVolumeConsumedBytes(ctx, volumeID)
+}
+
+// VolumePeakThroughputMibps returns the highest read plus write throughput of the volume within the last day
+func (AzureMonitorSource) VolumePeakThroughputMibps(ctx context.Context, volumeID string) (float64, error) {
+	return sdkutils.GetANFVolumePeakThroughputMibps(ctx, volumeID)
+}
+
+// FixedSource returns fixed values per volume id instead of querying Azure Monitor, e.g. in tests or to simulate
+// usage, a volume without a value is an error
+type FixedSource struct {
+	ConsumedBytes       map[string]int64
+	PeakThroughputMibps map[string]float64
+}
+
+// VolumeConsumedBytes returns the fixed consumed size of the volume
+func (s FixedSource) VolumeConsumedBytes(ctx context.Context, volumeID string) (int64, error) {
+	consumed, found := s.ConsumedBytes[volumeID]
+	if !found {
+		return 0, fmt.Errorf("no consumed size for volume %v", volumeID)
+	}
+	return consumed, nil
+}
+
+// VolumePeakThroughputMibps returns the fixed peak throughput of the volume
+func (s FixedSource) VolumePeakThroughputMibps(ctx context.Context, volumeID string) (float64, error) {
+	throughput, found := s.PeakThroughputMibps[volumeID]
+	if !found {
+		return 0, fmt.Errorf("no throughput for volume %v", volumeID)
+	}
+	return throughput, nil
+}
@@ -28,3 +28,56 @@ type AzureBasicInfo struct {
 	ResourceManagerEndpointURL *string
 	ManagementEndpointURL      *string
 }
+
+// DeploymentSpec object definition, describes a set of ANF resources to be deployed
+type DeploymentSpec struct {
+	Location      string            `json:"location"`
+	ResourceGroup string            `json:"resourceGroup"`
+	SubnetID      string            `json:"subnetId,omitempty"`
+	Tags          map[string]string `json:"tags,omitempty"`
+	Accounts      []AccountSpec     `json:"accounts"`
+}
+
+// AccountSpec object definition
+type AccountSpec struct {
+	Name     string            `json:"name"`
+	Location string            `json:"location,omitempty"`
+	Tags     map[string]string `json:"tags,omitempty"`
+	Pools    []PoolSpec        `json:"pools"`
+}
+
+// PoolSpec object definition
+type PoolSpec struct {
+	Name         string            `json:"name"`
+	ServiceLevel string            `json:"serviceLevel"`
+	SizeTiB      int64             `json:"sizeTiB"`
+	QosType      string            `json:"qosType,omitempty"`
+	Tags         map[string]string `json:"tags,omitempty"`
+	Volumes      []VolumeSpec      `json:"volumes"`
+}
+
+// VolumeSpec object definition
+type VolumeSpec struct {
+	Name          string            `json:"name"`
+	CreationToken string            `json:"creationToken,omitempty"`
+	ProtocolTypes []string          `json:"protocolTypes"`
+	QuotaGiB      int64             `json:"quotaGiB"`
+	Tags          map[string]string `json:"tags,omitempty"`
+	Backup        *BackupSpec       `json:"backup,omitempty"`
+	Replication   *ReplicationSpec  `json:"replication,omitempty"`
+}
+
+// BackupSpec object definition, backups are kept according to daily, weekly and monthly retention
+type BackupSpec struct {
+	DailyBackupsToKeep   int32 `json:"dailyBackupsToKeep"`
+	WeeklyBackupsToKeep  int32 `json:"weeklyBackupsToKeep"`
+	MonthlyBackupsToKeep int32 `json:"monthlyBackupsToKeep"`
+	EstimatedSizeGiB     int64 `json:"estimatedSizeGiB,omitempty"`
+}
+
+// ReplicationSpec object definition, cross region replication to a volume in another location
+type ReplicationSpec struct {
+	RemoteLocation     string `json:"remoteLocation"`
+	RemoteServiceLevel string `json:"remoteServiceLevel"`
+	Schedule           string `json:"schedule"`
+}
@@ -0,0 +1,208 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// This package builds ready to use mount instructions for a volume
+// from its mount targets and export path (creation token): mount
+// commands, /etc/fstab entries and systemd mount units for NFS and
+// UNC paths for SMB. Recommended options come from
+// https://docs.microsoft.com/en-us/azure/azure-netapp-files/azure-netapp-files-mount-unmount-volumes-for-virtual-machines
+
+package mount
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/sdkutils"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/utils"
+	"github.com/Azure/azure-sdk-for-go/services/netapp/mgmt/2021-04-01/netapp"
+	"github.com/Azure/go-autorest/autorest/to"
+)
+
+const (
+	nfsv3  string = "NFSv3"
+	nfsv41 string = "NFSv4.1"
+	cifs   string = "CIFS"
+
+	nfsv3Options    string = "rw,hard,rsize=262144,wsize=262144,vers=3,tcp"
+	nfsv41Options   string = "rw,hard,rsize=262144,wsize=262144,vers=4.1,tcp"
+	smbOptions      string = "vers=3.0,username=<user>,domain=<domain>,dir_mode=0777,file_mode=0777,serverino"
+	smbFstabOptions string = "vers=3.0,credentials=/etc/smbcredentials,dir_mode=0777,file_mode=0777,serverino"
+)
+
+// Instructions describe how to mount a volume with one protocol
+type Instructions struct {
+	Protocol        string
+	Source          string
+	Options         string
+	Commands        []string
+	Fstab           string
+	SystemdUnitName string
+	SystemdUnit     string
+}
+
+// VolumeInstructions are the mount instructions of a volume, one per protocol it exports
+type VolumeInstructions struct {
+	VolumeID     string
+	VolumeName   string
+	MountPoint   string
+	Instructions []Instructions
+}
+
+// GetInstructions reads a volume and builds its mount instructions
+func GetInstructions(ctx context.Context, volumeID, mountPoint string) (VolumeInstructions, error) {
+
+	volume, err := sdkutils.GetANFVolumeByID(ctx, volumeID)
+	if err != nil {
+		return VolumeInstructions{}, err
+	}
+
+	return ForVolume(volume, mountPoint)
+}
+
+// ForVolume builds the mount instructions of a volume, the mount point defaults to /mnt/<creation token>
+func ForVolume(volume netapp.Volume, mountPoint string) (VolumeInstructions, error) {
+
+	if volume.VolumeProperties == nil || volume.CreationToken == nil {
+		return VolumeInstructions{}, fmt.Errorf("volume has no export path")
+	}
+	if volume.MountTargets == nil || len(*volume.MountTargets) == 0 {
+		return VolumeInstructions{}, fmt.Errorf("volume has no mount targets yet")
+	}
+
+	exportPath := *volume.CreationToken
+	target := (*volume.MountTargets)[0]
+	if mountPoint == "" {
+		mountPoint = fmt.Sprintf("/mnt/%v", exportPath)
+	}
+
+	result := VolumeInstructions{
+		VolumeID:   to.String(volume.ID),
+		VolumeName: to.String(volume.Name),
+		MountPoint: mountPoint,
+	}
+
+	protocolTypes := []string{nfsv3}
+	if volume.ProtocolTypes != nil && len(*volume.ProtocolTypes) > 0 {
+		protocolTypes = *volume.ProtocolTypes
+	}
+
+	for _, protocol := range protocolTypes {
+		switch protocol {
+		case nfsv3, nfsv41:
+			if target.IPAddress == nil {
+				return VolumeInstructions{}, fmt.Errorf("mount target has no IP address")
+			}
+			options := NFSOptions(protocol, volume.KerberosEnabled != nil && *volume.KerberosEnabled)
+			result.Instructions = append(result.Instructions, nfsInstructions(protocol, *target.IPAddress, exportPath, mountPoint, options, to.String(volume.Name)))
+		case cifs:
+			if target.SmbServerFqdn == nil {
+				return VolumeInstructions{}, fmt.Errorf("mount target has no SMB server name")
+			}
+			result.Instructions = append(result.Instructions, smbInstructions(*target.SmbServerFqdn, exportPath, mountPoint))
+		default:
+			return VolumeInstructions{}, fmt.Errorf("protocol type %v is not supported", protocol)
+		}
+	}
+
+	return result, nil
+}
+
+// NFSOptions returns the recommended NFS mount options of a protocol type, NFSv3 or NFSv4.1
+func NFSOptions(protocol string, kerberosEnabled bool) string {
+	if protocol == nfsv3 {
+		return nfsv3Options
+	}
+	if kerberosEnabled {
+		return fmt.Sprintf("%v,sec=krb5", nfsv41Options)
+	}
+	return nfsv41Options
+}
+
+// Print writes the mount instructions to the console, optionally with /etc/fstab and systemd snippets
+func (v VolumeInstructions) Print(withFstab, withSystemd bool) {
+
+	utils.ConsoleOutput(fmt.Sprintf("Volume %v (%v):", v.VolumeName, v.VolumeID))
+	for _, instructions := range v.Instructions {
+		utils.ConsoleOutput(fmt.Sprintf("\t%v - %v", instructions.Protocol, instructions.Source))
+		for _, command := range instructions.Commands {
+			utils.ConsoleOutput(fmt.Sprintf("\t\t%v", command))
+		}
+
+		if withFstab && instructions.Fstab != "" {
+			utils.ConsoleOutput("\t/etc/fstab entry:")
+			utils.ConsoleOutput(fmt.Sprintf("\t\t%v", instructions.Fstab))
+		}
+
+		if withSystemd && instructions.SystemdUnit != "" {
+			utils.ConsoleOutput(fmt.Sprintf("\tsystemd mount unit /etc/systemd/system/%v:", instructions.SystemdUnitName))
+			for _, line := range strings.Split(instructions.SystemdUnit, "\n") {
+				utils.ConsoleOutput(fmt.Sprintf("\t\t%v", line))
+			}
+		}
+	}
+}
+
+func nfsInstructions(protocol, ipAddress, exportPath, mountPoint, options, volumeName string) Instructions {
+
+	source := fmt.Sprintf("%v:/%v", ipAddress, exportPath)
+	unitName := fmt.Sprintf("%v.mount", systemdEscapePath(mountPoint))
+
+	return Instructions{
+		Protocol: protocol,
+		Source:   source,
+		Options:  options,
+		Commands: []string{
+			fmt.Sprintf("sudo mkdir -p %v", mountPoint),
+			fmt.Sprintf("sudo mount -t nfs -o %v %v %v", options, source, mountPoint),
+		},
+		Fstab:           fmt.Sprintf("%v %v nfs %v 0 0", source, mountPoint, options),
+		SystemdUnitName: unitName,
+		SystemdUnit: strings.Join([]string{
+			"[Unit]",
+			fmt.Sprintf("Description=Azure NetApp Files volume %v", volumeName),
+			"After=network-online.target",
+			"Wants=network-online.target",
+			"",
+			"[Mount]",
+			fmt.Sprintf("What=%v", source),
+			fmt.Sprintf("Where=%v", mountPoint),
+			"Type=nfs",
+			fmt.Sprintf("Options=%v", options),
+			"",
+			"[Install]",
+			"WantedBy=multi-user.target",
+		}, "\n"),
+	}
+}
+
+func smbInstructions(smbServerFqdn, shareName, mountPoint string) Instructions {
+
+	uncPath := fmt.Sprintf(`\\%v\%v`, smbServerFqdn, shareName)
+	linuxSource := fmt.Sprintf("//%v/%v", smbServerFqdn, shareName)
+
+	return Instructions{
+		Protocol: "SMB",
+		Source:   uncPath,
+		Options:  smbOptions,
+		Commands: []string{
+			fmt.Sprintf("net use Z: %v", uncPath),
+			fmt.Sprintf("sudo mkdir -p %v", mountPoint),
+			fmt.Sprintf("sudo mount -t cifs -o %v %v %v", smbOptions, linuxSource, mountPoint),
+		},
+		Fstab: fmt.Sprintf("%v %v cifs %v 0 0", linuxSource, mountPoint, smbFstabOptions),
+	}
+}
+
+// systemdEscapePath returns the systemd unit name of a mount point, e.g. /mnt/my-vol becomes mnt-my\x2dvol
+func systemdEscapePath(path string) string {
+	trimmed := strings.Trim(path, "/")
+	if trimmed == "" {
+		return "-"
+	}
+	escaped := strings.ReplaceAll(trimmed, "-", `\x2d`)
+	return strings.ReplaceAll(escaped, "/", "-")
+}
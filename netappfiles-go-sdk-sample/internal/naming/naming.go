@@ -0,0 +1,285 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// This package validates Azure NetApp Files resource names, checks
+// their availability with the NetApp name and file path availability
+// APIs and builds names from a pluggable naming convention.
+
+package naming
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/sdkutils"
+	"github.com/Azure/azure-sdk-for-go/services/netapp/mgmt/2021-04-01/netapp"
+)
+
+// ResourceType is a kind of named Azure NetApp Files resource
+type ResourceType string
+
+const (
+	// Account is a NetApp account
+	Account ResourceType = "account"
+	// CapacityPool is a capacity pool
+	CapacityPool ResourceType = "capacityPool"
+	// Volume is a volume
+	Volume ResourceType = "volume"
+	// Snapshot is a volume snapshot
+	Snapshot ResourceType = "snapshot"
+	// SnapshotPolicy is a snapshot policy
+	SnapshotPolicy ResourceType = "snapshotPolicy"
+	// BackupPolicy is a backup policy
+	BackupPolicy ResourceType = "backupPolicy"
+	// Backup is a volume backup
+	Backup ResourceType = "backup"
+	// CreationToken is the export path of a volume, unique within its subnet
+	CreationToken ResourceType = "creationToken"
+
+	// DefaultTemplate is the naming convention template used when none is provided
+	DefaultTemplate string = "{env}-{app}-{proto}-{n}"
+
+	// Highest sequence number tried when looking for an available name
+	maxSequenceNumber int = 999
+)
+
+// rule is the set of constraints a name must satisfy
+type rule struct {
+	pattern     *regexp.Regexp
+	description string
+}
+
+var (
+	// Patterns come from the Azure NetApp Files REST API specification
+	rules = map[ResourceType]rule{
+		Account:        {regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9\-_]{0,127}$`), "1 to 128 letters, digits, hyphens or underscores, starting with a letter or digit"},
+		CapacityPool:   {regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9\-_]{0,63}$`), "1 to 64 letters, digits, hyphens or underscores, starting with a letter or digit"},
+		Volume:         {regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9\-_]{0,63}$`), "1 to 64 letters, digits, hyphens or underscores, starting with a letter"},
+		Snapshot:       {regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9\-_.]{0,254}$`), "1 to 255 letters, digits, hyphens, underscores or periods, starting with a letter or digit"},
+		SnapshotPolicy: {regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9\-_]{0,63}$`), "1 to 64 letters, digits, hyphens or underscores, starting with a letter or digit"},
+		BackupPolicy:   {regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9\-_]{0,63}$`), "1 to 64 letters, digits, hyphens or underscores, starting with a letter or digit"},
+		Backup:         {regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9\-_.]{0,63}$`), "1 to 64 letters, digits, hyphens, underscores or periods, starting with a letter or digit"},
+		CreationToken:  {regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9\-]{0,79}$`), "1 to 80 letters, digits or hyphens, starting with a letter"},
+	}
+
+	// Resource types supported by the name availability API
+	availabilityTypes = map[ResourceType]netapp.CheckNameResourceTypes{
+		Account:      netapp.CheckNameResourceTypesMicrosoftNetAppnetAppAccounts,
+		CapacityPool: netapp.CheckNameResourceTypesMicrosoftNetAppnetAppAccountscapacityPools,
+		Volume:       netapp.CheckNameResourceTypesMicrosoftNetAppnetAppAccountscapacityPoolsvolumes,
+		Snapshot:     netapp.CheckNameResourceTypesMicrosoftNetAppnetAppAccountscapacityPoolsvolumessnapshots,
+	}
+
+	// Resource types of the name following each segment of an Azure NetApp Files resource id
+	idSegmentTypes = map[string]ResourceType{
+		"netappaccounts":   Account,
+		"capacitypools":    CapacityPool,
+		"volumes":          Volume,
+		"snapshots":        Snapshot,
+		"snapshotpolicies": SnapshotPolicy,
+		"backuppolicies":   BackupPolicy,
+		"backups":          Backup,
+		"accountbackups":   Backup,
+	}
+
+	placeholder   = regexp.MustCompile(`\{[^{}]*\}`)
+	invalidInName = regexp.MustCompile(`[^a-zA-Z0-9\-]`)
+)
+
+// Fields are the values available to a naming convention, e.g. env, app, proto or n
+type Fields map[string]string
+
+// Convention builds resource names, custom implementations can be plugged in to enforce a naming policy
+type Convention interface {
+	Name(resourceType ResourceType, fields Fields) (string, error)
+}
+
+// TemplateConvention is a naming convention based on templates like {env}-{app}-{proto}-{n},
+// Templates can hold one template per resource type and Template is used for any other type
+type TemplateConvention struct {
+	Template  string
+	Templates map[ResourceType]string
+	Defaults  Fields
+}
+
+// NewTemplateConvention returns a convention applying the same template to all resource types
+func NewTemplateConvention(template string, defaults Fields) TemplateConvention {
+	return TemplateConvention{
+		Template: template,
+		Defaults: defaults,
+	}
+}
+
+// Name renders the template of a resource type, field values are stripped of characters not allowed
+// in every resource name (e.g. NFSv4.1 becomes NFSv41) and the result is validated
+func (c TemplateConvention) Name(resourceType ResourceType, fields Fields) (string, error) {
+
+	template := c.Template
+	if t, found := c.Templates[resourceType]; found {
+		template = t
+	}
+	if template == "" {
+		template = DefaultTemplate
+	}
+
+	missing := []string{}
+	name := placeholder.ReplaceAllStringFunc(template, func(p string) string {
+		key := strings.Trim(p, "{}")
+		value, found := fields[key]
+		if !found {
+			value, found = c.Defaults[key]
+		}
+		if !found {
+			missing = append(missing, key)
+			return p
+		}
+		return invalidInName.ReplaceAllString(value, "")
+	})
+
+	if len(missing) > 0 {
+		return "", fmt.Errorf("naming template %v has no value for %v", template, strings.Join(missing, ", "))
+	}
+
+	return name, ValidateName(resourceType, name)
+}
+
+// ValidateName checks the length and allowed characters of a resource name
+func ValidateName(resourceType ResourceType, name string) error {
+
+	r, found := rules[resourceType]
+	if !found {
+		return fmt.Errorf("unknown resource type %v", resourceType)
+	}
+
+	if !r.pattern.MatchString(name) {
+		return fmt.Errorf("invalid %v name %q, it must have %v", resourceType, name, r.description)
+	}
+
+	return nil
+}
+
+// ValidateResourceID checks every Azure NetApp Files name of a resource id, e.g. the account, capacity pool
+// and volume names of a volume id, so commands reject invalid names before calling Azure
+func ValidateResourceID(resourceID string) error {
+
+	segments := strings.Split(strings.Trim(resourceID, "/"), "/")
+
+	provider := -1
+	for i, segment := range segments {
+		if strings.EqualFold(segment, "Microsoft.NetApp") {
+			provider = i
+			break
+		}
+	}
+	if provider == -1 || provider+2 >= len(segments) {
+		return fmt.Errorf("%q is not an Azure NetApp Files resource id", resourceID)
+	}
+
+	for i := provider + 1; i < len(segments); i += 2 {
+		resourceType, found := idSegmentTypes[strings.ToLower(segments[i])]
+		if !found || i+1 >= len(segments) {
+			return fmt.Errorf("%q is not an Azure NetApp Files resource id", resourceID)
+		}
+		err := ValidateName(resourceType, segments[i+1])
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// CheckNameAvailability validates a name and asks Azure whether it is available, nested resources
+// are identified by their parent names, e.g. account/pool/volume for a volume
+func CheckNameAvailability(ctx context.Context, location, resourceGroupName string, resourceType ResourceType, name string) error {
+
+	segments := strings.Split(name, "/")
+	err := ValidateName(resourceType, segments[len(segments)-1])
+	if err != nil {
+		return err
+	}
+
+	availabilityType, found := availabilityTypes[resourceType]
+	if !found {
+		return nil
+	}
+
+	available, reason, err := sdkutils.CheckANFNameAvailability(ctx, location, resourceGroupName, name, availabilityType)
+	if err != nil {
+		return err
+	}
+	if !available {
+		return fmt.Errorf("%v name %v is not available: %v", resourceType, name, reason)
+	}
+
+	return nil
+}
+
+// CheckFilePathAvailability validates a volume creation token and asks Azure whether it is unique within the subnet
+func CheckFilePathAvailability(ctx context.Context, location, subnetID, creationToken string) error {
+
+	err := ValidateName(CreationToken, creationToken)
+	if err != nil {
+		return err
+	}
+
+	available, reason, err := sdkutils.CheckANFFilePathAvailability(ctx, location, subnetID, creationToken)
+	if err != nil {
+		return err
+	}
+	if !available {
+		return fmt.Errorf("file path %v is already used in subnet %v: %v", creationToken, subnetID, reason)
+	}
+
+	return nil
+}
+
+// NextVolumeName builds a volume name from the convention using the first sequence number {n} whose name is
+// not in taken and whose file path is available in the subnet, the volume name is used as its creation token
+func NextVolumeName(ctx context.Context, convention Convention, fields Fields, location, subnetID string, taken []string) (string, error) {
+
+	for n := 1; n <= maxSequenceNumber; n++ {
+		volumeFields := Fields{"n": fmt.Sprintf("%v", n)}
+		for key, value := range fields {
+			volumeFields[key] = value
+		}
+
+		name, err := convention.Name(Volume, volumeFields)
+		if err != nil {
+			return "", err
+		}
+
+		err = ValidateName(CreationToken, name)
+		if err != nil {
+			return "", err
+		}
+
+		if contains(taken, name) {
+			continue
+		}
+
+		available, _, err := sdkutils.CheckANFFilePathAvailability(ctx, location, subnetID, name)
+		if err != nil {
+			return "", err
+		}
+		if available {
+			return name, nil
+		}
+	}
+
+	return "", fmt.Errorf("no available volume name found with sequence numbers up to %v", maxSequenceNumber)
+}
+
+// contains checks if a name is in a list, ignoring case as Azure does
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if strings.EqualFold(n, name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,45 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package naming
+
+import (
+	"testing"
+)
+
+func TestValidateResourceID(t *testing.T) {
+
+	const accountID = "/subscriptions/s/resourceGroups/rg/providers/Microsoft.NetApp/netAppAccounts/account"
+
+	tests := []struct {
+		name       string
+		resourceID string
+		wantErr    bool
+	}{
+		{"account", accountID, false},
+		{"volume", accountID + "/capacityPools/pool/volumes/volume1", false},
+		{"snapshot", accountID + "/capacityPools/pool/volumes/volume1/snapshots/snap.1", false},
+		{"snapshot policy", accountID + "/snapshotPolicies/daily", false},
+		{"resource group named like a resource type", "/subscriptions/s/resourceGroups/volumes/providers/Microsoft.NetApp/netAppAccounts/account", false},
+		{"volume starting with a digit", accountID + "/capacityPools/pool/volumes/1volume", true},
+		{"pool with a period", accountID + "/capacityPools/pool.1", true},
+		{"snapshot name too long", accountID + "/capacityPools/pool/volumes/volume1/snapshots/" + string(make([]byte, 256)), true},
+		{"missing name", accountID + "/capacityPools", true},
+		{"not a netapp resource", "/subscriptions/s/resourceGroups/rg/providers/Microsoft.Network/virtualNetworks/vnet", true},
+		{"unknown segment", accountID + "/vaults/vault", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := ValidateResourceID(test.resourceID)
+			if test.wantErr && err == nil {
+				t.Errorf("expected an error for %v", test.resourceID)
+			}
+			if !test.wantErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
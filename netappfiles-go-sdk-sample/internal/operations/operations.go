@@ -0,0 +1,241 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// This package keeps handles of long-running operations, e.g. volume
+// creations, in a local file while they are in progress. A handle holds
+// the serialized poller of the operation, its Azure-AsyncOperation URL
+// and the resource it applies to, so a later run of the tool can resume
+// polling after the process that started the operation was interrupted.
+
+package operations
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/progress"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/sdkutils"
+	"github.com/Azure/go-autorest/autorest/azure"
+)
+
+const (
+	// DefaultStorePath is the file used when no other operation store location is provided
+	DefaultStorePath string = "anf-operations.json"
+
+	// Operations tracked by a handle
+	Create string = "create"
+	Delete string = "delete"
+
+	// pollingInterval is used when the service does not ask for a specific delay between polls
+	pollingInterval = 10 * time.Second
+)
+
+// Handle is a long-running operation that can be resumed, Future is the serialized poller of the operation
+type Handle struct {
+	ID           string          `json:"id"`
+	Operation    string          `json:"operation"`
+	ResourceID   string          `json:"resourceId"`
+	ResourceType string          `json:"resourceType"`
+	StartedAt    time.Time       `json:"startedAt"`
+	PollingURL   string          `json:"pollingUrl"`
+	Future       json.RawMessage `json:"future"`
+}
+
+// Store is the list of operations in progress, it can be updated from several goroutines
+type Store struct {
+	path    string
+	mu      sync.Mutex
+	Handles []Handle `json:"handles"`
+}
+
+// NewHandle serializes the future of an operation started by one of the sdkutils Begin functions
+func NewHandle(operation, resourceID, resourceType string, future azure.Future) (Handle, error) {
+
+	futureJSON, err := json.Marshal(future)
+	if err != nil {
+		return Handle{}, fmt.Errorf("cannot serialize operation future: %v", err)
+	}
+
+	startedAt := time.Now().UTC()
+
+	return Handle{
+		ID:           strconv.FormatInt(startedAt.UnixNano(), 36),
+		Operation:    operation,
+		ResourceID:   resourceID,
+		ResourceType: resourceType,
+		StartedAt:    startedAt,
+		PollingURL:   future.PollingURL(),
+		Future:       futureJSON,
+	}, nil
+}
+
+// Poll checks once if the operation is done and returns its last known status, e.g. InProgress or Succeeded,
+// an error is returned when the operation failed
+func Poll(ctx context.Context, handle Handle) (bool, string, error) {
+
+	future, err := handle.future()
+	if err != nil {
+		return false, "", err
+	}
+
+	done, err := sdkutils.PollANFOperation(ctx, &future)
+	return done, future.Status(), err
+}
+
+// Wait resumes polling the operation until it completes, reporting its progress with the default progress monitor
+func Wait(ctx context.Context, handle Handle) error {
+
+	future, err := handle.future()
+	if err != nil {
+		return err
+	}
+
+	tracker := progress.Start(handle.Operation, handle.ResourceID, handle.ResourceType, handle.StartedAt)
+	for {
+		done, err := sdkutils.PollANFOperation(ctx, &future)
+		if done || err != nil {
+			tracker.Done(err)
+			return err
+		}
+
+		// The resource is gone at the end of a deletion, its provisioning state is only informative
+		provisioningState, _ := sdkutils.GetANFProvisioningState(ctx, handle.ResourceID)
+		tracker.Poll(provisioningState)
+
+		delay, found := future.GetPollingDelay()
+		if !found || delay <= 0 {
+			delay = pollingInterval
+		}
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			tracker.Done(ctx.Err())
+			return ctx.Err()
+		}
+	}
+}
+
+func (h Handle) future() (azure.Future, error) {
+
+	var future azure.Future
+	err := json.Unmarshal(h.Future, &future)
+	if err != nil {
+		return azure.Future{}, fmt.Errorf("cannot parse future of operation %v: %v", h.ID, err)
+	}
+
+	return future, nil
+}
+
+// Load reads an operation store from disk, a missing file results in an empty store
+func Load(path string) (*Store, error) {
+
+	store := &Store{path: path}
+
+	storeJSON, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot read operation store: %v", err)
+	}
+
+	err = json.Unmarshal(storeJSON, store)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse operation store %v: %v", path, err)
+	}
+
+	return store, nil
+}
+
+// Add records an operation in progress and saves the store
+func (s *Store) Add(handle Handle) error {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Handles = append(s.Handles, handle)
+
+	return s.save()
+}
+
+// Remove drops an operation, usually once it completed, and saves the store
+func (s *Store) Remove(id string) error {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, handle := range s.Handles {
+		if handle.ID == id {
+			s.Handles = append(s.Handles[:i], s.Handles[i+1:]...)
+			return s.save()
+		}
+	}
+
+	return nil
+}
+
+// Get returns an operation of the store
+func (s *Store) Get(id string) (Handle, bool) {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, handle := range s.Handles {
+		if handle.ID == id {
+			return handle, true
+		}
+	}
+
+	return Handle{}, false
+}
+
+// Track records an operation while polling it until it completes, the handle is kept when polling
+// is interrupted so the operation can be resumed with Wait
+func (s *Store) Track(ctx context.Context, operation, resourceID, resourceType string, future azure.Future) error {
+
+	handle, err := NewHandle(operation, resourceID, resourceType, future)
+	if err != nil {
+		return err
+	}
+
+	err = s.Add(handle)
+	if err != nil {
+		return err
+	}
+
+	err = Wait(ctx, handle)
+	if err != nil && ctx.Err() != nil {
+		return err
+	}
+
+	removeErr := s.Remove(handle.ID)
+	if err != nil {
+		return err
+	}
+
+	return removeErr
+}
+
+func (s *Store) save() error {
+
+	storeJSON, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("cannot serialize operation store: %v", err)
+	}
+
+	err = ioutil.WriteFile(s.path, storeJSON, 0644)
+	if err != nil {
+		return fmt.Errorf("cannot write operation store: %v", err)
+	}
+
+	return nil
+}
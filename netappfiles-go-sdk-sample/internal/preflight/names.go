@@ -0,0 +1,82 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package preflight
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/models"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/naming"
+)
+
+// CheckNames validates the length and characters of every name of a deployment spec and that volume file paths
+// (creation tokens, which default to the volume name) are unique within the spec. Unless offline is set,
+// the NetApp name and file path availability APIs are also asked whether each name is still available.
+func CheckNames(ctx context.Context, spec models.DeploymentSpec, offline bool) Report {
+
+	report := Report{}
+	filePaths := map[string]string{}
+
+	check := func(resourceType naming.ResourceType, location, name string) {
+		var err error
+		if offline {
+			segments := strings.Split(name, "/")
+			err = naming.ValidateName(resourceType, segments[len(segments)-1])
+		} else {
+			err = naming.CheckNameAvailability(ctx, location, spec.ResourceGroup, resourceType, name)
+		}
+		if err != nil {
+			report.add("names", SeverityError, err.Error(), "rename the resource in the deployment spec")
+		}
+	}
+
+	for _, account := range spec.Accounts {
+		location := accountLocation(spec, account)
+		check(naming.Account, location, account.Name)
+
+		for _, pool := range account.Pools {
+			poolName := fmt.Sprintf("%v/%v", account.Name, pool.Name)
+			check(naming.CapacityPool, location, poolName)
+
+			for _, volume := range pool.Volumes {
+				volumeName := fmt.Sprintf("%v/%v", poolName, volume.Name)
+				check(naming.Volume, location, volumeName)
+
+				creationToken := volume.CreationToken
+				if creationToken == "" {
+					creationToken = volume.Name
+				}
+
+				if other, found := filePaths[strings.ToLower(creationToken)]; found {
+					report.add("names", SeverityError,
+						fmt.Sprintf("volumes %v and %v use the same file path %v", other, volumeName, creationToken),
+						"set a distinct creationToken on one of the volumes",
+					)
+					continue
+				}
+				filePaths[strings.ToLower(creationToken)] = volumeName
+
+				var err error
+				if offline || spec.SubnetID == "" {
+					err = naming.ValidateName(naming.CreationToken, creationToken)
+				} else {
+					err = naming.CheckFilePathAvailability(ctx, location, spec.SubnetID, creationToken)
+				}
+				if err != nil {
+					report.add("names", SeverityError, fmt.Sprintf("volume %v: %v", volumeName, err), "set a valid and unused creationToken on the volume")
+				}
+			}
+		}
+	}
+
+	if !report.HasErrors() {
+		report.add("names", SeverityInfo, map[bool]string{true: "all names are valid", false: "all names are valid and available"}[offline], "")
+	}
+
+	return report
+}
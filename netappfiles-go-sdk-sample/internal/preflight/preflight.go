@@ -0,0 +1,225 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// This package runs checks before any NetApp resource is created and
+// returns a report with actionable findings, so deployments fail early
+// instead of half way through.
+
+package preflight
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/sdkutils"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/uri"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/utils"
+	"github.com/Azure/azure-sdk-for-go/profiles/latest/network/mgmt/network"
+)
+
+const (
+	netAppDelegation string = "Microsoft.NetApp/volumes"
+	// Azure reserves the first four and the last address of every subnet
+	azureReservedIPs int = 5
+)
+
+// Severity of a finding
+type Severity string
+
+const (
+	// SeverityError findings make the deployment fail
+	SeverityError Severity = "error"
+	// SeverityWarning findings may make the deployment fail or behave differently than expected
+	SeverityWarning Severity = "warning"
+	// SeverityInfo findings are informational only
+	SeverityInfo Severity = "info"
+)
+
+// Finding is the outcome of a single check
+type Finding struct {
+	Check       string
+	Severity    Severity
+	Message     string
+	Remediation string
+}
+
+// Report holds all findings of a preflight execution
+type Report struct {
+	Findings []Finding
+}
+
+// HasErrors returns true when at least one finding would make the deployment fail
+func (r Report) HasErrors() bool {
+	for _, finding := range r.Findings {
+		if finding.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// Print writes all findings to the console
+func (r Report) Print() {
+	for _, finding := range r.Findings {
+		utils.ConsoleOutput(fmt.Sprintf("\t[%v] %v: %v", finding.Severity, finding.Check, finding.Message))
+		if finding.Remediation != "" {
+			utils.ConsoleOutput(fmt.Sprintf("\t\tto fix: %v", finding.Remediation))
+		}
+	}
+}
+
+func (r *Report) add(check string, severity Severity, message, remediation string) {
+	r.Findings = append(r.Findings, Finding{
+		Check:       check,
+		Severity:    severity,
+		Message:     message,
+		Remediation: remediation,
+	})
+}
+
+// CheckNetwork verifies that the subnet exists and is delegated to Microsoft.NetApp/volumes, that its virtual network
+// is in the same region as the account and that there are enough free IP addresses for the planned volumes.
+// It also reports when Standard network features are required by the subnet configuration.
+func CheckNetwork(ctx context.Context, subnetID, location string, plannedVolumes int) (Report, error) {
+
+	report := Report{}
+
+	vnetName := uri.GetResourceValue(subnetID, "/virtualNetworks")
+	subnetName := uri.GetResourceValue(subnetID, "/subnets")
+	if vnetName == "" || subnetName == "" {
+		return Report{}, fmt.Errorf("resource id %v is not a subnet", subnetID)
+	}
+
+	subnet, err := sdkutils.GetSubnet(ctx, subnetID)
+	if err != nil {
+		report.add("subnet", SeverityError,
+			fmt.Sprintf("subnet %v could not be read: %v", subnetID, err),
+			fmt.Sprintf("create subnet %v in virtual network %v delegated to %v, or enable the network bootstrap", subnetName, vnetName, netAppDelegation),
+		)
+		return report, nil
+	}
+	report.add("subnet", SeverityInfo, fmt.Sprintf("subnet %v found", subnetID), "")
+
+	if subnet.SubnetPropertiesFormat == nil {
+		return Report{}, fmt.Errorf("subnet %v has no properties", subnetID)
+	}
+
+	// Delegation
+	delegated := false
+	otherDelegations := []string{}
+	if subnet.Delegations != nil {
+		for _, delegation := range *subnet.Delegations {
+			if delegation.ServiceDelegationPropertiesFormat == nil || delegation.ServiceName == nil {
+				continue
+			}
+			if strings.EqualFold(*delegation.ServiceName, netAppDelegation) {
+				delegated = true
+			} else {
+				otherDelegations = append(otherDelegations, *delegation.ServiceName)
+			}
+		}
+	}
+
+	switch {
+	case len(otherDelegations) > 0:
+		report.add("delegation", SeverityError,
+			fmt.Sprintf("subnet is delegated to %v", strings.Join(otherDelegations, ", ")),
+			fmt.Sprintf("use a subnet dedicated to %v", netAppDelegation),
+		)
+	case !delegated:
+		report.add("delegation", SeverityError,
+			fmt.Sprintf("subnet is not delegated to %v", netAppDelegation),
+			fmt.Sprintf("az network vnet subnet update -g %v --vnet-name %v -n %v --delegations %v", uri.GetResourceGroup(subnetID), vnetName, subnetName, netAppDelegation),
+		)
+	default:
+		report.add("delegation", SeverityInfo, fmt.Sprintf("subnet is delegated to %v", netAppDelegation), "")
+	}
+
+	// Region
+	vnetID := subnetID[:strings.Index(strings.ToLower(subnetID), "/subnets/")]
+	vnet, err := sdkutils.GetVirtualNetwork(ctx, vnetID)
+	if err != nil {
+		report.add("region", SeverityWarning, fmt.Sprintf("virtual network %v could not be read: %v", vnetName, err), "")
+	} else if vnet.Location == nil || normalizeLocation(*vnet.Location) != normalizeLocation(location) {
+		report.add("region", SeverityError,
+			fmt.Sprintf("virtual network %v is in %v but the account will be created in %v", vnetName, map[bool]string{true: *vnet.Location, false: "an unknown region"}[vnet.Location != nil], location),
+			fmt.Sprintf("deploy the account in the virtual network region or use a virtual network in %v", location),
+		)
+	} else {
+		report.add("region", SeverityInfo, fmt.Sprintf("virtual network is in %v", location), "")
+	}
+
+	// Free IP addresses
+	freeIPs, err := freeSubnetIPs(subnet)
+	if err != nil {
+		report.add("ip-addresses", SeverityWarning, fmt.Sprintf("cannot determine free IP addresses: %v", err), "")
+	} else if freeIPs < plannedVolumes {
+		report.add("ip-addresses", SeverityError,
+			fmt.Sprintf("subnet has %v free IP addresses but %v volumes are planned", freeIPs, plannedVolumes),
+			"use a larger subnet, a /26 or larger is recommended for Azure NetApp Files",
+		)
+	} else {
+		report.add("ip-addresses", SeverityInfo, fmt.Sprintf("subnet has %v free IP addresses for %v planned volumes", freeIPs, plannedVolumes), "")
+	}
+
+	// Standard network features are required for network security groups and user defined routes on the delegated subnet
+	standardReasons := []string{}
+	if subnet.NetworkSecurityGroup != nil {
+		standardReasons = append(standardReasons, "a network security group")
+	}
+	if subnet.RouteTable != nil {
+		standardReasons = append(standardReasons, "a route table")
+	}
+	if len(standardReasons) > 0 {
+		report.add("network-features", SeverityWarning,
+			fmt.Sprintf("subnet has %v attached, which requires Standard network features", strings.Join(standardReasons, " and ")),
+			"make sure Standard network features are registered for the subscription and selected for the volumes",
+		)
+	} else {
+		report.add("network-features", SeverityInfo, "Basic network features are sufficient for this subnet", "")
+	}
+
+	return report, nil
+}
+
+// normalizeLocation turns display names like "East US" into location names like "eastus"
+func normalizeLocation(location string) string {
+	return strings.ToLower(strings.ReplaceAll(location, " ", ""))
+}
+
+// freeSubnetIPs returns the number of IPv4 addresses of a subnet not reserved by Azure nor already in use
+func freeSubnetIPs(subnet network.Subnet) (int, error) {
+
+	prefix := ""
+	if subnet.AddressPrefix != nil {
+		prefix = *subnet.AddressPrefix
+	} else if subnet.AddressPrefixes != nil && len(*subnet.AddressPrefixes) > 0 {
+		prefix = (*subnet.AddressPrefixes)[0]
+	}
+
+	_, ipNet, err := net.ParseCIDR(prefix)
+	if err != nil {
+		return 0, fmt.Errorf("invalid subnet address prefix %v", prefix)
+	}
+
+	ones, bits := ipNet.Mask.Size()
+	if bits != 32 {
+		return 0, fmt.Errorf("only IPv4 address prefixes are supported")
+	}
+
+	used := 0
+	if subnet.IPConfigurations != nil {
+		used = len(*subnet.IPConfigurations)
+	}
+
+	free := 1<<(bits-ones) - azureReservedIPs - used
+	if free < 0 {
+		free = 0
+	}
+
+	return free, nil
+}
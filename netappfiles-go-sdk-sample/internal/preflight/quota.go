@@ -0,0 +1,344 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package preflight
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/models"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/sdkutils"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/uri"
+	"github.com/Azure/azure-sdk-for-go/services/netapp/mgmt/2021-04-01/netapp"
+)
+
+// Limits are the Azure NetApp Files resource limits a deployment is checked against. Defaults come from
+// https://docs.microsoft.com/en-us/azure/azure-netapp-files/azure-netapp-files-resource-limits and can be
+// overridden by a json file when limit increases have been granted to the subscription.
+type Limits struct {
+	AccountsPerRegion   int   `json:"accountsPerRegion"`
+	PoolsPerAccount     int   `json:"poolsPerAccount"`
+	VolumesPerPool      int   `json:"volumesPerPool"`
+	VolumesPerRegion    int   `json:"volumesPerRegion"`
+	RegionalCapacityTiB int64 `json:"regionalCapacityTiB"`
+	MinPoolSizeTiB      int64 `json:"minPoolSizeTiB"`
+	MaxPoolSizeTiB      int64 `json:"maxPoolSizeTiB"`
+	MinVolumeQuotaGiB   int64 `json:"minVolumeQuotaGiB"`
+	MaxVolumeQuotaGiB   int64 `json:"maxVolumeQuotaGiB"`
+}
+
+// quotaName is a resource checked against the quota availability API
+type quotaName struct {
+	name         string
+	resourceType netapp.CheckQuotaNameResourceTypes
+}
+
+// Usage is what a subscription already has deployed in a region
+type Usage struct {
+	Accounts       int
+	Volumes        int
+	ProvisionedTiB int64
+	// Existing accounts, capacity pools and volumes keyed by resourceKey, with the size in bytes of capacity pools
+	Resources map[string]int64
+}
+
+// resourceKey identifies an account, capacity pool or volume by its resource group and names, i.e. its
+// resource id without the subscription, so resources of a spec can be matched with deployed ones
+func resourceKey(resourceGroup string, names ...string) string {
+	return strings.ToLower(strings.Join(append([]string{resourceGroup}, names...), "/"))
+}
+
+// children counts the existing resources directly under a resource, e.g. the capacity pools of an account
+func (u Usage) children(key string) int {
+	count := 0
+	for resource := range u.Resources {
+		if strings.HasPrefix(resource, key+"/") && !strings.Contains(strings.TrimPrefix(resource, key+"/"), "/") {
+			count++
+		}
+	}
+	return count
+}
+
+// DefaultLimits returns the documented default limits
+func DefaultLimits() Limits {
+	return Limits{
+		AccountsPerRegion:   10,
+		PoolsPerAccount:     25,
+		VolumesPerPool:      500,
+		VolumesPerRegion:    500,
+		RegionalCapacityTiB: 25,
+		MinPoolSizeTiB:      sdkutils.MinCapacityPoolSizeBytes / sdkutils.TiBInBytes,
+		MaxPoolSizeTiB:      500,
+		MinVolumeQuotaGiB:   100,
+		MaxVolumeQuotaGiB:   102400,
+	}
+}
+
+// LoadLimits reads a json limits file and merges it over the default limits,
+// only the limits present in the file are overridden. An empty path returns the default limits.
+func LoadLimits(path string) (Limits, error) {
+
+	limits := DefaultLimits()
+	if path == "" {
+		return limits, nil
+	}
+
+	limitsJSON, err := ioutil.ReadFile(path)
+	if err != nil {
+		return Limits{}, fmt.Errorf("cannot read limits file: %v", err)
+	}
+
+	err = json.Unmarshal(limitsJSON, &limits)
+	if err != nil {
+		return Limits{}, fmt.Errorf("cannot parse limits file %v: %v", path, err)
+	}
+
+	return limits, nil
+}
+
+// GetUsage reads the accounts, capacity pools and volumes already deployed in a region
+func GetUsage(ctx context.Context, location string) (Usage, error) {
+
+	usage := Usage{Resources: map[string]int64{}}
+
+	accountIDs, err := sdkutils.ListANFAccountIDs(ctx, location)
+	if err != nil {
+		return Usage{}, err
+	}
+
+	var provisionedBytes int64
+	for _, accountID := range accountIDs {
+		pools, err := sdkutils.ListANFCapacityPools(ctx, accountID)
+		if err != nil {
+			return Usage{}, err
+		}
+
+		resourceGroup := uri.GetResourceGroup(accountID)
+		accountName := uri.GetANFAccount(accountID)
+		usage.Accounts++
+		usage.Resources[resourceKey(resourceGroup, accountName)] = 0
+
+		for _, pool := range pools {
+			var poolSize int64
+			if pool.PoolProperties != nil && pool.Size != nil {
+				poolSize = *pool.Size
+			}
+			provisionedBytes += poolSize
+			usage.Resources[resourceKey(resourceGroup, accountName, uri.GetANFCapacityPool(*pool.ID))] = poolSize
+
+			volumes, err := sdkutils.ListANFVolumes(ctx, *pool.ID)
+			if err != nil {
+				return Usage{}, err
+			}
+			usage.Volumes += len(volumes)
+			for _, volume := range volumes {
+				usage.Resources[resourceKey(resourceGroup, accountName, uri.GetANFCapacityPool(*pool.ID), uri.GetANFVolume(*volume.ID))] = 0
+			}
+		}
+	}
+	usage.ProvisionedTiB = provisionedBytes / sdkutils.TiBInBytes
+
+	return usage, nil
+}
+
+// CheckLimits compares a deployment spec, added to what is already deployed in each region, with the limits.
+// No Azure API is called, usage is keyed by region and regions without usage are considered empty. Resources
+// of the spec that are already deployed are only counted once, with the size of the spec for capacity pools.
+func CheckLimits(spec models.DeploymentSpec, limits Limits, usage map[string]Usage) Report {
+
+	report := Report{}
+
+	type plan struct {
+		accounts int
+		volumes  int
+		sizeTiB  int64
+	}
+	plans := map[string]*plan{}
+
+	for _, account := range spec.Accounts {
+		region := normalizeLocation(accountLocation(spec, account))
+		if plans[region] == nil {
+			plans[region] = &plan{}
+		}
+		regionPlan := plans[region]
+		regionUsage := usage[region]
+
+		accountKey := resourceKey(spec.ResourceGroup, account.Name)
+		if _, exists := regionUsage.Resources[accountKey]; !exists {
+			regionPlan.accounts++
+		}
+
+		pools := regionUsage.children(accountKey)
+		for _, pool := range account.Pools {
+			if _, exists := regionUsage.Resources[resourceKey(spec.ResourceGroup, account.Name, pool.Name)]; !exists {
+				pools++
+			}
+		}
+		if pools > limits.PoolsPerAccount {
+			report.add("pools-per-account", SeverityError,
+				fmt.Sprintf("account %v would have %v capacity pools, the limit is %v", account.Name, pools, limits.PoolsPerAccount),
+				"spread the capacity pools across more accounts",
+			)
+		}
+
+		for _, pool := range account.Pools {
+			// An existing pool is resized to the spec size, only the difference is provisioned
+			poolKey := resourceKey(spec.ResourceGroup, account.Name, pool.Name)
+			existingSize := regionUsage.Resources[poolKey]
+			regionPlan.sizeTiB += pool.SizeTiB - existingSize/sdkutils.TiBInBytes
+
+			if pool.SizeTiB < limits.MinPoolSizeTiB || pool.SizeTiB > limits.MaxPoolSizeTiB {
+				report.add("pool-size", SeverityError,
+					fmt.Sprintf("capacity pool %v/%v is %v TiB, the size must be between %v and %v TiB", account.Name, pool.Name, pool.SizeTiB, limits.MinPoolSizeTiB, limits.MaxPoolSizeTiB),
+					"change the capacity pool size in the deployment spec",
+				)
+			}
+
+			volumes := regionUsage.children(poolKey)
+			for _, volume := range pool.Volumes {
+				if _, exists := regionUsage.Resources[resourceKey(spec.ResourceGroup, account.Name, pool.Name, volume.Name)]; !exists {
+					volumes++
+				}
+			}
+			if volumes > limits.VolumesPerPool {
+				report.add("volumes-per-pool", SeverityError,
+					fmt.Sprintf("capacity pool %v/%v would have %v volumes, the limit is %v", account.Name, pool.Name, volumes, limits.VolumesPerPool),
+					"spread the volumes across more capacity pools",
+				)
+			}
+
+			var allocatedGiB int64
+			for _, volume := range pool.Volumes {
+				if _, exists := regionUsage.Resources[resourceKey(spec.ResourceGroup, account.Name, pool.Name, volume.Name)]; !exists {
+					regionPlan.volumes++
+				}
+				allocatedGiB += volume.QuotaGiB
+
+				if volume.QuotaGiB < limits.MinVolumeQuotaGiB || volume.QuotaGiB > limits.MaxVolumeQuotaGiB {
+					report.add("volume-quota", SeverityError,
+						fmt.Sprintf("volume %v/%v/%v quota is %v GiB, the quota must be between %v and %v GiB", account.Name, pool.Name, volume.Name, volume.QuotaGiB, limits.MinVolumeQuotaGiB, limits.MaxVolumeQuotaGiB),
+						"change the volume quota in the deployment spec",
+					)
+				}
+			}
+
+			if allocatedGiB*sdkutils.GiBInBytes > pool.SizeTiB*sdkutils.TiBInBytes {
+				report.add("pool-allocation", SeverityError,
+					fmt.Sprintf("volumes of capacity pool %v/%v add up to %v GiB, more than the %v TiB pool size", account.Name, pool.Name, allocatedGiB, pool.SizeTiB),
+					"grow the capacity pool or reduce the volume quotas",
+				)
+			}
+		}
+	}
+
+	regions := make([]string, 0, len(plans))
+	for region := range plans {
+		regions = append(regions, region)
+	}
+	sort.Strings(regions)
+
+	for _, region := range regions {
+		regionPlan := plans[region]
+		regionUsage := usage[region]
+
+		checks := []struct {
+			check    string
+			existing int64
+			planned  int64
+			limit    int64
+			unit     string
+			fix      string
+		}{
+			{"accounts", int64(regionUsage.Accounts), int64(regionPlan.accounts), int64(limits.AccountsPerRegion), "accounts", "reuse an existing account or request a limit increase"},
+			{"volumes", int64(regionUsage.Volumes), int64(regionPlan.volumes), int64(limits.VolumesPerRegion), "volumes", "remove unused volumes or request a limit increase"},
+			{"regional-capacity", regionUsage.ProvisionedTiB, regionPlan.sizeTiB, limits.RegionalCapacityTiB, "TiB", "shrink capacity pools or request a regional capacity quota increase through a support request"},
+		}
+
+		for _, c := range checks {
+			if c.existing+c.planned > c.limit {
+				report.add(c.check, SeverityError,
+					fmt.Sprintf("%v would have %v %v (%v existing + %v planned), the limit is %v", region, c.existing+c.planned, c.unit, c.existing, c.planned, c.limit),
+					c.fix,
+				)
+			} else {
+				report.add(c.check, SeverityInfo,
+					fmt.Sprintf("%v would have %v of %v %v", region, c.existing+c.planned, c.limit, c.unit),
+					"",
+				)
+			}
+		}
+	}
+
+	return report
+}
+
+// CheckQuotas reads what is already deployed in each region of a deployment spec, checks the spec against
+// the limits and then asks the NetApp quota availability API about each planned account, pool and volume
+func CheckQuotas(ctx context.Context, spec models.DeploymentSpec, limits Limits) (Report, error) {
+
+	usage := map[string]Usage{}
+	for _, account := range spec.Accounts {
+		region := normalizeLocation(accountLocation(spec, account))
+		if _, found := usage[region]; found {
+			continue
+		}
+
+		regionUsage, err := GetUsage(ctx, region)
+		if err != nil {
+			return Report{}, err
+		}
+		usage[region] = regionUsage
+	}
+
+	report := CheckLimits(spec, limits, usage)
+
+	// The quota API is only available per resource in this API version, subscription wide
+	// quota limits cannot be queried and are covered by the limits table above
+	for _, account := range spec.Accounts {
+		region := accountLocation(spec, account)
+
+		names := []quotaName{
+			{account.Name, netapp.CheckQuotaNameResourceTypesMicrosoftNetAppnetAppAccounts},
+		}
+		for _, pool := range account.Pools {
+			poolName := fmt.Sprintf("%v/%v", account.Name, pool.Name)
+			names = append(names, quotaName{poolName, netapp.CheckQuotaNameResourceTypesMicrosoftNetAppnetAppAccountscapacityPools})
+
+			for _, volume := range pool.Volumes {
+				volumeName := fmt.Sprintf("%v/%v", poolName, volume.Name)
+				names = append(names, quotaName{volumeName, netapp.CheckQuotaNameResourceTypesMicrosoftNetAppnetAppAccountscapacityPoolsvolumes})
+			}
+		}
+
+		for _, n := range names {
+			available, reason, err := sdkutils.CheckANFQuotaAvailability(ctx, region, spec.ResourceGroup, n.name, n.resourceType)
+			if err != nil {
+				report.add("quota-api", SeverityWarning, fmt.Sprintf("quota of %v could not be checked: %v", n.name, err), "")
+				continue
+			}
+			if !available {
+				report.add("quota-api", SeverityError,
+					fmt.Sprintf("quota for %v would be exceeded: %v", n.name, reason),
+					"request a quota increase through a support request",
+				)
+			}
+		}
+	}
+
+	return report, nil
+}
+
+// accountLocation returns the region of an account, which defaults to the spec location
+func accountLocation(spec models.DeploymentSpec, account models.AccountSpec) string {
+	if account.Location != "" {
+		return account.Location
+	}
+	return spec.Location
+}
@@ -0,0 +1,116 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package preflight
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/models"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/sdkutils"
+)
+
+func TestCheckLimitsExistingResources(t *testing.T) {
+
+	spec := models.DeploymentSpec{
+		Location:      "eastus",
+		ResourceGroup: "rg",
+		Accounts: []models.AccountSpec{{
+			Name: "account",
+			Pools: []models.PoolSpec{{
+				Name:    "pool1",
+				SizeTiB: 4,
+				Volumes: []models.VolumeSpec{{Name: "volume1", QuotaGiB: 100}, {Name: "volume2", QuotaGiB: 100}},
+			}},
+		}},
+	}
+
+	limits := DefaultLimits()
+	limits.AccountsPerRegion = 1
+	limits.PoolsPerAccount = 2
+	limits.VolumesPerPool = 2
+	limits.VolumesPerRegion = 3
+	limits.RegionalCapacityTiB = 8
+
+	tests := []struct {
+		name       string
+		resources  map[string]int64
+		wantErrors []string
+	}{
+		{
+			name: "spec already deployed",
+			resources: map[string]int64{
+				"rg/account":               0,
+				"rg/account/pool1":         4 * sdkutils.TiBInBytes,
+				"rg/account/pool1/volume1": 0,
+				"rg/account/pool1/volume2": 0,
+			},
+		},
+		{
+			name: "pool already deployed with a smaller size",
+			resources: map[string]int64{
+				"rg/account":               0,
+				"rg/account/pool1":         4 * sdkutils.TiBInBytes,
+				"rg/account/pool2":         4 * sdkutils.TiBInBytes,
+				"rg/account/pool1/volume1": 0,
+			},
+		},
+		{
+			name: "other resources of the account count",
+			resources: map[string]int64{
+				"rg/account":               0,
+				"rg/account/pool1":         4 * sdkutils.TiBInBytes,
+				"rg/account/pool2":         4 * sdkutils.TiBInBytes,
+				"rg/account/pool3":         sdkutils.TiBInBytes,
+				"rg/account/pool1/volume3": 0,
+			},
+			wantErrors: []string{"pools-per-account", "volumes-per-pool", "regional-capacity"},
+		},
+		{
+			name: "same account name in another resource group",
+			resources: map[string]int64{
+				"other/account":               0,
+				"other/account/pool1":         4 * sdkutils.TiBInBytes,
+				"other/account/pool1/volume1": 0,
+				"other/account/pool1/volume2": 0,
+			},
+			wantErrors: []string{"accounts", "volumes"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			usage := Usage{Resources: test.resources}
+			for key, size := range test.resources {
+				switch strings.Count(key, "/") {
+				case 1:
+					usage.Accounts++
+				case 2:
+					usage.ProvisionedTiB += size / sdkutils.TiBInBytes
+				case 3:
+					usage.Volumes++
+				}
+			}
+
+			report := CheckLimits(spec, limits, map[string]Usage{"eastus": usage})
+
+			errors := map[string]bool{}
+			for _, finding := range report.Findings {
+				if finding.Severity == SeverityError {
+					errors[finding.Check] = true
+				}
+			}
+			if len(errors) != len(test.wantErrors) {
+				t.Errorf("got errors %v, want %v", report.Findings, test.wantErrors)
+			}
+			for _, check := range test.wantErrors {
+				if !errors[check] {
+					t.Errorf("missing %v error, got %v", check, report.Findings)
+				}
+			}
+		})
+	}
+}
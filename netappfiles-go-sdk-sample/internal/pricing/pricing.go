@@ -0,0 +1,111 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// This package provides a price table of Azure NetApp Files capacity
+// per service level and region, used to estimate costs and savings.
+// Default prices are approximate list prices and can be overridden
+// by a local json file, please refer to https://azure.microsoft.com/pricing/details/netapp/
+// for the current prices of each region.
+
+package pricing
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+)
+
+const (
+	// DefaultRegion is the price table entry used for regions without specific prices
+	DefaultRegion string = "default"
+	// Backup is the price table entry for backup storage
+	Backup string = "backup"
+	// Replication is the price table entry for cross region replication of a volume
+	Replication string = "replication"
+)
+
+// Catalog holds prices per GiB per month, by region and item, items are service levels for
+// provisioned capacity plus backup storage and replicated volume capacity
+type Catalog struct {
+	Currency string                        `json:"currency"`
+	Regions  map[string]map[string]float64 `json:"regions"`
+}
+
+// DefaultCatalog returns the built-in catalog with approximate USD list prices
+func DefaultCatalog() Catalog {
+	return Catalog{
+		Currency: "USD",
+		Regions: map[string]map[string]float64{
+			DefaultRegion: {
+				"standard":  0.14746,
+				"premium":   0.29493,
+				"ultra":     0.39322,
+				Backup:      0.05,
+				Replication: 0.11,
+			},
+		},
+	}
+}
+
+// LoadCatalog reads a json price catalog and merges it over the default one,
+// prices found in the file replace the default ones, everything else is kept
+func LoadCatalog(path string) (Catalog, error) {
+
+	catalog := DefaultCatalog()
+
+	if path == "" {
+		return catalog, nil
+	}
+
+	catalogJSON, err := ioutil.ReadFile(path)
+	if err != nil {
+		return Catalog{}, fmt.Errorf("failed to read price catalog: %v", err)
+	}
+
+	var overrides Catalog
+	err = json.Unmarshal(catalogJSON, &overrides)
+	if err != nil {
+		return Catalog{}, fmt.Errorf("failed to parse price catalog: %v", err)
+	}
+
+	if overrides.Currency != "" {
+		catalog.Currency = overrides.Currency
+	}
+
+	for region, prices := range overrides.Regions {
+		region = strings.ToLower(region)
+		if _, found := catalog.Regions[region]; !found {
+			catalog.Regions[region] = map[string]float64{}
+		}
+		for item, price := range prices {
+			catalog.Regions[region][strings.ToLower(item)] = price
+		}
+	}
+
+	return catalog, nil
+}
+
+// Price returns the monthly price per GiB of an item (e.g. a service level) within a region,
+// falling back to the default region when the region has no price for it
+func (c Catalog) Price(region, item string) (float64, error) {
+
+	region = strings.ToLower(region)
+	item = strings.ToLower(item)
+
+	if prices, found := c.Regions[region]; found {
+		if price, found := prices[item]; found {
+			return price, nil
+		}
+	}
+
+	if prices, found := c.Regions[DefaultRegion]; found {
+		if price, found := prices[item]; found {
+			return price, nil
+		}
+	}
+
+	return 0, fmt.Errorf("no price found for %v in region %v", item, region)
+}
@@ -0,0 +1,185 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package progress
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/logging"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/uri"
+	"golang.org/x/term"
+)
+
+const (
+	// clearLine moves the cursor to the beginning of the line and erases it
+	clearLine    string = "\r\033[K"
+	defaultWidth int    = 120
+)
+
+// LineReporter writes one structured log record per progress event, for output that is not a terminal
+type LineReporter struct{}
+
+// Display keeps a live line on a terminal with the operations in progress, refreshed every second,
+// and writes a log line when one of them completes
+type Display struct {
+	out    *os.File
+	mu     sync.Mutex
+	active map[string]Event
+	order  []string
+	drawn  bool
+}
+
+// NewLineReporter creates a line based reporter
+func NewLineReporter() LineReporter {
+	return LineReporter{}
+}
+
+// Report writes the event as a log record, a failed operation is logged as a warning since its caller reports the error
+func (LineReporter) Report(event Event) {
+
+	fields := []logging.Field{
+		logging.Operation(event.Operation),
+		logging.String("resourceType", event.ResourceType),
+		logging.ResourceID(event.ResourceID),
+		logging.Duration(event.Elapsed),
+	}
+
+	if event.Done {
+		if event.Err != nil {
+			logging.Warn("operation failed", append(fields, logging.Err(event.Err))...)
+			return
+		}
+		logging.Info("operation completed", fields...)
+		return
+	}
+
+	fields = append(fields, logging.Any("poll", event.Poll), logging.String("provisioningState", event.ProvisioningState))
+	if remaining, found := event.Remaining(); found {
+		fields = append(fields, logging.Any("remaining", remaining))
+	}
+	logging.Info("operation in progress", fields...)
+}
+
+// NewDisplay creates a live display on a terminal
+func NewDisplay(out *os.File) *Display {
+
+	display := &Display{out: out, active: map[string]Event{}}
+
+	go func() {
+		for range time.NewTicker(time.Second).C {
+			display.mu.Lock()
+			display.draw()
+			display.mu.Unlock()
+		}
+	}()
+
+	return display
+}
+
+// Report updates the live line, completed operations are removed from it and logged
+func (d *Display) Report(event Event) {
+
+	id := event.Operation + " " + event.ResourceID
+
+	d.mu.Lock()
+	if event.Done {
+		delete(d.active, id)
+		for i, other := range d.order {
+			if other == id {
+				d.order = append(d.order[:i], d.order[i+1:]...)
+				break
+			}
+		}
+	} else {
+		if _, found := d.active[id]; !found {
+			d.order = append(d.order, id)
+		}
+		d.active[id] = event
+	}
+	d.draw()
+	d.mu.Unlock()
+
+	if event.Done {
+		logging.Info(describe(event))
+	}
+}
+
+// Write clears the live line before writing p, e.g. a log line, and draws it again afterwards
+func (d *Display) Write(p []byte) (int, error) {
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	fmt.Fprint(d.out, clearLine)
+	n, err := d.out.Write(p)
+	d.draw()
+
+	return n, err
+}
+
+// draw renders the operations in progress on a single line, truncated to the terminal width
+func (d *Display) draw() {
+
+	if len(d.order) == 0 {
+		if d.drawn {
+			fmt.Fprint(d.out, clearLine)
+			d.drawn = false
+		}
+		return
+	}
+
+	parts := []string{}
+	for _, id := range d.order {
+		event := d.active[id]
+		event.Elapsed = time.Since(event.StartedAt)
+
+		part := fmt.Sprintf("%v %v %v", uri.GetResourceName(event.ResourceID), event.ProvisioningState, event.Elapsed.Round(time.Second))
+		if remaining, found := event.Remaining(); found {
+			part += fmt.Sprintf(" (~%v left)", remaining.Round(time.Second))
+		}
+		parts = append(parts, strings.Join(strings.Fields(part), " "))
+	}
+
+	width, _, err := term.GetSize(int(d.out.Fd()))
+	if err != nil || width <= 0 {
+		width = defaultWidth
+	}
+
+	line := strings.Join(parts, " | ")
+	if len(line) > width-1 {
+		line = line[:width-1]
+	}
+
+	fmt.Fprint(d.out, clearLine+line)
+	d.drawn = true
+}
+
+// describe returns a one line description of an event
+func describe(event Event) string {
+
+	operation := fmt.Sprintf("%v %v %v", event.Operation, event.ResourceType, uri.GetResourceName(event.ResourceID))
+
+	if event.Done {
+		if event.Err != nil {
+			return fmt.Sprintf("%v failed after %v: %v", operation, event.Elapsed.Round(time.Second), event.Err)
+		}
+		return fmt.Sprintf("%v completed in %v", operation, event.Elapsed.Round(time.Second))
+	}
+
+	description := fmt.Sprintf("%v: poll %v, %v elapsed", operation, event.Poll, event.Elapsed.Round(time.Second))
+	if event.ProvisioningState != "" {
+		description += fmt.Sprintf(", %v", event.ProvisioningState)
+	}
+	if remaining, found := event.Remaining(); found {
+		description += fmt.Sprintf(", about %v remaining", remaining.Round(time.Second))
+	}
+
+	return description
+}
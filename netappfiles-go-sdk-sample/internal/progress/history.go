@@ -0,0 +1,105 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package progress
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultHistoryPath is the file used when no other history location is provided
+	DefaultHistoryPath string = "anf-durations.json"
+
+	// maxSamples is the number of durations kept for each kind of operation
+	maxSamples int = 20
+)
+
+// History keeps the durations, in seconds, of the last operations of each kind, e.g. volume creations
+type History struct {
+	path      string
+	mu        sync.Mutex
+	Durations map[string][]float64 `json:"durations"`
+}
+
+// LoadHistory reads a history from disk, a missing file results in an empty history
+func LoadHistory(path string) (*History, error) {
+
+	history := &History{path: path, Durations: map[string][]float64{}}
+
+	historyJSON, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return history, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot read duration history: %v", err)
+	}
+
+	err = json.Unmarshal(historyJSON, history)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse duration history %v: %v", path, err)
+	}
+	if history.Durations == nil {
+		history.Durations = map[string][]float64{}
+	}
+
+	return history, nil
+}
+
+// Estimate returns the median duration of previous operations of the same kind
+func (h *History) Estimate(operation, resourceType string) (time.Duration, bool) {
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	samples := append([]float64{}, h.Durations[key(operation, resourceType)]...)
+	if len(samples) == 0 {
+		return 0, false
+	}
+	sort.Float64s(samples)
+
+	median := samples[len(samples)/2]
+	if len(samples)%2 == 0 {
+		median = (samples[len(samples)/2-1] + samples[len(samples)/2]) / 2
+	}
+
+	return time.Duration(median * float64(time.Second)), true
+}
+
+// Record adds the duration of a completed operation, only the most recent ones are kept, and saves the history
+func (h *History) Record(operation, resourceType string, duration time.Duration) error {
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	k := key(operation, resourceType)
+	samples := append(h.Durations[k], duration.Seconds())
+	if len(samples) > maxSamples {
+		samples = samples[len(samples)-maxSamples:]
+	}
+	h.Durations[k] = samples
+
+	historyJSON, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return fmt.Errorf("cannot serialize duration history: %v", err)
+	}
+
+	err = ioutil.WriteFile(h.path, historyJSON, 0644)
+	if err != nil {
+		return fmt.Errorf("cannot write duration history: %v", err)
+	}
+
+	return nil
+}
+
+func key(operation, resourceType string) string {
+	return fmt.Sprintf("%v/%v", resourceType, operation)
+}
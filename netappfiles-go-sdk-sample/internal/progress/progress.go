@@ -0,0 +1,180 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// This package reports the progress of long-running operations, e.g.
+// volume creations, while they are polled: number of polls, elapsed
+// time, current provisioning state and an estimate of the remaining
+// time based on the durations of previous operations of the same kind,
+// kept in a local history file. Progress renders as a live line on a
+// terminal and as log records otherwise.
+
+package progress
+
+import (
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/state"
+	"golang.org/x/term"
+)
+
+// Event is the state of an operation after a poll, Estimate is the expected total duration, 0 when unknown
+type Event struct {
+	Operation         string
+	ResourceID        string
+	ResourceType      string
+	StartedAt         time.Time
+	Poll              int
+	Elapsed           time.Duration
+	ProvisioningState string
+	Estimate          time.Duration
+	Done              bool
+	Err               error
+}
+
+// Reporter renders progress events
+type Reporter interface {
+	Report(event Event)
+}
+
+// Monitor hands out trackers for operations, reporting their events and recording their durations in the history
+type Monitor struct {
+	reporter Reporter
+	history  *History
+}
+
+// Tracker follows a single operation, a nil tracker ignores every call
+type Tracker struct {
+	monitor *Monitor
+	event   Event
+}
+
+var (
+	defaultMonitor *Monitor
+	defaultMu      sync.Mutex
+)
+
+// NewMonitor creates a monitor, history can be nil when durations are neither estimated nor recorded
+func NewMonitor(reporter Reporter, history *History) *Monitor {
+	return &Monitor{reporter: reporter, history: history}
+}
+
+// SetDefault sets the monitor used by Start, progress is not reported when it is nil
+func SetDefault(monitor *Monitor) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultMonitor = monitor
+}
+
+// Enable reports progress on stderr with estimates from the given history file, as a live display when live is set
+// and stderr is a terminal, in which case log output goes through the display so log lines do not overwrite the
+// progress line, and as log records otherwise
+func Enable(historyPath string, live bool) error {
+
+	history, err := LoadHistory(historyPath)
+	if err != nil {
+		return err
+	}
+
+	var reporter Reporter = NewLineReporter()
+	if live && term.IsTerminal(int(os.Stderr.Fd())) {
+		display := NewDisplay(os.Stderr)
+		log.SetOutput(display)
+		reporter = display
+	}
+
+	SetDefault(NewMonitor(reporter, history))
+
+	return nil
+}
+
+// Start begins tracking an operation with the default monitor, startedAt is in the past when polling resumes
+// an operation started by another process. The resource type is taken from the resource id when empty.
+func Start(operation, resourceID, resourceType string, startedAt time.Time) *Tracker {
+	defaultMu.Lock()
+	monitor := defaultMonitor
+	defaultMu.Unlock()
+	return monitor.Start(operation, resourceID, resourceType, startedAt)
+}
+
+// Start begins tracking an operation
+func (m *Monitor) Start(operation, resourceID, resourceType string, startedAt time.Time) *Tracker {
+
+	if m == nil {
+		return nil
+	}
+
+	if resourceType == "" {
+		resourceType = typeFromID(resourceID)
+	}
+
+	event := Event{
+		Operation:    operation,
+		ResourceID:   resourceID,
+		ResourceType: resourceType,
+		StartedAt:    startedAt,
+	}
+	if m.history != nil {
+		event.Estimate, _ = m.history.Estimate(operation, resourceType)
+	}
+
+	return &Tracker{monitor: m, event: event}
+}
+
+// Poll reports a poll of the operation, provisioningState is empty when it is not known
+func (t *Tracker) Poll(provisioningState string) {
+
+	if t == nil {
+		return
+	}
+
+	t.event.Poll++
+	t.event.Elapsed = time.Since(t.event.StartedAt)
+	t.event.ProvisioningState = provisioningState
+	t.monitor.reporter.Report(t.event)
+}
+
+// Done reports the end of the operation, the duration of a successful operation is recorded in the history
+func (t *Tracker) Done(err error) {
+
+	if t == nil {
+		return
+	}
+
+	t.event.Elapsed = time.Since(t.event.StartedAt)
+	t.event.Done = true
+	t.event.Err = err
+	t.monitor.reporter.Report(t.event)
+
+	if err == nil && t.monitor.history != nil {
+		// A history that cannot be saved only degrades future estimates
+		_ = t.monitor.history.Record(t.event.Operation, t.event.ResourceType, t.event.Elapsed)
+	}
+}
+
+// Remaining estimates the time left before the operation completes, false when there is no estimate
+// or the operation already took longer than expected
+func (e Event) Remaining() (time.Duration, bool) {
+	if e.Estimate == 0 || e.Elapsed > e.Estimate {
+		return 0, false
+	}
+	return e.Estimate - e.Elapsed, true
+}
+
+// typeFromID returns the journal type of a resource id, e.g. volume, so operations tracked by sdkutils and by a
+// resumed handle share their history, or the type of the last resource of the id for other resources, e.g. backups
+func typeFromID(resourceID string) string {
+	if resourceType := state.TypeFromID(resourceID); resourceType != "" {
+		return resourceType
+	}
+	segments := strings.Split(strings.Trim(resourceID, "/"), "/")
+	if len(segments) < 2 {
+		return ""
+	}
+	return segments[len(segments)-2]
+}
@@ -0,0 +1,237 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// This package calculates volume throughput limits. Under auto QoS
+// the limit is given by the service level and the volume quota, under
+// manual QoS the pool throughput is allocated to its volumes from
+// weights or explicit targets.
+
+package qos
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/sdkutils"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/uri"
+	"github.com/Azure/azure-sdk-for-go/services/netapp/mgmt/2021-04-01/netapp"
+)
+
+const (
+	// MinVolumeThroughputMibps is the lowest throughput that can be assigned to a volume under manual QoS
+	MinVolumeThroughputMibps float64 = 1
+)
+
+var (
+	// ThroughputPerTiB is the throughput in MiB/s each TiB provides for each service level
+	ThroughputPerTiB = map[netapp.ServiceLevel]float64{
+		netapp.ServiceLevelStandard: 16,
+		netapp.ServiceLevelPremium:  64,
+		netapp.ServiceLevelUltra:    128,
+	}
+)
+
+// ThroughputMibps returns the throughput in MiB/s provided by a size in bytes at a service level,
+// this is a volume limit under auto QoS given its quota, or the total of a manual QoS pool given its size
+func ThroughputMibps(serviceLevel netapp.ServiceLevel, sizeBytes int64) (float64, error) {
+
+	rate, found := ThroughputPerTiB[serviceLevel]
+	if !found {
+		return 0, fmt.Errorf("invalid service level, supported service levels are: %v", netapp.PossibleServiceLevelValues())
+	}
+
+	return float64(sizeBytes) / float64(sdkutils.TiBInBytes) * rate, nil
+}
+
+// VolumeThroughput is the throughput limit of a volume
+type VolumeThroughput struct {
+	VolumeID        string
+	QuotaBytes      int64
+	ThroughputMibps float64
+}
+
+// GetVolumeThroughputs returns the throughput limit of every volume of a pool, calculated from
+// service level and quota under auto QoS or as currently assigned under manual QoS
+func GetVolumeThroughputs(ctx context.Context, poolID string) (netapp.CapacityPool, []VolumeThroughput, error) {
+
+	pool, err := sdkutils.GetANFCapacityPoolByID(ctx, poolID)
+	if err != nil {
+		return netapp.CapacityPool{}, nil, err
+	}
+
+	if pool.PoolProperties == nil {
+		return netapp.CapacityPool{}, nil, fmt.Errorf("capacity pool %v has no properties", poolID)
+	}
+
+	volumes, err := sdkutils.ListANFVolumes(ctx, poolID)
+	if err != nil {
+		return netapp.CapacityPool{}, nil, err
+	}
+
+	throughputs := []VolumeThroughput{}
+	for _, volume := range volumes {
+		if volume.VolumeProperties == nil || volume.UsageThreshold == nil {
+			continue
+		}
+
+		throughput := VolumeThroughput{
+			VolumeID:   *volume.ID,
+			QuotaBytes: *volume.UsageThreshold,
+		}
+
+		if pool.QosType == netapp.QosTypeManual {
+			if volume.ThroughputMibps != nil {
+				throughput.ThroughputMibps = *volume.ThroughputMibps
+			}
+		} else {
+			throughput.ThroughputMibps, err = ThroughputMibps(pool.ServiceLevel, *volume.UsageThreshold)
+			if err != nil {
+				return netapp.CapacityPool{}, nil, err
+			}
+		}
+
+		throughputs = append(throughputs, throughput)
+	}
+
+	return pool, throughputs, nil
+}
+
+// Request describes how much of a manual QoS pool throughput a volume should get, either
+// an explicit target in MiB/s or a weight to share what is left after all targets are met
+type Request struct {
+	VolumeID    string
+	Weight      float64
+	TargetMibps float64
+}
+
+// AvailableThroughputMibps returns the throughput of a manual QoS pool that can be allocated to the requested volumes,
+// that is the pool throughput minus the throughput of the volumes of the pool which are not part of the requests
+func AvailableThroughputMibps(poolThroughputMibps float64, volumes []VolumeThroughput, requests []Request) float64 {
+
+	requested := map[string]bool{}
+	for _, request := range requests {
+		requested[strings.ToLower(request.VolumeID)] = true
+	}
+
+	available := poolThroughputMibps
+	for _, volume := range volumes {
+		if !requested[strings.ToLower(volume.VolumeID)] {
+			available -= volume.ThroughputMibps
+		}
+	}
+
+	return available
+}
+
+// Allocate splits the available throughput, see AvailableThroughputMibps, between volumes. Targets are assigned
+// first and must fit within it, the remaining throughput is shared between weighted volumes proportionally.
+func Allocate(availableMibps float64, requests []Request) (map[string]float64, error) {
+
+	allocation := map[string]float64{}
+	var targets, weights float64
+
+	for _, request := range requests {
+		if _, found := allocation[request.VolumeID]; found {
+			return nil, fmt.Errorf("volume %v requested more than once", uri.GetANFVolume(request.VolumeID))
+		}
+
+		switch {
+		case request.TargetMibps > 0:
+			targets += request.TargetMibps
+			allocation[request.VolumeID] = request.TargetMibps
+		case request.Weight > 0:
+			weights += request.Weight
+			allocation[request.VolumeID] = 0
+		default:
+			return nil, fmt.Errorf("volume %v needs either a target or a weight greater than zero", uri.GetANFVolume(request.VolumeID))
+		}
+	}
+
+	if targets > availableMibps {
+		return nil, fmt.Errorf("targets add up to %.1f MiB/s which exceeds the available throughput of %.1f MiB/s", targets, availableMibps)
+	}
+
+	remaining := availableMibps - targets
+	for _, request := range requests {
+		if request.TargetMibps > 0 {
+			continue
+		}
+		allocation[request.VolumeID] = remaining * request.Weight / weights
+	}
+
+	for volumeID, throughput := range allocation {
+		if throughput < MinVolumeThroughputMibps {
+			return nil, fmt.Errorf("volume %v would get %.2f MiB/s, the minimum is %v MiB/s", uri.GetANFVolume(volumeID), throughput, MinVolumeThroughputMibps)
+		}
+	}
+
+	return allocation, nil
+}
+
+// ApplyAllocation sets the throughput of each volume of a manual QoS pool, after checking the total stays within the pool.
+// Volumes that are reduced are patched first so the pool never exceeds its throughput during the changes.
+func ApplyAllocation(ctx context.Context, poolID string, allocation map[string]float64) error {
+
+	pool, current, err := GetVolumeThroughputs(ctx, poolID)
+	if err != nil {
+		return err
+	}
+
+	if pool.QosType != netapp.QosTypeManual {
+		return fmt.Errorf("capacity pool %v does not use manual qos", uri.GetANFCapacityPool(poolID))
+	}
+
+	if pool.Size == nil {
+		return fmt.Errorf("cannot determine capacity pool size")
+	}
+
+	poolThroughput, err := ThroughputMibps(pool.ServiceLevel, *pool.Size)
+	if err != nil {
+		return err
+	}
+
+	// Resource ids are compared case-insensitively, volumes not part of the allocation keep their current throughput
+	requested := map[string]float64{}
+	for volumeID, throughput := range allocation {
+		requested[strings.ToLower(volumeID)] = throughput
+	}
+
+	var total float64
+	currentThroughput := map[string]float64{}
+	for _, volume := range current {
+		currentThroughput[strings.ToLower(volume.VolumeID)] = volume.ThroughputMibps
+		if throughput, found := requested[strings.ToLower(volume.VolumeID)]; found {
+			total += throughput
+		} else {
+			total += volume.ThroughputMibps
+		}
+	}
+
+	for volumeID := range allocation {
+		if _, found := currentThroughput[strings.ToLower(volumeID)]; !found {
+			return fmt.Errorf("volume %v is not in capacity pool %v", volumeID, uri.GetANFCapacityPool(poolID))
+		}
+	}
+
+	if total > poolThroughput {
+		return fmt.Errorf("volumes would add up to %.1f MiB/s which exceeds the pool throughput of %.1f MiB/s", total, poolThroughput)
+	}
+
+	for _, decreasing := range []bool{true, false} {
+		for volumeID, throughput := range allocation {
+			currentMibps := currentThroughput[strings.ToLower(volumeID)]
+			if (throughput < currentMibps) != decreasing || throughput == currentMibps {
+				continue
+			}
+			_, err = sdkutils.SetANFVolumeThroughput(ctx, volumeID, throughput)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
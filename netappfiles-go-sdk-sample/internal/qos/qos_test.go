@@ -0,0 +1,131 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package qos
+
+import (
+	"math"
+	"testing"
+)
+
+const (
+	poolPrefix = "/subscriptions/s/resourceGroups/rg/providers/Microsoft.NetApp/netAppAccounts/account/capacityPools/pool/volumes/"
+)
+
+func TestAllocate(t *testing.T) {
+
+	tests := []struct {
+		name      string
+		available float64
+		requests  []Request
+		want      map[string]float64
+		wantErr   bool
+	}{
+		{
+			name:      "weights share the available throughput",
+			available: 100,
+			requests:  []Request{{VolumeID: "a", Weight: 1}, {VolumeID: "b", Weight: 3}},
+			want:      map[string]float64{"a": 25, "b": 75},
+		},
+		{
+			name:      "targets are assigned before weights",
+			available: 100,
+			requests:  []Request{{VolumeID: "a", TargetMibps: 40}, {VolumeID: "b", Weight: 1}, {VolumeID: "c", Weight: 1}},
+			want:      map[string]float64{"a": 40, "b": 30, "c": 30},
+		},
+		{
+			name:      "targets only",
+			available: 100,
+			requests:  []Request{{VolumeID: "a", TargetMibps: 60}, {VolumeID: "b", TargetMibps: 40}},
+			want:      map[string]float64{"a": 60, "b": 40},
+		},
+		{
+			name:      "targets exceeding the available throughput",
+			available: 50,
+			requests:  []Request{{VolumeID: "a", TargetMibps: 40}, {VolumeID: "b", TargetMibps: 20}},
+			wantErr:   true,
+		},
+		{
+			name:      "weighted volume left below the minimum",
+			available: 100,
+			requests:  []Request{{VolumeID: "a", TargetMibps: 99.5}, {VolumeID: "b", Weight: 1}},
+			wantErr:   true,
+		},
+		{
+			name:      "volume requested twice",
+			available: 100,
+			requests:  []Request{{VolumeID: "a", Weight: 1}, {VolumeID: "a", TargetMibps: 10}},
+			wantErr:   true,
+		},
+		{
+			name:      "volume without target or weight",
+			available: 100,
+			requests:  []Request{{VolumeID: "a"}},
+			wantErr:   true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := Allocate(test.available, test.requests)
+			if test.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got allocation %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(got) != len(test.want) {
+				t.Fatalf("got allocation %v, want %v", got, test.want)
+			}
+			for volumeID, want := range test.want {
+				if math.Abs(got[volumeID]-want) > 1e-9 {
+					t.Errorf("volume %v got %v MiB/s, want %v MiB/s", volumeID, got[volumeID], want)
+				}
+			}
+		})
+	}
+}
+
+func TestAvailableThroughputMibps(t *testing.T) {
+
+	volumes := []VolumeThroughput{
+		{VolumeID: poolPrefix + "vol1", ThroughputMibps: 20},
+		{VolumeID: poolPrefix + "vol2", ThroughputMibps: 30},
+		{VolumeID: poolPrefix + "other", ThroughputMibps: 25},
+	}
+
+	tests := []struct {
+		name     string
+		requests []Request
+		want     float64
+	}{
+		{
+			name:     "other volumes are subtracted",
+			requests: []Request{{VolumeID: poolPrefix + "vol1"}, {VolumeID: poolPrefix + "vol2"}},
+			want:     75,
+		},
+		{
+			name:     "ids are compared case-insensitively",
+			requests: []Request{{VolumeID: poolPrefix + "VOL1"}},
+			want:     45,
+		},
+		{
+			name:     "every volume requested",
+			requests: []Request{{VolumeID: poolPrefix + "vol1"}, {VolumeID: poolPrefix + "vol2"}, {VolumeID: poolPrefix + "other"}},
+			want:     100,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := AvailableThroughputMibps(100, volumes, test.requests); math.Abs(got-test.want) > 1e-9 {
+				t.Errorf("got %v MiB/s, want %v MiB/s", got, test.want)
+			}
+		})
+	}
+}
@@ -12,15 +12,22 @@ package sdkutils
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"strings"
 	"time"
 
 	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/iam"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/logging"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/progress"
 	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/uri"
 	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/utils"
 
+	"github.com/Azure/azure-sdk-for-go/profiles/latest/network/mgmt/network"
 	"github.com/Azure/azure-sdk-for-go/profiles/latest/resources/mgmt/resources"
 	"github.com/Azure/azure-sdk-for-go/services/netapp/mgmt/2021-04-01/netapp"
+	"github.com/Azure/azure-sdk-for-go/services/preview/monitor/mgmt/2019-06-01/insights"
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/azure"
 	"github.com/Azure/go-autorest/autorest/to"
 )
 
@@ -29,6 +36,28 @@ const (
 	nfsv3     = "NFSv3"
 	nfsv41    = "NFSv4.1"
 	cifs      = "CIFS"
+
+	volumeConsumedSizeMetric    = "VolumeLogicalSize"
+	volumeReadThroughputMetric  = "ReadThroughput"
+	volumeWriteThroughputMetric = "WriteThroughput"
+
+	netAppVolumesDelegation = "Microsoft.NetApp/volumes"
+
+	// NetAppAPIVersion is the Microsoft.NetApp API version of the netapp package, used with the generic resources API
+	NetAppAPIVersion = "2021-04-01"
+
+	// OwnershipTagName is the tag set on resource groups created by this sample, groups without it are never deleted
+	OwnershipTagName  = "CreatedBy"
+	ownershipTagValue = userAgent
+
+	// GiBInBytes is the size of one gibibyte (GiB) in bytes
+	GiBInBytes int64 = 1073741824
+	// TiBInBytes is the size of one tebibyte (TiB) in bytes
+	TiBInBytes int64 = 1099511627776
+	// MinCapacityPoolSizeBytes is the minimum size of a capacity pool (4TiB)
+	MinCapacityPoolSizeBytes int64 = 4 * TiBInBytes
+	// CapacityPoolSizeIncrementBytes is the increment a capacity pool can be resized by (1TiB)
+	CapacityPoolSizeIncrementBytes int64 = TiBInBytes
 )
 
 var (
@@ -53,6 +82,36 @@ func validateANFServiceLevel(serviceLevel string) (validatedServiceLevel netapp.
 	return svcLevel, nil
 }
 
+func validateANFQosType(qosType string) (validatedQosType netapp.QosType, err error) {
+
+	var qos netapp.QosType
+
+	switch strings.ToLower(qosType) {
+	case "auto":
+		qos = netapp.QosTypeAuto
+	case "manual":
+		qos = netapp.QosTypeManual
+	default:
+		return "", fmt.Errorf("invalid qos type, supported qos types are: %v", netapp.PossibleQosTypeValues())
+	}
+
+	return qos, nil
+}
+
+// getOperationsClient returns a client polling long-running operations of any resource provider
+func getOperationsClient() (autorest.Client, error) {
+
+	authorizer, _, err := iam.GetAuthorizer()
+	if err != nil {
+		return autorest.Client{}, err
+	}
+
+	client := autorest.NewClientWithUserAgent(userAgent)
+	client.Authorizer = authorizer
+
+	return client, nil
+}
+
 func getResourcesClient() (resources.Client, error) {
 
 	authorizer, subscriptionID, err := iam.GetAuthorizer()
@@ -67,6 +126,34 @@ func getResourcesClient() (resources.Client, error) {
 	return client, nil
 }
 
+func getGroupsClient() (resources.GroupsClient, error) {
+
+	authorizer, subscriptionID, err := iam.GetAuthorizer()
+	if err != nil {
+		return resources.GroupsClient{}, err
+	}
+
+	client := resources.NewGroupsClient(subscriptionID)
+	client.Authorizer = authorizer
+	client.AddToUserAgent(userAgent)
+
+	return client, nil
+}
+
+func getNetAppResourceClient() (netapp.ResourceClient, error) {
+
+	authorizer, subscriptionID, err := iam.GetAuthorizer()
+	if err != nil {
+		return netapp.ResourceClient{}, err
+	}
+
+	client := netapp.NewResourceClient(subscriptionID)
+	client.Authorizer = authorizer
+	client.AddToUserAgent(userAgent)
+
+	return client, nil
+}
+
 func getAccountsClient() (netapp.AccountsClient, error) {
 
 	authorizer, subscriptionID, err := iam.GetAuthorizer()
@@ -137,6 +224,104 @@ func getSnapshotPoliciesClient() (netapp.SnapshotPoliciesClient, error) {
 	return client, nil
 }
 
+func getBackupPoliciesClient() (netapp.BackupPoliciesClient, error) {
+
+	authorizer, subscriptionID, err := iam.GetAuthorizer()
+	if err != nil {
+		return netapp.BackupPoliciesClient{}, err
+	}
+
+	client := netapp.NewBackupPoliciesClient(subscriptionID)
+	client.Authorizer = authorizer
+	client.AddToUserAgent(userAgent)
+
+	return client, nil
+}
+
+func getBackupsClient() (netapp.BackupsClient, error) {
+
+	authorizer, subscriptionID, err := iam.GetAuthorizer()
+	if err != nil {
+		return netapp.BackupsClient{}, err
+	}
+
+	client := netapp.NewBackupsClient(subscriptionID)
+	client.Authorizer = authorizer
+	client.AddToUserAgent(userAgent)
+
+	return client, nil
+}
+
+func getAccountBackupsClient() (netapp.AccountBackupsClient, error) {
+
+	authorizer, subscriptionID, err := iam.GetAuthorizer()
+	if err != nil {
+		return netapp.AccountBackupsClient{}, err
+	}
+
+	client := netapp.NewAccountBackupsClient(subscriptionID)
+	client.Authorizer = authorizer
+	client.AddToUserAgent(userAgent)
+
+	return client, nil
+}
+
+func getVaultsClient() (netapp.VaultsClient, error) {
+
+	authorizer, subscriptionID, err := iam.GetAuthorizer()
+	if err != nil {
+		return netapp.VaultsClient{}, err
+	}
+
+	client := netapp.NewVaultsClient(subscriptionID)
+	client.Authorizer = authorizer
+	client.AddToUserAgent(userAgent)
+
+	return client, nil
+}
+
+func getMetricsClient() (insights.MetricsClient, error) {
+
+	authorizer, subscriptionID, err := iam.GetAuthorizer()
+	if err != nil {
+		return insights.MetricsClient{}, err
+	}
+
+	client := insights.NewMetricsClient(subscriptionID)
+	client.Authorizer = authorizer
+	client.AddToUserAgent(userAgent)
+
+	return client, nil
+}
+
+func getVirtualNetworksClient() (network.VirtualNetworksClient, error) {
+
+	authorizer, subscriptionID, err := iam.GetAuthorizer()
+	if err != nil {
+		return network.VirtualNetworksClient{}, err
+	}
+
+	client := network.NewVirtualNetworksClient(subscriptionID)
+	client.Authorizer = authorizer
+	client.AddToUserAgent(userAgent)
+
+	return client, nil
+}
+
+func getSubnetsClient() (network.SubnetsClient, error) {
+
+	authorizer, subscriptionID, err := iam.GetAuthorizer()
+	if err != nil {
+		return network.SubnetsClient{}, err
+	}
+
+	client := network.NewSubnetsClient(subscriptionID)
+	client.Authorizer = authorizer
+	client.AddToUserAgent(userAgent)
+
+	return client, nil
+}
+
 // GetResourceByID gets a generic resource
 func GetResourceByID(ctx context.Context, resourceID, APIVersion string) (resources.GenericResource, error) {
 
@@ -168,356 +353,2013 @@ func GetResourceByID(ctx context.Context, resourceID, APIVersion string) (resour
 	)
 }
 
-// CreateANFAccount creates an ANF Account resource
-func CreateANFAccount(ctx context.Context, location, resourceGroupName, accountName string, activeDirectories []netapp.ActiveDirectory, tags map[string]*string) (netapp.Account, error) {
+// ResourceExists checks if a resource exists using the generic resources API, apiVersion must be supported by its provider
+func ResourceExists(ctx context.Context, resourceID, apiVersion string) (bool, error) {
 
-	accountClient, err := getAccountsClient()
+	resourcesClient, err := getResourcesClient()
 	if err != nil {
-		return netapp.Account{}, err
+		return false, err
 	}
 
-	accountProperties := netapp.AccountProperties{}
+	resource, err := resourcesClient.GetByID(ctx, resourceID, apiVersion)
+	if resource.Response.Response != nil && resource.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("cannot get resource %v: %v", resourceID, err)
+	}
 
-	if activeDirectories != nil {
-		accountProperties = netapp.AccountProperties{
-			ActiveDirectories: &activeDirectories,
-		}
+	return true, nil
+}
+
+// GetANFProvisioningState returns the provisioningState property of an Azure NetApp Files resource, e.g. Creating
+func GetANFProvisioningState(ctx context.Context, resourceID string) (string, error) {
+
+	resource, err := GetResourceByID(ctx, resourceID, NetAppAPIVersion)
+	if err != nil {
+		return "", fmt.Errorf("cannot get resource %v: %v", resourceID, err)
 	}
 
-	future, err := accountClient.CreateOrUpdate(
+	properties, ok := resource.Properties.(map[string]interface{})
+	if !ok {
+		return "", nil
+	}
+
+	provisioningState, _ := properties["provisioningState"].(string)
+
+	return provisioningState, nil
+}
+
+// GetVirtualNetwork gets a virtual network from its resource id
+func GetVirtualNetwork(ctx context.Context, vnetID string) (network.VirtualNetwork, error) {
+
+	vnetClient, err := getVirtualNetworksClient()
+	if err != nil {
+		return network.VirtualNetwork{}, err
+	}
+
+	vnet, err := vnetClient.Get(
 		ctx,
-		netapp.Account{
-			Location:          to.StringPtr(location),
-			Tags:              tags,
-			AccountProperties: &accountProperties,
-		},
-		resourceGroupName,
-		accountName,
+		uri.GetResourceGroup(vnetID),
+		uri.GetResourceValue(vnetID, "/virtualNetworks"),
+		"",
 	)
+
 	if err != nil {
-		return netapp.Account{}, fmt.Errorf("cannot create account: %v", err)
+		return network.VirtualNetwork{}, fmt.Errorf("cannot get virtual network: %v", err)
 	}
 
-	err = future.WaitForCompletionRef(ctx, accountClient.Client)
+	return vnet, nil
+}
+
+// GetSubnet gets a subnet from its resource id
+func GetSubnet(ctx context.Context, subnetID string) (network.Subnet, error) {
+
+	subnetClient, err := getSubnetsClient()
 	if err != nil {
-		return netapp.Account{}, fmt.Errorf("cannot get the account create or update future response: %v", err)
+		return network.Subnet{}, err
 	}
 
-	return future.Result(accountClient)
+	subnet, err := subnetClient.Get(
+		ctx,
+		uri.GetResourceGroup(subnetID),
+		uri.GetResourceValue(subnetID, "/virtualNetworks"),
+		uri.GetResourceValue(subnetID, "/subnets"),
+		"",
+	)
+
+	if err != nil {
+		return network.Subnet{}, fmt.Errorf("cannot get subnet: %v", err)
+	}
+
+	return subnet, nil
 }
 
-// CreateANFCapacityPool creates an ANF Capacity Pool within ANF Account
-func CreateANFCapacityPool(ctx context.Context, location, resourceGroupName, accountName, poolName, serviceLevel string, sizeBytes int64, tags map[string]*string) (netapp.CapacityPool, error) {
+// ResourceGroupExists checks if a resource group exists in the subscription
+func ResourceGroupExists(ctx context.Context, resourceGroupName string) (bool, error) {
 
-	poolClient, err := getPoolsClient()
+	groupsClient, err := getGroupsClient()
 	if err != nil {
-		return netapp.CapacityPool{}, err
+		return false, err
 	}
 
-	svcLevel, err := validateANFServiceLevel(serviceLevel)
+	response, err := groupsClient.CheckExistence(ctx, resourceGroupName)
+	if response.Response != nil && response.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
 	if err != nil {
-		return netapp.CapacityPool{}, err
+		return false, fmt.Errorf("cannot check resource group existence: %v", err)
 	}
 
-	future, err := poolClient.CreateOrUpdate(
+	return true, nil
+}
+
+// EnsureResourceGroup creates a resource group when it does not exist, tagging it with the ownership tag
+// so it can be deleted later on by DeleteResourceGroup. It returns the group and whether it was created
+func EnsureResourceGroup(ctx context.Context, location, resourceGroupName string, tags map[string]*string) (resources.Group, bool, error) {
+
+	groupsClient, err := getGroupsClient()
+	if err != nil {
+		return resources.Group{}, false, err
+	}
+
+	exists, err := ResourceGroupExists(ctx, resourceGroupName)
+	if err != nil {
+		return resources.Group{}, false, err
+	}
+
+	if exists {
+		group, err := groupsClient.Get(ctx, resourceGroupName)
+		if err != nil {
+			return resources.Group{}, false, fmt.Errorf("cannot get resource group: %v", err)
+		}
+		return group, false, nil
+	}
+
+	// The ownership tag is set last so a caller tag with the same name cannot override it
+	groupTags := map[string]*string{}
+	for key, value := range tags {
+		groupTags[key] = value
+	}
+	groupTags[OwnershipTagName] = to.StringPtr(ownershipTagValue)
+
+	group, err := groupsClient.CreateOrUpdate(
 		ctx,
-		netapp.CapacityPool{
+		resourceGroupName,
+		resources.Group{
 			Location: to.StringPtr(location),
-			Tags:     tags,
-			PoolProperties: &netapp.PoolProperties{
-				ServiceLevel: svcLevel,
-				Size:         to.Int64Ptr(sizeBytes),
-			},
+			Tags:     groupTags,
 		},
-		resourceGroupName,
-		accountName,
-		poolName,
 	)
+	if err != nil {
+		return resources.Group{}, false, fmt.Errorf("cannot create resource group: %v", err)
+	}
+
+	return group, true, nil
+}
+
+// DeleteResourceGroup deletes a resource group and everything it contains, waiting for the deletion to complete.
+// As a safety guard, groups without the ownership tag set by EnsureResourceGroup are never deleted
+func DeleteResourceGroup(ctx context.Context, resourceGroupName string) error {
 
+	groupsClient, err := getGroupsClient()
 	if err != nil {
-		return netapp.CapacityPool{}, fmt.Errorf("cannot create pool: %v", err)
+		return err
 	}
 
-	err = future.WaitForCompletionRef(ctx, poolClient.Client)
+	group, err := groupsClient.Get(ctx, resourceGroupName)
 	if err != nil {
-		return netapp.CapacityPool{}, fmt.Errorf("cannot get the pool create or update future response: %v", err)
+		return fmt.Errorf("cannot get resource group: %v", err)
 	}
 
-	return future.Result(poolClient)
+	if owner, found := group.Tags[OwnershipTagName]; !found || owner == nil || *owner != ownershipTagValue {
+		return fmt.Errorf("refusing to delete resource group %v, it was not created by this sample (tag %v=%v not found)", resourceGroupName, OwnershipTagName, ownershipTagValue)
+	}
+
+	future, err := groupsClient.Delete(ctx, resourceGroupName)
+	if err != nil {
+		return fmt.Errorf("cannot delete resource group: %v", err)
+	}
+
+	err = waitForCompletion(ctx, future.FutureAPI, groupsClient.Client, "delete", resourceGroupID(groupsClient.SubscriptionID, resourceGroupName))
+	if err != nil {
+		return fmt.Errorf("cannot get the resource group delete future response: %v", err)
+	}
+
+	return nil
 }
 
-// CreateANFVolume creates an ANF volume within a Capacity Pool
-func CreateANFVolume(ctx context.Context, location, resourceGroupName, accountName, poolName, volumeName, serviceLevel, subnetID, snapshotID string, protocolTypes []string, volumeUsageQuota int64, unixReadOnly, unixReadWrite bool, tags map[string]*string, dataProtectionObject netapp.VolumePropertiesDataProtection) (netapp.Volume, error) {
+// VirtualNetworkExists checks if a virtual network exists from its resource id
+func VirtualNetworkExists(ctx context.Context, vnetID string) (bool, error) {
 
-	if len(protocolTypes) > 2 {
-		return netapp.Volume{}, fmt.Errorf("maximum of two protocol types are supported")
+	vnetClient, err := getVirtualNetworksClient()
+	if err != nil {
+		return false, err
 	}
 
-	if len(protocolTypes) > 1 && utils.Contains(protocolTypes, "NFSv4.1") {
-		return netapp.Volume{}, fmt.Errorf("only cifs/nfsv3 protocol types are supported as dual protocol")
+	vnet, err := vnetClient.Get(
+		ctx,
+		uri.GetResourceGroup(vnetID),
+		uri.GetResourceValue(vnetID, "/virtualNetworks"),
+		"",
+	)
+	if vnet.Response.Response != nil && vnet.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("cannot get virtual network: %v", err)
 	}
 
-	_, found := utils.FindInSlice(validProtocols, protocolTypes[0])
-	if !found {
-		return netapp.Volume{}, fmt.Errorf("invalid protocol type, valid protocol types are: %v", validProtocols)
+	return true, nil
+}
+
+// SubnetExists checks if a subnet exists from its resource id
+func SubnetExists(ctx context.Context, subnetID string) (bool, error) {
+
+	subnetClient, err := getSubnetsClient()
+	if err != nil {
+		return false, err
 	}
 
-	svcLevel, err := validateANFServiceLevel(serviceLevel)
+	subnet, err := subnetClient.Get(
+		ctx,
+		uri.GetResourceGroup(subnetID),
+		uri.GetResourceValue(subnetID, "/virtualNetworks"),
+		uri.GetResourceValue(subnetID, "/subnets"),
+		"",
+	)
+	if subnet.Response.Response != nil && subnet.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
 	if err != nil {
-		return netapp.Volume{}, err
+		return false, fmt.Errorf("cannot get subnet: %v", err)
 	}
 
-	volumeClient, err := getVolumesClient()
+	return true, nil
+}
+
+// CreateVirtualNetwork creates a virtual network with the provided address spaces
+func CreateVirtualNetwork(ctx context.Context, location, resourceGroupName, vnetName string, addressPrefixes []string, tags map[string]*string) (network.VirtualNetwork, error) {
+
+	vnetClient, err := getVirtualNetworksClient()
 	if err != nil {
-		return netapp.Volume{}, err
+		return network.VirtualNetwork{}, err
 	}
 
-	exportPolicy := netapp.VolumePropertiesExportPolicy{}
+	future, err := vnetClient.CreateOrUpdate(
+		ctx,
+		resourceGroupName,
+		vnetName,
+		network.VirtualNetwork{
+			Location: to.StringPtr(location),
+			Tags:     tags,
+			VirtualNetworkPropertiesFormat: &network.VirtualNetworkPropertiesFormat{
+				AddressSpace: &network.AddressSpace{
+					AddressPrefixes: &addressPrefixes,
+				},
+			},
+		},
+	)
+	if err != nil {
+		return network.VirtualNetwork{}, fmt.Errorf("cannot create virtual network: %v", err)
+	}
 
-	if _, found := utils.FindInSlice(protocolTypes, cifs); !found {
-		exportPolicy = netapp.VolumePropertiesExportPolicy{
-			Rules: &[]netapp.ExportPolicyRule{
-				{
-					AllowedClients: to.StringPtr("0.0.0.0/0"),
-					Cifs:           to.BoolPtr(map[bool]bool{true: true, false: false}[protocolTypes[0] == cifs]),
-					Nfsv3:          to.BoolPtr(map[bool]bool{true: true, false: false}[protocolTypes[0] == nfsv3]),
-					Nfsv41:         to.BoolPtr(map[bool]bool{true: true, false: false}[protocolTypes[0] == nfsv41]),
-					RuleIndex:      to.Int32Ptr(1),
-					UnixReadOnly:   to.BoolPtr(unixReadOnly),
-					UnixReadWrite:  to.BoolPtr(unixReadWrite),
+	err = waitForCompletion(ctx, future.FutureAPI, vnetClient.Client, "create", fmt.Sprintf("%v/providers/Microsoft.Network/virtualNetworks/%v", resourceGroupID(vnetClient.SubscriptionID, resourceGroupName), vnetName))
+	if err != nil {
+		return network.VirtualNetwork{}, fmt.Errorf("cannot get the virtual network create or update future response: %v", err)
+	}
+
+	return future.Result(vnetClient)
+}
+
+// CreateDelegatedSubnet creates a subnet delegated to Azure NetApp Files volumes
+func CreateDelegatedSubnet(ctx context.Context, resourceGroupName, vnetName, subnetName, addressPrefix string) (network.Subnet, error) {
+
+	subnetClient, err := getSubnetsClient()
+	if err != nil {
+		return network.Subnet{}, err
+	}
+
+	future, err := subnetClient.CreateOrUpdate(
+		ctx,
+		resourceGroupName,
+		vnetName,
+		subnetName,
+		network.Subnet{
+			SubnetPropertiesFormat: &network.SubnetPropertiesFormat{
+				AddressPrefix: to.StringPtr(addressPrefix),
+				Delegations: &[]network.Delegation{
+					{
+						Name: to.StringPtr("netAppVolumes"),
+						ServiceDelegationPropertiesFormat: &network.ServiceDelegationPropertiesFormat{
+							ServiceName: to.StringPtr(netAppVolumesDelegation),
+						},
+					},
 				},
 			},
+		},
+	)
+	if err != nil {
+		return network.Subnet{}, fmt.Errorf("cannot create subnet: %v", err)
+	}
+
+	err = waitForCompletion(ctx, future.FutureAPI, subnetClient.Client, "create", fmt.Sprintf("%v/providers/Microsoft.Network/virtualNetworks/%v/subnets/%v", resourceGroupID(subnetClient.SubscriptionID, resourceGroupName), vnetName, subnetName))
+	if err != nil {
+		return network.Subnet{}, fmt.Errorf("cannot get the subnet create or update future response: %v", err)
+	}
+
+	return future.Result(subnetClient)
+}
+
+// DeleteVirtualNetwork deletes a virtual network from its resource id
+func DeleteVirtualNetwork(ctx context.Context, vnetID string) error {
+
+	vnetClient, err := getVirtualNetworksClient()
+	if err != nil {
+		return err
+	}
+
+	future, err := vnetClient.Delete(
+		ctx,
+		uri.GetResourceGroup(vnetID),
+		uri.GetResourceValue(vnetID, "/virtualNetworks"),
+	)
+	if err != nil {
+		return fmt.Errorf("cannot delete virtual network: %v", err)
+	}
+
+	err = waitForCompletion(ctx, future.FutureAPI, vnetClient.Client, "delete", vnetID)
+	if err != nil {
+		return fmt.Errorf("cannot get the virtual network delete future response: %v", err)
+	}
+
+	return nil
+}
+
+// DeleteSubnet deletes a subnet from its resource id
+func DeleteSubnet(ctx context.Context, subnetID string) error {
+
+	subnetClient, err := getSubnetsClient()
+	if err != nil {
+		return err
+	}
+
+	future, err := subnetClient.Delete(
+		ctx,
+		uri.GetResourceGroup(subnetID),
+		uri.GetResourceValue(subnetID, "/virtualNetworks"),
+		uri.GetResourceValue(subnetID, "/subnets"),
+	)
+	if err != nil {
+		return fmt.Errorf("cannot delete subnet: %v", err)
+	}
+
+	err = waitForCompletion(ctx, future.FutureAPI, subnetClient.Client, "delete", subnetID)
+	if err != nil {
+		return fmt.Errorf("cannot get the subnet delete future response: %v", err)
+	}
+
+	return nil
+}
+
+// CreateANFAccount creates an ANF Account resource
+func CreateANFAccount(ctx context.Context, location, resourceGroupName, accountName string, activeDirectories []netapp.ActiveDirectory, tags map[string]*string) (netapp.Account, error) {
+
+	accountClient, future, err := beginANFAccountCreation(ctx, location, resourceGroupName, accountName, activeDirectories, tags)
+	if err != nil {
+		return netapp.Account{}, err
+	}
+
+	err = waitForCompletion(ctx, future.FutureAPI, accountClient.Client, "create", anfResourceID(accountClient.SubscriptionID, resourceGroupName, accountName))
+	if err != nil {
+		return netapp.Account{}, fmt.Errorf("cannot get the account create or update future response: %v", err)
+	}
+
+	return future.Result(accountClient)
+}
+
+// BeginCreateANFAccount starts the creation of an ANF Account without waiting for it to complete,
+// the returned future can be serialized to resume polling later on, even from another process
+func BeginCreateANFAccount(ctx context.Context, location, resourceGroupName, accountName string, activeDirectories []netapp.ActiveDirectory, tags map[string]*string) (azure.Future, error) {
+
+	_, future, err := beginANFAccountCreation(ctx, location, resourceGroupName, accountName, activeDirectories, tags)
+	if err != nil {
+		return azure.Future{}, err
+	}
+	logging.Debug("account creation started", logging.Operation("create"), logging.String("account", accountName), logging.String("pollingUrl", future.PollingURL()))
+
+	return asFuture(future.FutureAPI)
+}
+
+// beginANFAccountCreation sends the account create request
+func beginANFAccountCreation(ctx context.Context, location, resourceGroupName, accountName string, activeDirectories []netapp.ActiveDirectory, tags map[string]*string) (netapp.AccountsClient, netapp.AccountsCreateOrUpdateFuture, error) {
+
+	accountClient, err := getAccountsClient()
+	if err != nil {
+		return netapp.AccountsClient{}, netapp.AccountsCreateOrUpdateFuture{}, err
+	}
+
+	accountProperties := netapp.AccountProperties{}
+
+	if activeDirectories != nil {
+		accountProperties = netapp.AccountProperties{
+			ActiveDirectories: &activeDirectories,
+		}
+	}
+
+	future, err := accountClient.CreateOrUpdate(
+		ctx,
+		netapp.Account{
+			Location:          to.StringPtr(location),
+			Tags:              tags,
+			AccountProperties: &accountProperties,
+		},
+		resourceGroupName,
+		accountName,
+	)
+	if err != nil {
+		return netapp.AccountsClient{}, netapp.AccountsCreateOrUpdateFuture{}, fmt.Errorf("cannot create account: %v", err)
+	}
+
+	return accountClient, future, nil
+}
+
+// CreateANFCapacityPool creates an ANF Capacity Pool within ANF Account
+func CreateANFCapacityPool(ctx context.Context, location, resourceGroupName, accountName, poolName, serviceLevel string, sizeBytes int64, tags map[string]*string) (netapp.CapacityPool, error) {
+
+	poolClient, future, err := beginANFCapacityPoolCreation(ctx, location, resourceGroupName, accountName, poolName, serviceLevel, sizeBytes, tags)
+	if err != nil {
+		return netapp.CapacityPool{}, err
+	}
+
+	err = waitForCompletion(ctx, future.FutureAPI, poolClient.Client, "create", anfResourceID(poolClient.SubscriptionID, resourceGroupName, accountName, "capacityPools", poolName))
+	if err != nil {
+		return netapp.CapacityPool{}, fmt.Errorf("cannot get the pool create or update future response: %v", err)
+	}
+
+	return future.Result(poolClient)
+}
+
+// BeginCreateANFCapacityPool starts the creation of an ANF Capacity Pool without waiting for it to complete,
+// the returned future can be serialized to resume polling later on, even from another process
+func BeginCreateANFCapacityPool(ctx context.Context, location, resourceGroupName, accountName, poolName, serviceLevel string, sizeBytes int64, tags map[string]*string) (azure.Future, error) {
+
+	_, future, err := beginANFCapacityPoolCreation(ctx, location, resourceGroupName, accountName, poolName, serviceLevel, sizeBytes, tags)
+	if err != nil {
+		return azure.Future{}, err
+	}
+	logging.Debug("capacity pool creation started", logging.Operation("create"), logging.String("pool", poolName), logging.String("pollingUrl", future.PollingURL()))
+
+	return asFuture(future.FutureAPI)
+}
+
+// beginANFCapacityPoolCreation validates the service level and sends the capacity pool create request
+func beginANFCapacityPoolCreation(ctx context.Context, location, resourceGroupName, accountName, poolName, serviceLevel string, sizeBytes int64, tags map[string]*string) (netapp.PoolsClient, netapp.PoolsCreateOrUpdateFuture, error) {
+
+	poolClient, err := getPoolsClient()
+	if err != nil {
+		return netapp.PoolsClient{}, netapp.PoolsCreateOrUpdateFuture{}, err
+	}
+
+	svcLevel, err := validateANFServiceLevel(serviceLevel)
+	if err != nil {
+		return netapp.PoolsClient{}, netapp.PoolsCreateOrUpdateFuture{}, err
+	}
+
+	future, err := poolClient.CreateOrUpdate(
+		ctx,
+		netapp.CapacityPool{
+			Location: to.StringPtr(location),
+			Tags:     tags,
+			PoolProperties: &netapp.PoolProperties{
+				ServiceLevel: svcLevel,
+				Size:         to.Int64Ptr(sizeBytes),
+			},
+		},
+		resourceGroupName,
+		accountName,
+		poolName,
+	)
+
+	if err != nil {
+		return netapp.PoolsClient{}, netapp.PoolsCreateOrUpdateFuture{}, fmt.Errorf("cannot create pool: %v", err)
+	}
+
+	return poolClient, future, nil
+}
+
+// GetANFAccountByID gets an account from its resource id
+func GetANFAccountByID(ctx context.Context, accountID string) (netapp.Account, error) {
+
+	if !uri.IsANFAccount(accountID) {
+		return netapp.Account{}, fmt.Errorf("resource id %v is not an account", accountID)
+	}
+
+	accountClient, err := getAccountsClient()
+	if err != nil {
+		return netapp.Account{}, err
+	}
+
+	account, err := accountClient.Get(
+		ctx,
+		uri.GetResourceGroup(accountID),
+		uri.GetANFAccount(accountID),
+	)
+
+	if err != nil {
+		return netapp.Account{}, fmt.Errorf("cannot get account: %v", err)
+	}
+
+	return account, nil
+}
+
+// GetANFCapacityPoolByID gets a capacity pool from its resource id
+func GetANFCapacityPoolByID(ctx context.Context, poolID string) (netapp.CapacityPool, error) {
+
+	if !uri.IsANFCapacityPool(poolID) {
+		return netapp.CapacityPool{}, fmt.Errorf("resource id %v is not a capacity pool", poolID)
+	}
+
+	poolClient, err := getPoolsClient()
+	if err != nil {
+		return netapp.CapacityPool{}, err
+	}
+
+	pool, err := poolClient.Get(
+		ctx,
+		uri.GetResourceGroup(poolID),
+		uri.GetANFAccount(poolID),
+		uri.GetANFCapacityPool(poolID),
+	)
+
+	if err != nil {
+		return netapp.CapacityPool{}, fmt.Errorf("cannot get capacity pool: %v", err)
+	}
+
+	return pool, nil
+}
+
+// ListANFVolumes lists all volumes of a capacity pool
+func ListANFVolumes(ctx context.Context, poolID string) ([]netapp.Volume, error) {
+
+	if !uri.IsANFCapacityPool(poolID) {
+		return nil, fmt.Errorf("resource id %v is not a capacity pool", poolID)
+	}
+
+	volumeClient, err := getVolumesClient()
+	if err != nil {
+		return nil, err
+	}
+
+	iterator, err := volumeClient.ListComplete(
+		ctx,
+		uri.GetResourceGroup(poolID),
+		uri.GetANFAccount(poolID),
+		uri.GetANFCapacityPool(poolID),
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("cannot list volumes: %v", err)
+	}
+
+	volumes := []netapp.Volume{}
+	for iterator.NotDone() {
+		volumes = append(volumes, iterator.Value())
+		if err = iterator.NextWithContext(ctx); err != nil {
+			return nil, fmt.Errorf("cannot list volumes: %v", err)
+		}
+	}
+
+	return volumes, nil
+}
+
+// ListANFAccountIDs returns the resource ids of all accounts of the subscription in a region,
+// all regions are included when location is empty
+func ListANFAccountIDs(ctx context.Context, location string) ([]string, error) {
+
+	resourcesClient, err := getResourcesClient()
+	if err != nil {
+		return nil, err
+	}
+
+	iterator, err := resourcesClient.ListComplete(
+		ctx,
+		"resourceType eq 'Microsoft.NetApp/netAppAccounts'",
+		"",
+		nil,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("cannot list accounts: %v", err)
+	}
+
+	accountIDs := []string{}
+	for iterator.NotDone() {
+		account := iterator.Value()
+		if account.ID != nil && (location == "" || (account.Location != nil && strings.EqualFold(*account.Location, location))) {
+			accountIDs = append(accountIDs, *account.ID)
+		}
+		if err = iterator.NextWithContext(ctx); err != nil {
+			return nil, fmt.Errorf("cannot list accounts: %v", err)
 		}
 	}
 
-	volumeProperties := netapp.VolumeProperties{
-		SnapshotID:     map[bool]*string{true: to.StringPtr(snapshotID), false: nil}[snapshotID != ""],
-		ExportPolicy:   map[bool]*netapp.VolumePropertiesExportPolicy{true: &exportPolicy, false: nil}[protocolTypes[0] != cifs],
-		ProtocolTypes:  &protocolTypes,
-		ServiceLevel:   svcLevel,
-		SubnetID:       to.StringPtr(subnetID),
-		UsageThreshold: to.Int64Ptr(volumeUsageQuota),
-		CreationToken:  to.StringPtr(volumeName),
-		DataProtection: &dataProtectionObject,
+	return accountIDs, nil
+}
+
+// CheckANFQuotaAvailability checks if a resource can be created without exceeding the subscription quota,
+// it returns false and the reason when the quota would be exceeded
+func CheckANFQuotaAvailability(ctx context.Context, location, resourceGroupName, name string, resourceType netapp.CheckQuotaNameResourceTypes) (bool, string, error) {
+
+	resourceClient, err := getNetAppResourceClient()
+	if err != nil {
+		return false, "", err
+	}
+
+	response, err := resourceClient.CheckQuotaAvailability(
+		ctx,
+		netapp.QuotaAvailabilityRequest{
+			Name:          to.StringPtr(name),
+			Type:          resourceType,
+			ResourceGroup: to.StringPtr(resourceGroupName),
+		},
+		location,
+	)
+	if err != nil {
+		return false, "", fmt.Errorf("cannot check quota availability: %v", err)
+	}
+
+	if response.IsAvailable != nil && !*response.IsAvailable {
+		return false, to.String(response.Message), nil
+	}
+
+	return true, "", nil
+}
+
+// CheckANFNameAvailability checks if a resource name is valid and not used yet, it returns false and the reason otherwise
+func CheckANFNameAvailability(ctx context.Context, location, resourceGroupName, name string, resourceType netapp.CheckNameResourceTypes) (bool, string, error) {
+
+	resourceClient, err := getNetAppResourceClient()
+	if err != nil {
+		return false, "", err
+	}
+
+	response, err := resourceClient.CheckNameAvailability(
+		ctx,
+		netapp.ResourceNameAvailabilityRequest{
+			Name:          to.StringPtr(name),
+			Type:          resourceType,
+			ResourceGroup: to.StringPtr(resourceGroupName),
+		},
+		location,
+	)
+	if err != nil {
+		return false, "", fmt.Errorf("cannot check name availability: %v", err)
+	}
+
+	if response.IsAvailable != nil && !*response.IsAvailable {
+		return false, to.String(response.Message), nil
+	}
+
+	return true, "", nil
+}
+
+// CheckANFFilePathAvailability checks if a volume file path (creation token) is not used yet within a subnet,
+// it returns false and the reason otherwise
+func CheckANFFilePathAvailability(ctx context.Context, location, subnetID, filePath string) (bool, string, error) {
+
+	resourceClient, err := getNetAppResourceClient()
+	if err != nil {
+		return false, "", err
+	}
+
+	response, err := resourceClient.CheckFilePathAvailability(
+		ctx,
+		netapp.FilePathAvailabilityRequest{
+			Name:     to.StringPtr(filePath),
+			SubnetID: to.StringPtr(subnetID),
+		},
+		location,
+	)
+	if err != nil {
+		return false, "", fmt.Errorf("cannot check file path availability: %v", err)
+	}
+
+	if response.IsAvailable != nil && !*response.IsAvailable {
+		return false, to.String(response.Message), nil
+	}
+
+	return true, "", nil
+}
+
+// ListANFCapacityPools lists all capacity pools of an account
+func ListANFCapacityPools(ctx context.Context, accountID string) ([]netapp.CapacityPool, error) {
+
+	if !uri.IsANFAccount(accountID) {
+		return nil, fmt.Errorf("resource id %v is not an account", accountID)
+	}
+
+	poolClient, err := getPoolsClient()
+	if err != nil {
+		return nil, err
+	}
+
+	iterator, err := poolClient.ListComplete(
+		ctx,
+		uri.GetResourceGroup(accountID),
+		uri.GetANFAccount(accountID),
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("cannot list capacity pools: %v", err)
+	}
+
+	pools := []netapp.CapacityPool{}
+	for iterator.NotDone() {
+		pools = append(pools, iterator.Value())
+		if err = iterator.NextWithContext(ctx); err != nil {
+			return nil, fmt.Errorf("cannot list capacity pools: %v", err)
+		}
+	}
+
+	return pools, nil
+}
+
+// GetANFCapacityPoolAllocatedBytes returns the sum of the quotas (usage thresholds) of all volumes within a capacity pool
+func GetANFCapacityPoolAllocatedBytes(ctx context.Context, poolID string) (int64, error) {
+
+	volumes, err := ListANFVolumes(ctx, poolID)
+	if err != nil {
+		return 0, err
+	}
+
+	var allocatedBytes int64
+	for _, volume := range volumes {
+		if volume.VolumeProperties != nil && volume.UsageThreshold != nil {
+			allocatedBytes += *volume.UsageThreshold
+		}
+	}
+
+	return allocatedBytes, nil
+}
+
+// ResizeANFCapacityPool grows or shrinks a capacity pool. The new size must be at least 4TiB, in 1TiB increments,
+// and cannot be smaller than the sum of the quotas of the volumes already allocated within the pool.
+func ResizeANFCapacityPool(ctx context.Context, poolID string, newSizeBytes int64) (netapp.CapacityPool, error) {
+
+	if newSizeBytes < MinCapacityPoolSizeBytes {
+		return netapp.CapacityPool{}, fmt.Errorf("capacity pool size must be at least %v TiB, requested %v bytes", MinCapacityPoolSizeBytes/TiBInBytes, newSizeBytes)
+	}
+
+	if newSizeBytes%CapacityPoolSizeIncrementBytes != 0 {
+		return netapp.CapacityPool{}, fmt.Errorf("capacity pool size must be a multiple of 1 TiB (%v bytes), requested %v bytes", CapacityPoolSizeIncrementBytes, newSizeBytes)
+	}
+
+	pool, err := GetANFCapacityPoolByID(ctx, poolID)
+	if err != nil {
+		return netapp.CapacityPool{}, err
+	}
+
+	allocatedBytes, err := GetANFCapacityPoolAllocatedBytes(ctx, poolID)
+	if err != nil {
+		return netapp.CapacityPool{}, err
+	}
+
+	if newSizeBytes < allocatedBytes {
+		return netapp.CapacityPool{}, fmt.Errorf(
+			"cannot shrink capacity pool %v to %v TiB, its volumes have %.2f TiB allocated, reduce or move volumes first",
+			uri.GetANFCapacityPool(poolID),
+			newSizeBytes/TiBInBytes,
+			float64(allocatedBytes)/float64(TiBInBytes),
+		)
+	}
+
+	poolClient, err := getPoolsClient()
+	if err != nil {
+		return netapp.CapacityPool{}, err
+	}
+
+	future, err := poolClient.Update(
+		ctx,
+		netapp.CapacityPoolPatch{
+			Location: pool.Location,
+			Tags:     pool.Tags,
+			PoolPatchProperties: &netapp.PoolPatchProperties{
+				Size: to.Int64Ptr(newSizeBytes),
+			},
+		},
+		uri.GetResourceGroup(poolID),
+		uri.GetANFAccount(poolID),
+		uri.GetANFCapacityPool(poolID),
+	)
+
+	if err != nil {
+		return netapp.CapacityPool{}, fmt.Errorf("cannot resize capacity pool: %v", err)
+	}
+
+	err = waitForCompletion(ctx, future.FutureAPI, poolClient.Client, "update", poolID)
+	if err != nil {
+		return netapp.CapacityPool{}, fmt.Errorf("cannot get the capacity pool update future response: %v", err)
+	}
+
+	return future.Result(poolClient)
+}
+
+// UpdateANFCapacityPool changes the QoS type and/or cool access setting of a capacity pool, empty serviceLevel and qosType
+// or nil coolAccess keep the current values. The service level of a pool cannot be changed in place, requesting another
+// one fails without changing anything: create a pool with the desired service level and move the volumes to it with
+// MoveANFVolumeToPool instead.
+func UpdateANFCapacityPool(ctx context.Context, poolID, serviceLevel, qosType string, coolAccess *bool) (netapp.CapacityPool, error) {
+
+	pool, err := GetANFCapacityPoolByID(ctx, poolID)
+	if err != nil {
+		return netapp.CapacityPool{}, err
+	}
+
+	if pool.PoolProperties == nil {
+		return netapp.CapacityPool{}, fmt.Errorf("capacity pool %v has no properties", poolID)
+	}
+
+	if serviceLevel != "" {
+		level, err := validateANFServiceLevel(serviceLevel)
+		if err != nil {
+			return netapp.CapacityPool{}, err
+		}
+
+		if level != pool.ServiceLevel {
+			return netapp.CapacityPool{}, fmt.Errorf(
+				"service level of capacity pool %v cannot be changed from %v to %v in place, create a %v pool and move the volumes to it with MoveANFVolumeToPool",
+				uri.GetANFCapacityPool(poolID),
+				pool.ServiceLevel,
+				level,
+				level,
+			)
+		}
+	}
+
+	if qosType != "" {
+		qos, err := validateANFQosType(qosType)
+		if err != nil {
+			return netapp.CapacityPool{}, err
+		}
+
+		if pool.QosType == netapp.QosTypeManual && qos == netapp.QosTypeAuto {
+			return netapp.CapacityPool{}, fmt.Errorf("capacity pool %v uses manual qos and cannot be changed back to auto qos", uri.GetANFCapacityPool(poolID))
+		}
+
+		pool.QosType = qos
+	}
+
+	if coolAccess != nil {
+		pool.CoolAccess = coolAccess
+	}
+
+	poolClient, err := getPoolsClient()
+	if err != nil {
+		return netapp.CapacityPool{}, err
+	}
+
+	future, err := poolClient.CreateOrUpdate(
+		ctx,
+		pool,
+		uri.GetResourceGroup(poolID),
+		uri.GetANFAccount(poolID),
+		uri.GetANFCapacityPool(poolID),
+	)
+
+	if err != nil {
+		return netapp.CapacityPool{}, fmt.Errorf("cannot update capacity pool: %v", err)
+	}
+
+	err = waitForCompletion(ctx, future.FutureAPI, poolClient.Client, "update", poolID)
+	if err != nil {
+		return netapp.CapacityPool{}, fmt.Errorf("cannot get the capacity pool create or update future response: %v", err)
+	}
+
+	return future.Result(poolClient)
+}
+
+// CreateANFVolume creates an ANF volume within a Capacity Pool
+func CreateANFVolume(ctx context.Context, location, resourceGroupName, accountName, poolName, volumeName, serviceLevel, subnetID, snapshotID string, protocolTypes []string, volumeUsageQuota int64, unixReadOnly, unixReadWrite bool, tags map[string]*string, dataProtectionObject netapp.VolumePropertiesDataProtection) (netapp.Volume, error) {
+
+	return createANFVolume(ctx, location, resourceGroupName, accountName, poolName, volumeName, serviceLevel, subnetID, snapshotID, "", protocolTypes, volumeUsageQuota, unixReadOnly, unixReadWrite, tags, dataProtectionObject)
+}
+
+// CreateANFVolumeFromBackup creates a new ANF volume within a Capacity Pool restoring the contents of a volume or account backup
+func CreateANFVolumeFromBackup(ctx context.Context, location, resourceGroupName, accountName, poolName, volumeName, serviceLevel, subnetID, backupID string, protocolTypes []string, volumeUsageQuota int64, unixReadOnly, unixReadWrite bool, tags map[string]*string) (netapp.Volume, error) {
+
+	if !uri.IsANFBackup(backupID) && !uri.IsANFAccountBackup(backupID) {
+		return netapp.Volume{}, fmt.Errorf("resource id %v is not a backup", backupID)
+	}
+
+	return createANFVolume(ctx, location, resourceGroupName, accountName, poolName, volumeName, serviceLevel, subnetID, "", backupID, protocolTypes, volumeUsageQuota, unixReadOnly, unixReadWrite, tags, netapp.VolumePropertiesDataProtection{})
+}
+
+// BeginCreateANFVolume starts the creation of an ANF volume within a Capacity Pool without waiting for it to complete,
+// the returned future can be serialized to resume polling later on, even from another process
+func BeginCreateANFVolume(ctx context.Context, location, resourceGroupName, accountName, poolName, volumeName, serviceLevel, subnetID, snapshotID string, protocolTypes []string, volumeUsageQuota int64, unixReadOnly, unixReadWrite bool, tags map[string]*string, dataProtectionObject netapp.VolumePropertiesDataProtection) (azure.Future, error) {
+
+	_, future, err := beginANFVolumeCreation(ctx, location, resourceGroupName, accountName, poolName, volumeName, serviceLevel, subnetID, snapshotID, "", protocolTypes, volumeUsageQuota, unixReadOnly, unixReadWrite, tags, dataProtectionObject)
+	if err != nil {
+		return azure.Future{}, err
+	}
+	logging.Debug("volume creation started", logging.Operation("create"), logging.String("volume", volumeName), logging.String("pollingUrl", future.PollingURL()))
+
+	return asFuture(future.FutureAPI)
+}
+
+// createANFVolume creates an ANF volume, empty or from either a snapshot or a backup
+func createANFVolume(ctx context.Context, location, resourceGroupName, accountName, poolName, volumeName, serviceLevel, subnetID, snapshotID, backupID string, protocolTypes []string, volumeUsageQuota int64, unixReadOnly, unixReadWrite bool, tags map[string]*string, dataProtectionObject netapp.VolumePropertiesDataProtection) (netapp.Volume, error) {
+
+	volumeClient, future, err := beginANFVolumeCreation(ctx, location, resourceGroupName, accountName, poolName, volumeName, serviceLevel, subnetID, snapshotID, backupID, protocolTypes, volumeUsageQuota, unixReadOnly, unixReadWrite, tags, dataProtectionObject)
+	if err != nil {
+		return netapp.Volume{}, err
+	}
+
+	err = waitForCompletion(ctx, future.FutureAPI, volumeClient.Client, "create", anfResourceID(volumeClient.SubscriptionID, resourceGroupName, accountName, "capacityPools", poolName, "volumes", volumeName))
+	if err != nil {
+		return netapp.Volume{}, fmt.Errorf("cannot get the volume create or update future response: %v", err)
+	}
+
+	return future.Result(volumeClient)
+}
+
+// beginANFVolumeCreation validates the volume properties and sends the create request, empty or from either a snapshot or a backup
+func beginANFVolumeCreation(ctx context.Context, location, resourceGroupName, accountName, poolName, volumeName, serviceLevel, subnetID, snapshotID, backupID string, protocolTypes []string, volumeUsageQuota int64, unixReadOnly, unixReadWrite bool, tags map[string]*string, dataProtectionObject netapp.VolumePropertiesDataProtection) (netapp.VolumesClient, netapp.VolumesCreateOrUpdateFuture, error) {
+
+	if len(protocolTypes) > 2 {
+		return netapp.VolumesClient{}, netapp.VolumesCreateOrUpdateFuture{}, fmt.Errorf("maximum of two protocol types are supported")
+	}
+
+	if len(protocolTypes) > 1 && utils.Contains(protocolTypes, "NFSv4.1") {
+		return netapp.VolumesClient{}, netapp.VolumesCreateOrUpdateFuture{}, fmt.Errorf("only cifs/nfsv3 protocol types are supported as dual protocol")
+	}
+
+	_, found := utils.FindInSlice(validProtocols, protocolTypes[0])
+	if !found {
+		return netapp.VolumesClient{}, netapp.VolumesCreateOrUpdateFuture{}, fmt.Errorf("invalid protocol type, valid protocol types are: %v", validProtocols)
+	}
+
+	svcLevel, err := validateANFServiceLevel(serviceLevel)
+	if err != nil {
+		return netapp.VolumesClient{}, netapp.VolumesCreateOrUpdateFuture{}, err
+	}
+
+	volumeClient, err := getVolumesClient()
+	if err != nil {
+		return netapp.VolumesClient{}, netapp.VolumesCreateOrUpdateFuture{}, err
+	}
+
+	exportPolicy := netapp.VolumePropertiesExportPolicy{}
+
+	if _, found := utils.FindInSlice(protocolTypes, cifs); !found {
+		exportPolicy = netapp.VolumePropertiesExportPolicy{
+			Rules: &[]netapp.ExportPolicyRule{
+				{
+					AllowedClients: to.StringPtr("0.0.0.0/0"),
+					Cifs:           to.BoolPtr(map[bool]bool{true: true, false: false}[protocolTypes[0] == cifs]),
+					Nfsv3:          to.BoolPtr(map[bool]bool{true: true, false: false}[protocolTypes[0] == nfsv3]),
+					Nfsv41:         to.BoolPtr(map[bool]bool{true: true, false: false}[protocolTypes[0] == nfsv41]),
+					RuleIndex:      to.Int32Ptr(1),
+					UnixReadOnly:   to.BoolPtr(unixReadOnly),
+					UnixReadWrite:  to.BoolPtr(unixReadWrite),
+				},
+			},
+		}
+	}
+
+	volumeProperties := netapp.VolumeProperties{
+		SnapshotID:     map[bool]*string{true: to.StringPtr(snapshotID), false: nil}[snapshotID != ""],
+		BackupID:       map[bool]*string{true: to.StringPtr(backupID), false: nil}[backupID != ""],
+		ExportPolicy:   map[bool]*netapp.VolumePropertiesExportPolicy{true: &exportPolicy, false: nil}[protocolTypes[0] != cifs],
+		ProtocolTypes:  &protocolTypes,
+		ServiceLevel:   svcLevel,
+		SubnetID:       to.StringPtr(subnetID),
+		UsageThreshold: to.Int64Ptr(volumeUsageQuota),
+		CreationToken:  to.StringPtr(volumeName),
+		DataProtection: &dataProtectionObject,
+	}
+
+	future, err := volumeClient.CreateOrUpdate(
+		ctx,
+		netapp.Volume{
+			Location:         to.StringPtr(location),
+			Tags:             tags,
+			VolumeProperties: &volumeProperties,
+		},
+		resourceGroupName,
+		accountName,
+		poolName,
+		volumeName,
+	)
+
+	if err != nil {
+		return netapp.VolumesClient{}, netapp.VolumesCreateOrUpdateFuture{}, fmt.Errorf("cannot create volume: %v", err)
+	}
+
+	return volumeClient, future, nil
+}
+
+// UpdateANFVolume update an ANF volume
+func UpdateANFVolume(ctx context.Context, location, resourceGroupName, accountName, poolName, volumeName string, volumePropertiesPatch netapp.VolumePatchProperties, tags map[string]*string) (netapp.VolumesUpdateFuture, error) {
+
+	volumeClient, err := getVolumesClient()
+	if err != nil {
+		return netapp.VolumesUpdateFuture{}, err
+	}
+
+	volume, err := volumeClient.Update(
+		ctx,
+		netapp.VolumePatch{
+			Location:              to.StringPtr(location),
+			Tags:                  tags,
+			VolumePatchProperties: &volumePropertiesPatch,
+		},
+		resourceGroupName,
+		accountName,
+		poolName,
+		volumeName,
+	)
+
+	if err != nil {
+		return netapp.VolumesUpdateFuture{}, fmt.Errorf("cannot update volume: %v", err)
+	}
+
+	return volume, nil
+}
+
+// ResizeANFVolume changes the quota (usage threshold) of a volume and waits for the update to complete
+func ResizeANFVolume(ctx context.Context, volumeID string, newSizeBytes int64) (netapp.Volume, error) {
+
+	return patchANFVolume(ctx, volumeID, netapp.VolumePatchProperties{
+		UsageThreshold: to.Int64Ptr(newSizeBytes),
+	})
+}
+
+// SetANFVolumeThroughput sets the throughput limit of a volume within a manual QoS capacity pool and waits for the update to complete
+func SetANFVolumeThroughput(ctx context.Context, volumeID string, throughputMibps float64) (netapp.Volume, error) {
+
+	return patchANFVolume(ctx, volumeID, netapp.VolumePatchProperties{
+		ThroughputMibps: to.Float64Ptr(throughputMibps),
+	})
+}
+
+// SetANFVolumeExportPolicy replaces the export policy rules of a volume and waits for the update to complete
+func SetANFVolumeExportPolicy(ctx context.Context, volumeID string, rules []netapp.ExportPolicyRule) (netapp.Volume, error) {
+
+	return patchANFVolume(ctx, volumeID, netapp.VolumePatchProperties{
+		ExportPolicy: &netapp.VolumePatchPropertiesExportPolicy{
+			Rules: &rules,
+		},
+	})
+}
+
+// UpdateANFResourceTags replaces the tags of an account, capacity pool, volume, snapshot policy or backup policy and
+// waits for the update to complete
+func UpdateANFResourceTags(ctx context.Context, resourceID string, tags map[string]*string) error {
+
+	if tags == nil {
+		tags = map[string]*string{}
+	}
+
+	resourceGroupName := uri.GetResourceGroup(resourceID)
+	accountName := uri.GetANFAccount(resourceID)
+
+	switch {
+	case uri.IsANFVolume(resourceID):
+		volumeClient, err := getVolumesClient()
+		if err != nil {
+			return err
+		}
+		future, err := volumeClient.Update(ctx, netapp.VolumePatch{Tags: tags}, resourceGroupName, accountName, uri.GetANFCapacityPool(resourceID), uri.GetANFVolume(resourceID))
+		if err != nil {
+			return fmt.Errorf("cannot update volume tags: %v", err)
+		}
+		err = waitForCompletion(ctx, future.FutureAPI, volumeClient.Client, "update", resourceID)
+		if err != nil {
+			return fmt.Errorf("cannot get the volume update future response: %v", err)
+		}
+	case uri.IsANFCapacityPool(resourceID):
+		poolClient, err := getPoolsClient()
+		if err != nil {
+			return err
+		}
+		future, err := poolClient.Update(ctx, netapp.CapacityPoolPatch{Tags: tags}, resourceGroupName, accountName, uri.GetANFCapacityPool(resourceID))
+		if err != nil {
+			return fmt.Errorf("cannot update capacity pool tags: %v", err)
+		}
+		err = waitForCompletion(ctx, future.FutureAPI, poolClient.Client, "update", resourceID)
+		if err != nil {
+			return fmt.Errorf("cannot get the capacity pool update future response: %v", err)
+		}
+	case uri.IsANFSnapshotPolicy(resourceID):
+		snapshotPolicyClient, err := getSnapshotPoliciesClient()
+		if err != nil {
+			return err
+		}
+		future, err := snapshotPolicyClient.Update(ctx, netapp.SnapshotPolicyPatch{Tags: tags}, resourceGroupName, accountName, uri.GetANFSnapshotPolicy(resourceID))
+		if err != nil {
+			return fmt.Errorf("cannot update snapshot policy tags: %v", err)
+		}
+		err = waitForCompletion(ctx, future.FutureAPI, snapshotPolicyClient.Client, "update", resourceID)
+		if err != nil {
+			return fmt.Errorf("cannot get the snapshot policy update future response: %v", err)
+		}
+	case uri.IsANFBackupPolicy(resourceID):
+		backupPolicyClient, err := getBackupPoliciesClient()
+		if err != nil {
+			return err
+		}
+		future, err := backupPolicyClient.Update(ctx, resourceGroupName, accountName, uri.GetANFBackupPolicy(resourceID), netapp.BackupPolicyPatch{Tags: tags})
+		if err != nil {
+			return fmt.Errorf("cannot update backup policy tags: %v", err)
+		}
+		err = waitForCompletion(ctx, future.FutureAPI, backupPolicyClient.Client, "update", resourceID)
+		if err != nil {
+			return fmt.Errorf("cannot get the backup policy update future response: %v", err)
+		}
+	case uri.IsANFAccount(resourceID):
+		accountClient, err := getAccountsClient()
+		if err != nil {
+			return err
+		}
+		future, err := accountClient.Update(ctx, netapp.AccountPatch{Tags: tags}, resourceGroupName, accountName)
+		if err != nil {
+			return fmt.Errorf("cannot update account tags: %v", err)
+		}
+		err = waitForCompletion(ctx, future.FutureAPI, accountClient.Client, "update", resourceID)
+		if err != nil {
+			return fmt.Errorf("cannot get the account update future response: %v", err)
+		}
+	default:
+		return fmt.Errorf("tags of %v cannot be updated, only accounts, capacity pools, volumes, snapshot policies and backup policies are supported", resourceID)
+	}
+
+	return nil
+}
+
+// patchANFVolume updates a volume identified by its resource id, keeping its location and tags, and waits for the update to complete
+func patchANFVolume(ctx context.Context, volumeID string, volumePropertiesPatch netapp.VolumePatchProperties) (netapp.Volume, error) {
+
+	volume, err := GetANFVolumeByID(ctx, volumeID)
+	if err != nil {
+		return netapp.Volume{}, err
+	}
+
+	future, err := UpdateANFVolume(
+		ctx,
+		*volume.Location,
+		uri.GetResourceGroup(volumeID),
+		uri.GetANFAccount(volumeID),
+		uri.GetANFCapacityPool(volumeID),
+		uri.GetANFVolume(volumeID),
+		volumePropertiesPatch,
+		volume.Tags,
+	)
+	if err != nil {
+		return netapp.Volume{}, err
+	}
+
+	volumeClient, err := getVolumesClient()
+	if err != nil {
+		return netapp.Volume{}, err
+	}
+
+	err = waitForCompletion(ctx, future.FutureAPI, volumeClient.Client, "update", volumeID)
+	if err != nil {
+		return netapp.Volume{}, fmt.Errorf("cannot get the volume update future response: %v", err)
+	}
+
+	return future.Result(volumeClient)
+}
+
+// metricAverage is the 5 minutes average of a metric starting at a point in time
+type metricAverage struct {
+	timeStamp time.Time
+	value     float64
+}
+
+// getANFMetricAverages gets the 5 minutes averages of a metric over a period of time from Azure Monitor, ordered by time
+func getANFMetricAverages(ctx context.Context, resourceID, metricName string, period time.Duration) ([]metricAverage, error) {
+
+	metricsClient, err := getMetricsClient()
+	if err != nil {
+		return nil, err
+	}
+
+	end := time.Now().UTC()
+	start := end.Add(-1 * period)
+
+	response, err := metricsClient.List(
+		ctx,
+		resourceID,
+		fmt.Sprintf("%v/%v", start.Format(time.RFC3339), end.Format(time.RFC3339)),
+		to.StringPtr("PT5M"),
+		metricName,
+		"Average",
+		nil,
+		"",
+		"",
+		insights.Data,
+		"",
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("cannot get %v metric: %v", metricName, err)
+	}
+
+	averages := []metricAverage{}
+	if response.Value != nil {
+		for _, metric := range *response.Value {
+			if metric.Timeseries == nil {
+				continue
+			}
+			for _, series := range *metric.Timeseries {
+				if series.Data == nil {
+					continue
+				}
+				for _, data := range *series.Data {
+					if data.Average == nil || data.TimeStamp == nil {
+						continue
+					}
+					averages = append(averages, metricAverage{timeStamp: data.TimeStamp.Time, value: *data.Average})
+				}
+			}
+		}
+	}
+
+	if len(averages) == 0 {
+		return nil, fmt.Errorf("no %v metric values found for %v", metricName, resourceID)
+	}
+
+	return averages, nil
+}
+
+// GetANFVolumeConsumedBytes gets the most recent logical size (consumed bytes) of a volume from Azure Monitor
+func GetANFVolumeConsumedBytes(ctx context.Context, volumeID string) (int64, error) {
+
+	averages, err := getANFMetricAverages(ctx, volumeID, volumeConsumedSizeMetric, time.Hour)
+	if err != nil {
+		return 0, err
+	}
+
+	return int64(averages[len(averages)-1].value), nil
+}
+
+// GetANFVolumePeakThroughputMibps gets the highest combined read and write throughput of a volume
+// within the last 24 hours from Azure Monitor, based on 5 minutes averages. Read and write throughputs are
+// added for each interval before the peak is taken, so peaks of each at different times are not summed.
+func GetANFVolumePeakThroughputMibps(ctx context.Context, volumeID string) (float64, error) {
+
+	// Keyed by unix time, time.Time values of the same instant are not equal when their locations differ
+	bytesPerSecond := map[int64]float64{}
+
+	for _, metricName := range []string{volumeReadThroughputMetric, volumeWriteThroughputMetric} {
+		averages, err := getANFMetricAverages(ctx, volumeID, metricName, 24*time.Hour)
+		if err != nil {
+			return 0, err
+		}
+
+		for _, average := range averages {
+			bytesPerSecond[average.timeStamp.Unix()] += average.value
+		}
+	}
+
+	var peakBytesPerSecond float64
+	for _, value := range bytesPerSecond {
+		if value > peakBytesPerSecond {
+			peakBytesPerSecond = value
+		}
+	}
+
+	return peakBytesPerSecond / 1024 / 1024, nil
+}
+
+// MoveANFVolumeToPool moves a volume to another capacity pool within the same account, e.g. to change its service level,
+// and waits until the volume is reported within the target pool. The target pool must have enough free capacity
+// for the volume quota and, if the volume uses cool access, cool access enabled.
+func MoveANFVolumeToPool(ctx context.Context, volumeID, targetPoolID string) (netapp.Volume, error) {
+
+	volume, err := GetANFVolumeByID(ctx, volumeID)
+	if err != nil {
+		return netapp.Volume{}, err
+	}
+
+	targetPool, err := GetANFCapacityPoolByID(ctx, targetPoolID)
+	if err != nil {
+		return netapp.Volume{}, err
+	}
+
+	if !strings.EqualFold(uri.GetSubscription(volumeID), uri.GetSubscription(targetPoolID)) ||
+		!strings.EqualFold(uri.GetResourceGroup(volumeID), uri.GetResourceGroup(targetPoolID)) ||
+		!strings.EqualFold(uri.GetANFAccount(volumeID), uri.GetANFAccount(targetPoolID)) {
+		return netapp.Volume{}, fmt.Errorf("target pool %v is not in the same account as volume %v", targetPoolID, volumeID)
+	}
+
+	if strings.EqualFold(uri.GetANFCapacityPool(volumeID), uri.GetANFCapacityPool(targetPoolID)) {
+		return netapp.Volume{}, fmt.Errorf("volume %v is already in capacity pool %v", uri.GetANFVolume(volumeID), uri.GetANFCapacityPool(targetPoolID))
+	}
+
+	if volume.VolumeProperties == nil || volume.UsageThreshold == nil || targetPool.PoolProperties == nil || targetPool.Size == nil {
+		return netapp.Volume{}, fmt.Errorf("cannot determine volume quota or target pool size")
+	}
+
+	if volume.CoolAccess != nil && *volume.CoolAccess && (targetPool.CoolAccess == nil || !*targetPool.CoolAccess) {
+		return netapp.Volume{}, fmt.Errorf("volume %v uses cool access but target pool %v does not support it", uri.GetANFVolume(volumeID), uri.GetANFCapacityPool(targetPoolID))
+	}
+
+	allocatedBytes, err := GetANFCapacityPoolAllocatedBytes(ctx, targetPoolID)
+	if err != nil {
+		return netapp.Volume{}, err
+	}
+
+	freeBytes := *targetPool.Size - allocatedBytes
+	if freeBytes < *volume.UsageThreshold {
+		return netapp.Volume{}, fmt.Errorf(
+			"target pool %v has %v bytes free, volume %v requires %v bytes, resize the pool first",
+			uri.GetANFCapacityPool(targetPoolID),
+			freeBytes,
+			uri.GetANFVolume(volumeID),
+			*volume.UsageThreshold,
+		)
+	}
+
+	volumeClient, err := getVolumesClient()
+	if err != nil {
+		return netapp.Volume{}, err
+	}
+
+	future, err := volumeClient.PoolChange(
+		ctx,
+		uri.GetResourceGroup(volumeID),
+		uri.GetANFAccount(volumeID),
+		uri.GetANFCapacityPool(volumeID),
+		uri.GetANFVolume(volumeID),
+		netapp.PoolChangeRequest{
+			NewPoolResourceID: to.StringPtr(targetPoolID),
+		},
+	)
+
+	if err != nil {
+		return netapp.Volume{}, fmt.Errorf("cannot change volume pool: %v", err)
+	}
+
+	err = waitForCompletion(ctx, future.FutureAPI, volumeClient.Client, "move", volumeID)
+	if err != nil {
+		return netapp.Volume{}, fmt.Errorf("cannot get the volume pool change future response: %v", err)
+	}
+
+	// Volume resource id changes with the pool, so it is only considered moved once it can be found within the target pool
+	movedVolumeID := fmt.Sprintf("%v/volumes/%v", targetPoolID, uri.GetANFVolume(volumeID))
+	err = WaitForANFResource(ctx, movedVolumeID, 10, 60, false)
+	if err != nil {
+		return netapp.Volume{}, fmt.Errorf("volume not reported within target pool: %v", err)
+	}
+
+	return GetANFVolumeByID(ctx, movedVolumeID)
+}
+
+// AuthorizeReplication - authorizes volume replication
+func AuthorizeReplication(ctx context.Context, resourceGroupName, accountName, poolName, volumeName, remoteVolumeResourceID string) error {
+
+	volumeClient, err := getVolumesClient()
+	if err != nil {
+		return err
+	}
+
+	future, err := volumeClient.AuthorizeReplication(
+		ctx,
+		resourceGroupName,
+		accountName,
+		poolName,
+		volumeName,
+		netapp.AuthorizeRequest{
+			RemoteVolumeResourceID: to.StringPtr(remoteVolumeResourceID),
+		},
+	)
+
+	if err != nil {
+		return fmt.Errorf("cannot authorize volume replication: %v", err)
+	}
+
+	err = waitForCompletion(ctx, future.FutureAPI, volumeClient.Client, "authorize replication", anfResourceID(volumeClient.SubscriptionID, resourceGroupName, accountName, "capacityPools", poolName, "volumes", volumeName))
+	if err != nil {
+		return fmt.Errorf("cannot get authorize volume replication future response: %v", err)
+	}
+
+	return nil
+}
+
+// DeleteANFVolumeReplication - authorizes volume replication
+func DeleteANFVolumeReplication(ctx context.Context, resourceGroupName, accountName, poolName, volumeName string) error {
+
+	volumeClient, err := getVolumesClient()
+	if err != nil {
+		return err
+	}
+
+	future, err := volumeClient.DeleteReplication(
+		ctx,
+		resourceGroupName,
+		accountName,
+		poolName,
+		volumeName,
+	)
+
+	if err != nil {
+		return fmt.Errorf("cannot delete volume replication: %v", err)
+	}
+
+	err = waitForCompletion(ctx, future.FutureAPI, volumeClient.Client, "delete replication", anfResourceID(volumeClient.SubscriptionID, resourceGroupName, accountName, "capacityPools", poolName, "volumes", volumeName))
+	if err != nil {
+		return fmt.Errorf("cannot get delete volume replication future response: %v", err)
+	}
+
+	return nil
+}
+
+// CreateANFSnapshot creates a Snapshot from an ANF volume. Snapshots cannot be tagged with the 2021-04-01 API version,
+// tags are accepted for consistency with the other create functions but are not applied.
+func CreateANFSnapshot(ctx context.Context, location, resourceGroupName, accountName, poolName, volumeName, snapshotName string, tags map[string]*string) (netapp.Snapshot, error) {
+
+	snapshotClient, future, err := beginANFSnapshotCreation(ctx, location, resourceGroupName, accountName, poolName, volumeName, snapshotName)
+	if err != nil {
+		return netapp.Snapshot{}, err
+	}
+
+	err = waitForCompletion(ctx, future.FutureAPI, snapshotClient.Client, "create", anfResourceID(snapshotClient.SubscriptionID, resourceGroupName, accountName, "capacityPools", poolName, "volumes", volumeName, "snapshots", snapshotName))
+	if err != nil {
+		return netapp.Snapshot{}, fmt.Errorf("cannot get the snapshot create or update future response: %v", err)
+	}
+
+	return future.Result(snapshotClient)
+}
+
+// BeginCreateANFSnapshot starts the creation of a Snapshot without waiting for it to complete, the returned future
+// can be serialized to resume polling later on, even from another process. Tags are not applied, see CreateANFSnapshot.
+func BeginCreateANFSnapshot(ctx context.Context, location, resourceGroupName, accountName, poolName, volumeName, snapshotName string, tags map[string]*string) (azure.Future, error) {
+
+	_, future, err := beginANFSnapshotCreation(ctx, location, resourceGroupName, accountName, poolName, volumeName, snapshotName)
+	if err != nil {
+		return azure.Future{}, err
+	}
+	logging.Debug("snapshot creation started", logging.Operation("create"), logging.String("snapshot", snapshotName), logging.String("pollingUrl", future.PollingURL()))
+
+	return asFuture(future.FutureAPI)
+}
+
+// beginANFSnapshotCreation sends the snapshot create request
+func beginANFSnapshotCreation(ctx context.Context, location, resourceGroupName, accountName, poolName, volumeName, snapshotName string) (netapp.SnapshotsClient, netapp.SnapshotsCreateFuture, error) {
+
+	snapshotClient, err := getSnapshotsClient()
+	if err != nil {
+		return netapp.SnapshotsClient{}, netapp.SnapshotsCreateFuture{}, err
+	}
+
+	future, err := snapshotClient.Create(
+		ctx,
+		netapp.Snapshot{
+			Location: to.StringPtr(location),
+		},
+		resourceGroupName,
+		accountName,
+		poolName,
+		volumeName,
+		snapshotName,
+	)
+
+	if err != nil {
+		return netapp.SnapshotsClient{}, netapp.SnapshotsCreateFuture{}, fmt.Errorf("cannot create snapshot: %v", err)
+	}
+
+	return snapshotClient, future, nil
+}
+
+// DeleteANFSnapshot deletes a Snapshot from an ANF volume
+func DeleteANFSnapshot(ctx context.Context, resourceGroupName, accountName, poolName, volumeName, snapshotName string) error {
+
+	snapshotClient, err := getSnapshotsClient()
+	if err != nil {
+		return err
+	}
+
+	future, err := snapshotClient.Delete(
+		ctx,
+		resourceGroupName,
+		accountName,
+		poolName,
+		volumeName,
+		snapshotName,
+	)
+
+	if err != nil {
+		return fmt.Errorf("cannot delete snapshot: %v", err)
+	}
+
+	err = waitForCompletion(ctx, future.FutureAPI, snapshotClient.Client, "delete", anfResourceID(snapshotClient.SubscriptionID, resourceGroupName, accountName, "capacityPools", poolName, "volumes", volumeName, "snapshots", snapshotName))
+	if err != nil {
+		return fmt.Errorf("cannot get the snapshot delete future response: %v", err)
+	}
+
+	return nil
+}
+
+// GetANFVolumeByID gets a volume from its resource id
+func GetANFVolumeByID(ctx context.Context, volumeID string) (netapp.Volume, error) {
+
+	if !uri.IsANFVolume(volumeID) {
+		return netapp.Volume{}, fmt.Errorf("resource id %v is not a volume", volumeID)
+	}
+
+	volumeClient, err := getVolumesClient()
+	if err != nil {
+		return netapp.Volume{}, err
+	}
+
+	volume, err := volumeClient.Get(
+		ctx,
+		uri.GetResourceGroup(volumeID),
+		uri.GetANFAccount(volumeID),
+		uri.GetANFCapacityPool(volumeID),
+		uri.GetANFVolume(volumeID),
+	)
+
+	if err != nil {
+		return netapp.Volume{}, fmt.Errorf("cannot get volume: %v", err)
+	}
+
+	return volume, nil
+}
+
+// GetANFSnapshotByID gets a snapshot from its resource id
+func GetANFSnapshotByID(ctx context.Context, snapshotID string) (netapp.Snapshot, error) {
+
+	if !uri.IsANFSnapshot(snapshotID) {
+		return netapp.Snapshot{}, fmt.Errorf("resource id %v is not a snapshot", snapshotID)
+	}
+
+	snapshotClient, err := getSnapshotsClient()
+	if err != nil {
+		return netapp.Snapshot{}, err
+	}
+
+	snapshot, err := snapshotClient.Get(
+		ctx,
+		uri.GetResourceGroup(snapshotID),
+		uri.GetANFAccount(snapshotID),
+		uri.GetANFCapacityPool(snapshotID),
+		uri.GetANFVolume(snapshotID),
+		uri.GetANFSnapshot(snapshotID),
+	)
+
+	if err != nil {
+		return netapp.Snapshot{}, fmt.Errorf("cannot get snapshot: %v", err)
+	}
+
+	return snapshot, nil
+}
+
+// ListANFSnapshots lists all snapshots of a volume
+func ListANFSnapshots(ctx context.Context, volumeID string) ([]netapp.Snapshot, error) {
+
+	if !uri.IsANFVolume(volumeID) {
+		return nil, fmt.Errorf("resource id %v is not a volume", volumeID)
+	}
+
+	snapshotClient, err := getSnapshotsClient()
+	if err != nil {
+		return nil, err
+	}
+
+	snapshotList, err := snapshotClient.List(
+		ctx,
+		uri.GetResourceGroup(volumeID),
+		uri.GetANFAccount(volumeID),
+		uri.GetANFCapacityPool(volumeID),
+		uri.GetANFVolume(volumeID),
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("cannot list snapshots: %v", err)
+	}
+
+	if snapshotList.Value == nil {
+		return []netapp.Snapshot{}, nil
+	}
+
+	return *snapshotList.Value, nil
+}
+
+// GetANFSnapshotsNewerThan returns the snapshots of a volume that were created after the provided snapshot,
+// these are the ones that are lost when the volume is reverted to that snapshot
+func GetANFSnapshotsNewerThan(ctx context.Context, volumeID, snapshotID string) ([]netapp.Snapshot, error) {
+
+	if !strings.HasPrefix(strings.ToLower(snapshotID), strings.ToLower(fmt.Sprintf("%v/snapshots/", volumeID))) {
+		return nil, fmt.Errorf("snapshot %v does not belong to volume %v", snapshotID, volumeID)
+	}
+
+	snapshot, err := GetANFSnapshotByID(ctx, snapshotID)
+	if err != nil {
+		return nil, err
+	}
+
+	if snapshot.SnapshotProperties == nil || snapshot.Created == nil {
+		return nil, fmt.Errorf("cannot determine creation date of snapshot %v", snapshotID)
+	}
+
+	snapshots, err := ListANFSnapshots(ctx, volumeID)
+	if err != nil {
+		return nil, err
+	}
+
+	newerSnapshots := []netapp.Snapshot{}
+	for _, s := range snapshots {
+		if s.SnapshotProperties != nil && s.Created != nil && s.Created.After(snapshot.Created.Time) {
+			newerSnapshots = append(newerSnapshots, s)
+		}
+	}
+
+	return newerSnapshots, nil
+}
+
+// RevertANFVolume reverts a volume in place to the state of one of its snapshots.
+// This is destructive: changes made after the snapshot and every snapshot newer than it are lost,
+// these snapshots are reported before the operation starts.
+func RevertANFVolume(ctx context.Context, volumeID, snapshotID string) error {
+
+	if !uri.IsANFSnapshot(snapshotID) {
+		return fmt.Errorf("resource id %v is not a snapshot", snapshotID)
+	}
+
+	snapshotVolumeID := snapshotID[:strings.LastIndex(strings.ToLower(snapshotID), "/snapshots/")]
+	if !strings.EqualFold(snapshotVolumeID, strings.TrimSuffix(volumeID, "/")) {
+		return fmt.Errorf("snapshot %v does not belong to volume %v", snapshotID, volumeID)
+	}
+
+	_, err := GetANFVolumeByID(ctx, volumeID)
+	if err != nil {
+		return err
+	}
+
+	newerSnapshots, err := GetANFSnapshotsNewerThan(ctx, volumeID, snapshotID)
+	if err != nil {
+		return err
+	}
+
+	for _, s := range newerSnapshots {
+		logging.Warn(fmt.Sprintf("snapshot %v is newer than %v and will be lost", uri.GetANFSnapshot(*s.ID), uri.GetANFSnapshot(snapshotID)), logging.Operation("revert"), logging.ResourceID(*s.ID))
+	}
+
+	volumeClient, err := getVolumesClient()
+	if err != nil {
+		return err
+	}
+
+	future, err := volumeClient.Revert(
+		ctx,
+		uri.GetResourceGroup(volumeID),
+		uri.GetANFAccount(volumeID),
+		uri.GetANFCapacityPool(volumeID),
+		uri.GetANFVolume(volumeID),
+		netapp.VolumeRevert{
+			SnapshotID: to.StringPtr(snapshotID),
+		},
+	)
+
+	if err != nil {
+		return fmt.Errorf("cannot revert volume: %v", err)
+	}
+
+	err = waitForCompletion(ctx, future.FutureAPI, volumeClient.Client, "revert", volumeID)
+	if err != nil {
+		return fmt.Errorf("cannot get the volume revert future response: %v", err)
+	}
+
+	return nil
+}
+
+// CreateANFSnapshotPolicy creates a Snapshot Policy to be used on volumes
+func CreateANFSnapshotPolicy(ctx context.Context, resourceGroupName, accountName, policyName string, policy netapp.SnapshotPolicy) (netapp.SnapshotPolicy, error) {
+
+	snapshotPolicyClient, err := getSnapshotPoliciesClient()
+	if err != nil {
+		return netapp.SnapshotPolicy{}, err
+	}
+
+	snapshotPolicy, err := snapshotPolicyClient.Create(
+		ctx,
+		policy,
+		resourceGroupName,
+		accountName,
+		policyName,
+	)
+
+	if err != nil {
+		return netapp.SnapshotPolicy{}, fmt.Errorf("cannot create snapshot policy: %v", err)
+	}
+
+	return snapshotPolicy, nil
+}
+
+// UpdateANFSnapshotPolicy update an ANF volume
+func UpdateANFSnapshotPolicy(ctx context.Context, resourceGroupName, accountName, policyName string, snapshotPolicyPatch netapp.SnapshotPolicyPatch) (netapp.SnapshotPoliciesUpdateFuture, error) {
+
+	snapshotPolicyClient, err := getSnapshotPoliciesClient()
+	if err != nil {
+		return netapp.SnapshotPoliciesUpdateFuture{}, err
+	}
+
+	snapshotPolicy, err := snapshotPolicyClient.Update(
+		ctx,
+		snapshotPolicyPatch,
+		resourceGroupName,
+		accountName,
+		policyName,
+	)
+
+	if err != nil {
+		return netapp.SnapshotPoliciesUpdateFuture{}, fmt.Errorf("cannot update snapshot policy: %v", err)
+	}
+
+	return snapshotPolicy, nil
+}
+
+// ListANFSnapshotPolicies lists all snapshot policies of an account
+func ListANFSnapshotPolicies(ctx context.Context, resourceGroupName, accountName string) ([]netapp.SnapshotPolicy, error) {
+
+	snapshotPolicyClient, err := getSnapshotPoliciesClient()
+	if err != nil {
+		return nil, err
+	}
+
+	snapshotPolicyList, err := snapshotPolicyClient.List(
+		ctx,
+		resourceGroupName,
+		accountName,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("cannot list snapshot policies: %v", err)
 	}
 
-	future, err := volumeClient.CreateOrUpdate(
+	if snapshotPolicyList.Value == nil {
+		return []netapp.SnapshotPolicy{}, nil
+	}
+
+	return *snapshotPolicyList.Value, nil
+}
+
+// CreateANFBackupPolicy creates a Backup Policy with daily, weekly and monthly retention to be used on volumes
+func CreateANFBackupPolicy(ctx context.Context, location, resourceGroupName, accountName, policyName string, dailyBackupsToKeep, weeklyBackupsToKeep, monthlyBackupsToKeep int32, tags map[string]*string) (netapp.BackupPolicy, error) {
+
+	backupPolicyClient, err := getBackupPoliciesClient()
+	if err != nil {
+		return netapp.BackupPolicy{}, err
+	}
+
+	future, err := backupPolicyClient.Create(
 		ctx,
-		netapp.Volume{
-			Location:         to.StringPtr(location),
-			Tags:             tags,
-			VolumeProperties: &volumeProperties,
-		},
 		resourceGroupName,
 		accountName,
-		poolName,
-		volumeName,
+		policyName,
+		netapp.BackupPolicy{
+			Location: to.StringPtr(location),
+			Tags:     tags,
+			BackupPolicyProperties: &netapp.BackupPolicyProperties{
+				DailyBackupsToKeep:   to.Int32Ptr(dailyBackupsToKeep),
+				WeeklyBackupsToKeep:  to.Int32Ptr(weeklyBackupsToKeep),
+				MonthlyBackupsToKeep: to.Int32Ptr(monthlyBackupsToKeep),
+				Enabled:              to.BoolPtr(true),
+			},
+		},
 	)
 
 	if err != nil {
-		return netapp.Volume{}, fmt.Errorf("cannot create volume: %v", err)
+		return netapp.BackupPolicy{}, fmt.Errorf("cannot create backup policy: %v", err)
 	}
 
-	err = future.WaitForCompletionRef(ctx, volumeClient.Client)
+	err = waitForCompletion(ctx, future.FutureAPI, backupPolicyClient.Client, "create", anfResourceID(backupPolicyClient.SubscriptionID, resourceGroupName, accountName, "backupPolicies", policyName))
 	if err != nil {
-		return netapp.Volume{}, fmt.Errorf("cannot get the volume create or update future response: %v", err)
+		return netapp.BackupPolicy{}, fmt.Errorf("cannot get the backup policy create future response: %v", err)
 	}
 
-	return future.Result(volumeClient)
+	return future.Result(backupPolicyClient)
 }
 
-// UpdateANFVolume update an ANF volume
-func UpdateANFVolume(ctx context.Context, location, resourceGroupName, accountName, poolName, volumeName string, volumePropertiesPatch netapp.VolumePatchProperties, tags map[string]*string) (netapp.VolumesUpdateFuture, error) {
+// ListANFBackupPolicies lists all backup policies of an account
+func ListANFBackupPolicies(ctx context.Context, resourceGroupName, accountName string) ([]netapp.BackupPolicy, error) {
 
-	volumeClient, err := getVolumesClient()
+	backupPolicyClient, err := getBackupPoliciesClient()
 	if err != nil {
-		return netapp.VolumesUpdateFuture{}, err
+		return nil, err
 	}
 
-	volume, err := volumeClient.Update(
+	backupPolicyList, err := backupPolicyClient.List(
 		ctx,
-		netapp.VolumePatch{
-			Location:              to.StringPtr(location),
-			Tags:                  tags,
-			VolumePatchProperties: &volumePropertiesPatch,
-		},
 		resourceGroupName,
 		accountName,
-		poolName,
-		volumeName,
 	)
 
 	if err != nil {
-		return netapp.VolumesUpdateFuture{}, fmt.Errorf("cannot update volume: %v", err)
+		return nil, fmt.Errorf("cannot list backup policies: %v", err)
 	}
 
-	return volume, nil
+	if backupPolicyList.Value == nil {
+		return []netapp.BackupPolicy{}, nil
+	}
+
+	return *backupPolicyList.Value, nil
 }
 
-// AuthorizeReplication - authorizes volume replication
-func AuthorizeReplication(ctx context.Context, resourceGroupName, accountName, poolName, volumeName, remoteVolumeResourceID string) error {
+// DeleteANFBackupPolicy deletes a backup policy
+func DeleteANFBackupPolicy(ctx context.Context, resourceGroupName, accountName, policyName string) error {
 
-	volumeClient, err := getVolumesClient()
+	backupPolicyClient, err := getBackupPoliciesClient()
 	if err != nil {
 		return err
 	}
 
-	future, err := volumeClient.AuthorizeReplication(
+	future, err := backupPolicyClient.Delete(
 		ctx,
 		resourceGroupName,
 		accountName,
-		poolName,
-		volumeName,
-		netapp.AuthorizeRequest{
-			RemoteVolumeResourceID: to.StringPtr(remoteVolumeResourceID),
-		},
+		policyName,
 	)
 
 	if err != nil {
-		return fmt.Errorf("cannot authorize volume replication: %v", err)
+		return fmt.Errorf("cannot delete backup policy: %v", err)
 	}
 
-	err = future.WaitForCompletionRef(ctx, volumeClient.Client)
+	err = waitForCompletion(ctx, future.FutureAPI, backupPolicyClient.Client, "delete", anfResourceID(backupPolicyClient.SubscriptionID, resourceGroupName, accountName, "backupPolicies", policyName))
 	if err != nil {
-		return fmt.Errorf("cannot get authorize volume replication future response: %v", err)
+		return fmt.Errorf("cannot get the backup policy delete future response: %v", err)
 	}
 
 	return nil
 }
 
-// DeleteANFVolumeReplication - authorizes volume replication
-func DeleteANFVolumeReplication(ctx context.Context, resourceGroupName, accountName, poolName, volumeName string) error {
+// GetANFBackupVaultID gets the resource id of the backup vault of an account, required to enable backups on volumes
+func GetANFBackupVaultID(ctx context.Context, resourceGroupName, accountName string) (string, error) {
 
-	volumeClient, err := getVolumesClient()
+	vaultClient, err := getVaultsClient()
 	if err != nil {
-		return err
+		return "", err
 	}
 
-	future, err := volumeClient.DeleteReplication(
+	vaultList, err := vaultClient.List(
 		ctx,
 		resourceGroupName,
 		accountName,
-		poolName,
-		volumeName,
 	)
 
 	if err != nil {
-		return fmt.Errorf("cannot delete volume replication: %v", err)
+		return "", fmt.Errorf("cannot list backup vaults: %v", err)
+	}
+
+	if vaultList.Value == nil || len(*vaultList.Value) == 0 {
+		return "", fmt.Errorf("no backup vault found for account %v", accountName)
+	}
+
+	vaultID := (*vaultList.Value)[0].ID
+	if vaultID == nil {
+		return "", fmt.Errorf("the backup vault of account %v has no resource id", accountName)
+	}
+
+	return *vaultID, nil
+}
+
+// EnableANFVolumeBackup enables backups on a volume, assigning it a backup policy
+func EnableANFVolumeBackup(ctx context.Context, location, resourceGroupName, accountName, poolName, volumeName, backupPolicyID string, tags map[string]*string) (netapp.Volume, error) {
+
+	vaultID, err := GetANFBackupVaultID(ctx, resourceGroupName, accountName)
+	if err != nil {
+		return netapp.Volume{}, err
+	}
+
+	volumeChanges := netapp.VolumePatchProperties{
+		DataProtection: &netapp.VolumePatchPropertiesDataProtection{
+			Backup: &netapp.VolumeBackupProperties{
+				BackupEnabled:  to.BoolPtr(true),
+				BackupPolicyID: to.StringPtr(backupPolicyID),
+				PolicyEnforced: to.BoolPtr(true),
+				VaultID:        to.StringPtr(vaultID),
+			},
+		},
 	}
 
-	err = future.WaitForCompletionRef(ctx, volumeClient.Client)
+	future, err := UpdateANFVolume(ctx, location, resourceGroupName, accountName, poolName, volumeName, volumeChanges, tags)
 	if err != nil {
-		return fmt.Errorf("cannot get delete volume replication future response: %v", err)
+		return netapp.Volume{}, err
 	}
 
-	return nil
+	volumeClient, err := getVolumesClient()
+	if err != nil {
+		return netapp.Volume{}, err
+	}
+
+	err = waitForCompletion(ctx, future.FutureAPI, volumeClient.Client, "update", anfResourceID(volumeClient.SubscriptionID, resourceGroupName, accountName, "capacityPools", poolName, "volumes", volumeName))
+	if err != nil {
+		return netapp.Volume{}, fmt.Errorf("cannot get the volume update future response: %v", err)
+	}
+
+	return future.Result(volumeClient)
 }
 
-// CreateANFSnapshot creates a Snapshot from an ANF volume
-func CreateANFSnapshot(ctx context.Context, location, resourceGroupName, accountName, poolName, volumeName, snapshotName string, tags map[string]*string) (netapp.Snapshot, error) {
+// CreateANFBackup creates an on-demand backup of an ANF volume
+func CreateANFBackup(ctx context.Context, location, resourceGroupName, accountName, poolName, volumeName, backupName, label string) (netapp.Backup, error) {
 
-	snapshotClient, err := getSnapshotsClient()
+	backupClient, err := getBackupsClient()
 	if err != nil {
-		return netapp.Snapshot{}, err
+		return netapp.Backup{}, err
 	}
 
-	future, err := snapshotClient.Create(
+	future, err := backupClient.Create(
 		ctx,
-		netapp.Snapshot{
-			Location: to.StringPtr(location),
-		},
 		resourceGroupName,
 		accountName,
 		poolName,
 		volumeName,
-		snapshotName,
+		backupName,
+		netapp.Backup{
+			Location: to.StringPtr(location),
+			BackupProperties: &netapp.BackupProperties{
+				Label: map[bool]*string{true: to.StringPtr(label), false: nil}[label != ""],
+			},
+		},
 	)
 
 	if err != nil {
-		return netapp.Snapshot{}, fmt.Errorf("cannot create snapshot: %v", err)
+		return netapp.Backup{}, fmt.Errorf("cannot create backup: %v", err)
 	}
 
-	err = future.WaitForCompletionRef(ctx, snapshotClient.Client)
+	err = waitForCompletion(ctx, future.FutureAPI, backupClient.Client, "create", anfResourceID(backupClient.SubscriptionID, resourceGroupName, accountName, "capacityPools", poolName, "volumes", volumeName, "backups", backupName))
 	if err != nil {
-		return netapp.Snapshot{}, fmt.Errorf("cannot get the snapshot create or update future response: %v", err)
+		return netapp.Backup{}, fmt.Errorf("cannot get the backup create future response: %v", err)
 	}
 
-	return future.Result(snapshotClient)
+	return future.Result(backupClient)
 }
 
-// DeleteANFSnapshot deletes a Snapshot from an ANF volume
-func DeleteANFSnapshot(ctx context.Context, resourceGroupName, accountName, poolName, volumeName, snapshotName string) error {
+// ListANFBackups lists all backups of a volume, each one with its size and creation date
+func ListANFBackups(ctx context.Context, resourceGroupName, accountName, poolName, volumeName string) ([]netapp.Backup, error) {
 
-	snapshotClient, err := getSnapshotsClient()
+	backupClient, err := getBackupsClient()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	future, err := snapshotClient.Delete(
+	backupList, err := backupClient.List(
 		ctx,
 		resourceGroupName,
 		accountName,
 		poolName,
 		volumeName,
-		snapshotName,
 	)
 
 	if err != nil {
-		return fmt.Errorf("cannot delete snapshot: %v", err)
+		return nil, fmt.Errorf("cannot list backups: %v", err)
 	}
 
-	err = future.WaitForCompletionRef(ctx, snapshotClient.Client)
-	if err != nil {
-		return fmt.Errorf("cannot get the snapshot delete future response: %v", err)
+	if backupList.Value == nil {
+		return []netapp.Backup{}, nil
 	}
 
-	return nil
+	return *backupList.Value, nil
 }
 
-// CreateANFSnapshotPolicy creates a Snapshot Policy to be used on volumes
-func CreateANFSnapshotPolicy(ctx context.Context, resourceGroupName, accountName, policyName string, policy netapp.SnapshotPolicy) (netapp.SnapshotPolicy, error) {
+// DeleteANFBackup deletes a backup from an ANF volume
+func DeleteANFBackup(ctx context.Context, resourceGroupName, accountName, poolName, volumeName, backupName string) error {
 
-	snapshotPolicyClient, err := getSnapshotPoliciesClient()
+	backupClient, err := getBackupsClient()
 	if err != nil {
-		return netapp.SnapshotPolicy{}, err
+		return err
 	}
 
-	snapshotPolicy, err := snapshotPolicyClient.Create(
+	future, err := backupClient.Delete(
 		ctx,
-		policy,
 		resourceGroupName,
 		accountName,
-		policyName,
+		poolName,
+		volumeName,
+		backupName,
 	)
 
 	if err != nil {
-		return netapp.SnapshotPolicy{}, fmt.Errorf("cannot create snapshot policy: %v", err)
+		return fmt.Errorf("cannot delete backup: %v", err)
 	}
 
-	return snapshotPolicy, nil
+	err = waitForCompletion(ctx, future.FutureAPI, backupClient.Client, "delete", anfResourceID(backupClient.SubscriptionID, resourceGroupName, accountName, "capacityPools", poolName, "volumes", volumeName, "backups", backupName))
+	if err != nil {
+		return fmt.Errorf("cannot get the backup delete future response: %v", err)
+	}
+
+	return nil
 }
 
-// UpdateANFSnapshotPolicy update an ANF volume
-func UpdateANFSnapshotPolicy(ctx context.Context, resourceGroupName, accountName, policyName string, snapshotPolicyPatch netapp.SnapshotPolicyPatch) (netapp.SnapshotPoliciesUpdateFuture, error) {
+// DeleteANFAccountBackup deletes an account backup, i.e. a backup listed at the account level such as the backups of a deleted volume
+func DeleteANFAccountBackup(ctx context.Context, resourceGroupName, accountName, backupName string) error {
 
-	snapshotPolicyClient, err := getSnapshotPoliciesClient()
+	accountBackupClient, err := getAccountBackupsClient()
 	if err != nil {
-		return netapp.SnapshotPoliciesUpdateFuture{}, err
+		return err
 	}
 
-	snapshotPolicy, err := snapshotPolicyClient.Update(
+	future, err := accountBackupClient.Delete(
 		ctx,
-		snapshotPolicyPatch,
 		resourceGroupName,
 		accountName,
-		policyName,
+		backupName,
 	)
 
 	if err != nil {
-		return netapp.SnapshotPoliciesUpdateFuture{}, fmt.Errorf("cannot update snapshot policy: %v", err)
+		return fmt.Errorf("cannot delete account backup: %v", err)
 	}
 
-	return snapshotPolicy, nil
+	err = waitForCompletion(ctx, future.FutureAPI, accountBackupClient.Client, "delete", anfResourceID(accountBackupClient.SubscriptionID, resourceGroupName, accountName, "accountBackups", backupName))
+	if err != nil {
+		return fmt.Errorf("cannot get the account backup delete future response: %v", err)
+	}
+
+	return nil
 }
 
 // DeleteANFVolume deletes a volume
@@ -540,7 +2382,7 @@ func DeleteANFVolume(ctx context.Context, resourceGroupName, accountName, poolNa
 		return fmt.Errorf("cannot delete volume: %v", err)
 	}
 
-	err = future.WaitForCompletionRef(ctx, volumesClient.Client)
+	err = waitForCompletion(ctx, future.FutureAPI, volumesClient.Client, "delete", anfResourceID(volumesClient.SubscriptionID, resourceGroupName, accountName, "capacityPools", poolName, "volumes", volumeName))
 	if err != nil {
 		return fmt.Errorf("cannot get the volume delete future response: %v", err)
 	}
@@ -548,6 +2390,68 @@ func DeleteANFVolume(ctx context.Context, resourceGroupName, accountName, poolNa
 	return nil
 }
 
+// BeginDeleteANFResource starts the deletion of an account, capacity pool, volume or snapshot without waiting for
+// it to complete, the returned future can be serialized to resume polling later on, even from another process
+func BeginDeleteANFResource(ctx context.Context, resourceID string) (azure.Future, error) {
+
+	resourceGroupName := uri.GetResourceGroup(resourceID)
+	accountName := uri.GetANFAccount(resourceID)
+
+	var futureAPI azure.FutureAPI
+	switch {
+	case uri.IsANFSnapshot(resourceID):
+		snapshotsClient, err := getSnapshotsClient()
+		if err != nil {
+			return azure.Future{}, err
+		}
+		future, err := snapshotsClient.Delete(ctx, resourceGroupName, accountName, uri.GetANFCapacityPool(resourceID), uri.GetANFVolume(resourceID), uri.GetANFSnapshot(resourceID))
+		if err != nil {
+			return azure.Future{}, fmt.Errorf("cannot delete snapshot: %v", err)
+		}
+		futureAPI = future.FutureAPI
+	case uri.IsANFVolume(resourceID):
+		volumesClient, err := getVolumesClient()
+		if err != nil {
+			return azure.Future{}, err
+		}
+		future, err := volumesClient.Delete(ctx, resourceGroupName, accountName, uri.GetANFCapacityPool(resourceID), uri.GetANFVolume(resourceID))
+		if err != nil {
+			return azure.Future{}, fmt.Errorf("cannot delete volume: %v", err)
+		}
+		futureAPI = future.FutureAPI
+	case uri.IsANFCapacityPool(resourceID):
+		poolsClient, err := getPoolsClient()
+		if err != nil {
+			return azure.Future{}, err
+		}
+		future, err := poolsClient.Delete(ctx, resourceGroupName, accountName, uri.GetANFCapacityPool(resourceID))
+		if err != nil {
+			return azure.Future{}, fmt.Errorf("cannot delete capacity pool: %v", err)
+		}
+		futureAPI = future.FutureAPI
+	case uri.IsANFAccount(resourceID):
+		accountsClient, err := getAccountsClient()
+		if err != nil {
+			return azure.Future{}, err
+		}
+		future, err := accountsClient.Delete(ctx, resourceGroupName, accountName)
+		if err != nil {
+			return azure.Future{}, fmt.Errorf("cannot delete account: %v", err)
+		}
+		futureAPI = future.FutureAPI
+	default:
+		return azure.Future{}, fmt.Errorf("resource id %v is not an account, capacity pool, volume or snapshot", resourceID)
+	}
+
+	future, err := asFuture(futureAPI)
+	if err != nil {
+		return azure.Future{}, err
+	}
+	logging.Debug("deletion started", logging.Operation("delete"), logging.ResourceID(resourceID), logging.String("pollingUrl", future.PollingURL()))
+
+	return future, nil
+}
+
 // DeleteANFCapacityPool deletes a capacity pool
 func DeleteANFCapacityPool(ctx context.Context, resourceGroupName, accountName, poolName string) error {
 
@@ -567,7 +2471,7 @@ func DeleteANFCapacityPool(ctx context.Context, resourceGroupName, accountName,
 		return fmt.Errorf("cannot delete capacity pool: %v", err)
 	}
 
-	err = future.WaitForCompletionRef(ctx, poolsClient.Client)
+	err = waitForCompletion(ctx, future.FutureAPI, poolsClient.Client, "delete", anfResourceID(poolsClient.SubscriptionID, resourceGroupName, accountName, "capacityPools", poolName))
 	if err != nil {
 		return fmt.Errorf("cannot get the capacity pool delete future response: %v", err)
 	}
@@ -594,7 +2498,7 @@ func DeleteANFSnapshotPolicy(ctx context.Context, resourceGroupName, accountName
 		return fmt.Errorf("cannot delete snapshot policy: %v", err)
 	}
 
-	err = future.WaitForCompletionRef(ctx, snapshotPolicyClient.Client)
+	err = waitForCompletion(ctx, future.FutureAPI, snapshotPolicyClient.Client, "delete", anfResourceID(snapshotPolicyClient.SubscriptionID, resourceGroupName, accountName, "snapshotPolicies", policyName))
 	if err != nil {
 		return fmt.Errorf("cannot get the snapshot policy delete future response: %v", err)
 	}
@@ -620,7 +2524,7 @@ func DeleteANFAccount(ctx context.Context, resourceGroupName, accountName string
 		return fmt.Errorf("cannot delete account: %v", err)
 	}
 
-	err = future.WaitForCompletionRef(ctx, accountsClient.Client)
+	err = waitForCompletion(ctx, future.FutureAPI, accountsClient.Client, "delete", anfResourceID(accountsClient.SubscriptionID, resourceGroupName, accountName))
 	if err != nil {
 		return fmt.Errorf("cannot get the account delete future response: %v", err)
 	}
@@ -628,12 +2532,98 @@ func DeleteANFAccount(ctx context.Context, resourceGroupName, accountName string
 	return nil
 }
 
+// waitForCompletion waits for a long-running operation with the same polling delays and retries as
+// WaitForCompletionRef and reports each poll to the default progress monitor
+func waitForCompletion(ctx context.Context, future azure.FutureAPI, client autorest.Client, operation, resourceID string) error {
+
+	tracker := progress.Start(operation, resourceID, "", time.Now())
+
+	// Polls go through the client, inspecting its requests counts them without changing how polling works
+	inspector := client.RequestInspector
+	client.RequestInspector = func(p autorest.Preparer) autorest.Preparer {
+		if inspector != nil {
+			p = inspector(p)
+		}
+		return autorest.PreparerFunc(func(r *http.Request) (*http.Request, error) {
+			// The status is the one of the last response, e.g. the provisioning state of the resource
+			// returned when the operation started or the status of the previous poll
+			tracker.Poll(future.Status())
+			return p.Prepare(r)
+		})
+	}
+
+	err := future.WaitForCompletionRef(ctx, client)
+	tracker.Done(err)
+
+	return err
+}
+
+// resourceGroupID returns the resource id of a resource group
+func resourceGroupID(subscriptionID, resourceGroupName string) string {
+	return fmt.Sprintf("/subscriptions/%v/resourceGroups/%v", subscriptionID, resourceGroupName)
+}
+
+// anfResourceID returns the resource id of an account, or of one of its children when the segments below the account are provided,
+// e.g. "capacityPools", poolName
+func anfResourceID(subscriptionID, resourceGroupName, accountName string, children ...string) string {
+	accountID := fmt.Sprintf("%v/providers/Microsoft.NetApp/netAppAccounts/%v", resourceGroupID(subscriptionID, resourceGroupName), accountName)
+	return strings.Join(append([]string{accountID}, children...), "/")
+}
+
+// asFuture returns the poller of a long-running operation so it can be serialized, the SDK futures always wrap an
+// azure.Future but the interface does not guarantee it
+func asFuture(futureAPI azure.FutureAPI) (azure.Future, error) {
+
+	future, ok := futureAPI.(*azure.Future)
+	if !ok || future == nil {
+		return azure.Future{}, fmt.Errorf("unexpected future type %T, the operation cannot be tracked", futureAPI)
+	}
+
+	return *future, nil
+}
+
+// PollANFOperation checks once if a long-running operation started by one of the Begin functions is done,
+// an error is returned when polling fails or the operation itself failed
+func PollANFOperation(ctx context.Context, future *azure.Future) (bool, error) {
+
+	client, err := getOperationsClient()
+	if err != nil {
+		return false, err
+	}
+
+	done, err := future.DoneWithContext(ctx, client)
+	if err != nil {
+		return done, fmt.Errorf("cannot poll operation: %v", err)
+	}
+	logging.Debug("operation polled", logging.String("pollingUrl", future.PollingURL()), logging.String("status", future.Status()))
+
+	return done, nil
+}
+
+// WaitForANFOperation polls a long-running operation started by one of the Begin functions until it completes
+func WaitForANFOperation(ctx context.Context, future *azure.Future) error {
+
+	client, err := getOperationsClient()
+	if err != nil {
+		return err
+	}
+
+	err = future.WaitForCompletionRef(ctx, client)
+	if err != nil {
+		return fmt.Errorf("cannot get the operation future response: %v", err)
+	}
+
+	return nil
+}
+
 // WaitForNoANFResource waits for a specified resource to don't exist anymore following a deletion.
 // This is due to a known issue related to ARM Cache where the state of the resource is still cached within ARM infrastructure
 // reporting that it still exists so looping into a get process will return 404 as soon as the cached state expires
 func WaitForNoANFResource(ctx context.Context, resourceID string, intervalInSec int, retries int, checkForReplication bool) error {
 
 	var err error
+	start := time.Now()
+	tracker := progress.Start("wait for deletion of", resourceID, "", start)
 
 	for i := 0; i < retries; i++ {
 		time.Sleep(time.Duration(intervalInSec) * time.Second)
@@ -647,6 +2637,24 @@ func WaitForNoANFResource(ctx context.Context, resourceID string, intervalInSec
 				uri.GetANFVolume(resourceID),
 				uri.GetANFSnapshot(resourceID),
 			)
+		} else if uri.IsANFBackup(resourceID) {
+			client, _ := getBackupsClient()
+			_, err = client.Get(
+				ctx,
+				uri.GetResourceGroup(resourceID),
+				uri.GetANFAccount(resourceID),
+				uri.GetANFCapacityPool(resourceID),
+				uri.GetANFVolume(resourceID),
+				uri.GetANFBackup(resourceID),
+			)
+		} else if uri.IsANFAccountBackup(resourceID) {
+			client, _ := getAccountBackupsClient()
+			_, err = client.Get(
+				ctx,
+				uri.GetResourceGroup(resourceID),
+				uri.GetANFAccount(resourceID),
+				uri.GetANFAccountBackup(resourceID),
+			)
 		} else if uri.IsANFVolume(resourceID) {
 			client, _ := getVolumesClient()
 			if !checkForReplication {
@@ -682,6 +2690,14 @@ func WaitForNoANFResource(ctx context.Context, resourceID string, intervalInSec
 				uri.GetANFAccount(resourceID),
 				uri.GetANFSnapshotPolicy(resourceID),
 			)
+		} else if uri.IsANFBackupPolicy(resourceID) {
+			client, _ := getBackupPoliciesClient()
+			_, err = client.Get(
+				ctx,
+				uri.GetResourceGroup(resourceID),
+				uri.GetANFAccount(resourceID),
+				uri.GetANFBackupPolicy(resourceID),
+			)
 		} else if uri.IsANFAccount(resourceID) {
 			client, _ := getAccountsClient()
 			_, err = client.Get(
@@ -693,17 +2709,28 @@ func WaitForNoANFResource(ctx context.Context, resourceID string, intervalInSec
 
 		// In this case error is expected
 		if err != nil {
+			tracker.Done(nil)
+			logging.Debug("resource no longer exists", logging.Operation("wait"), logging.ResourceID(resourceID), logging.Duration(time.Since(start)))
 			return nil
 		}
+
+		// The resource can be gone by now, its provisioning state is only informative
+		provisioningState, _ := GetANFProvisioningState(ctx, resourceID)
+		tracker.Poll(provisioningState)
 	}
 
-	return fmt.Errorf("exceeded number of retries: %v", retries)
+	err = fmt.Errorf("exceeded number of retries: %v", retries)
+	tracker.Done(err)
+
+	return err
 }
 
 // WaitForANFResource waits for a specified resource to be fully ready following a creation operation.
 func WaitForANFResource(ctx context.Context, resourceID string, intervalInSec int, retries int, checkForReplication bool) error {
 
 	var err error
+	start := time.Now()
+	tracker := progress.Start("wait for", resourceID, "", start)
 
 	for i := 0; i < retries; i++ {
 		time.Sleep(time.Duration(intervalInSec) * time.Second)
@@ -717,6 +2744,24 @@ func WaitForANFResource(ctx context.Context, resourceID string, intervalInSec in
 				uri.GetANFVolume(resourceID),
 				uri.GetANFSnapshot(resourceID),
 			)
+		} else if uri.IsANFBackup(resourceID) {
+			client, _ := getBackupsClient()
+			_, err = client.Get(
+				ctx,
+				uri.GetResourceGroup(resourceID),
+				uri.GetANFAccount(resourceID),
+				uri.GetANFCapacityPool(resourceID),
+				uri.GetANFVolume(resourceID),
+				uri.GetANFBackup(resourceID),
+			)
+		} else if uri.IsANFAccountBackup(resourceID) {
+			client, _ := getAccountBackupsClient()
+			_, err = client.Get(
+				ctx,
+				uri.GetResourceGroup(resourceID),
+				uri.GetANFAccount(resourceID),
+				uri.GetANFAccountBackup(resourceID),
+			)
 		} else if uri.IsANFVolume(resourceID) {
 			client, _ := getVolumesClient()
 			if !checkForReplication {
@@ -752,6 +2797,14 @@ func WaitForANFResource(ctx context.Context, resourceID string, intervalInSec in
 				uri.GetANFAccount(resourceID),
 				uri.GetANFSnapshotPolicy(resourceID),
 			)
+		} else if uri.IsANFBackupPolicy(resourceID) {
+			client, _ := getBackupPoliciesClient()
+			_, err = client.Get(
+				ctx,
+				uri.GetResourceGroup(resourceID),
+				uri.GetANFAccount(resourceID),
+				uri.GetANFBackupPolicy(resourceID),
+			)
 		} else if uri.IsANFAccount(resourceID) {
 			client, _ := getAccountsClient()
 			_, err = client.Get(
@@ -763,9 +2816,18 @@ func WaitForANFResource(ctx context.Context, resourceID string, intervalInSec in
 
 		// In this case, we exit when there is no error
 		if err == nil {
+			tracker.Done(nil)
+			logging.Debug("resource exists", logging.Operation("wait"), logging.ResourceID(resourceID), logging.Duration(time.Since(start)))
 			return nil
 		}
+
+		// Empty while the resource is not found, set when it exists but its replication is not ready yet
+		provisioningState, _ := GetANFProvisioningState(ctx, resourceID)
+		tracker.Poll(provisioningState)
 	}
 
-	return fmt.Errorf("resource still not found after number of retries: %v, error: %v", retries, err)
+	err = fmt.Errorf("resource still not found after number of retries: %v, error: %v", retries, err)
+	tracker.Done(err)
+
+	return err
 }
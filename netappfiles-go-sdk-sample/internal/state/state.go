@@ -0,0 +1,293 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// This package keeps a local journal of the resources created by
+// this sample so they can be removed later on by a teardown, even
+// from a different run of the tool.
+
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/uri"
+)
+
+const (
+	// DefaultJournalPath is the file used when no other journal location is provided
+	DefaultJournalPath string = "anf-sample-state.json"
+
+	// PathEnvironmentVariable overrides the default journal location for the sample and every command
+	PathEnvironmentVariable string = "ANF_STATE_PATH"
+
+	// Resource types recorded in the journal
+	ResourceGroupType  string = "resourceGroup"
+	VirtualNetworkType string = "virtualNetwork"
+	SubnetType         string = "subnet"
+	AccountType        string = "account"
+	CapacityPoolType   string = "capacityPool"
+	VolumeType         string = "volume"
+	SnapshotType       string = "snapshot"
+	SnapshotPolicyType string = "snapshotPolicy"
+	BackupPolicyType   string = "backupPolicy"
+)
+
+// Entry is a resource recorded in the journal, imported resources were created outside of the tool and are
+// only owned by it, i.e. deleted by a teardown, once adopted. Applied holds the last applied properties of the
+// resource, used as the reference for drift detection.
+type Entry struct {
+	ResourceID string          `json:"resourceId"`
+	Type       string          `json:"type"`
+	CreatedAt  time.Time       `json:"createdAt"`
+	Imported   bool            `json:"imported,omitempty"`
+	Adopted    bool            `json:"adopted,omitempty"`
+	Applied    json.RawMessage `json:"applied,omitempty"`
+}
+
+// Owned checks if the resource can be deleted by the tool, that is it was created by the tool or adopted when imported
+func (e Entry) Owned() bool {
+	return !e.Imported || e.Adopted
+}
+
+// Journal is the list of resources created by the tool, in creation order, it can be updated from several goroutines.
+// Growths holds when the autoscaler last grew a volume or capacity pool, by lower case resource id, so its cooldown
+// also applies to later runs.
+type Journal struct {
+	path    string
+	mu      sync.Mutex
+	Entries []Entry              `json:"entries"`
+	Growths map[string]time.Time `json:"growths,omitempty"`
+}
+
+// Path returns the journal location from the ANF_STATE_PATH environment variable, DefaultJournalPath when it is not set
+func Path() string {
+	if path := os.Getenv(PathEnvironmentVariable); path != "" {
+		return path
+	}
+	return DefaultJournalPath
+}
+
+// Load reads a journal from disk, a missing file results in an empty journal
+func Load(path string) (*Journal, error) {
+
+	journal := &Journal{path: path}
+
+	journalJSON, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return journal, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot read state journal: %v", err)
+	}
+
+	err = json.Unmarshal(journalJSON, journal)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse state journal %v: %v", path, err)
+	}
+
+	return journal, nil
+}
+
+// Save writes the journal back to disk
+func (j *Journal) Save() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.save()
+}
+
+func (j *Journal) save() error {
+
+	journalJSON, err := json.MarshalIndent(j, "", "  ")
+	if err != nil {
+		return fmt.Errorf("cannot serialize state journal: %v", err)
+	}
+
+	err = ioutil.WriteFile(j.path, journalJSON, 0644)
+	if err != nil {
+		return fmt.Errorf("cannot write state journal: %v", err)
+	}
+
+	return nil
+}
+
+// Record adds a newly created resource to the journal and saves it
+func (j *Journal) Record(resourceID, resourceType string) error {
+	return j.add(Entry{
+		ResourceID: resourceID,
+		Type:       resourceType,
+		CreatedAt:  time.Now().UTC(),
+	})
+}
+
+// RecordImported adds an existing resource to the journal and saves it, an adopted resource is owned by the tool
+// as if it had created it while other imported resources are tracked but never deleted
+func (j *Journal) RecordImported(resourceID, resourceType string, adopted bool) error {
+	return j.add(Entry{
+		ResourceID: resourceID,
+		Type:       resourceType,
+		CreatedAt:  time.Now().UTC(),
+		Imported:   true,
+		Adopted:    adopted,
+	})
+}
+
+func (j *Journal) add(entry Entry) error {
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	for i, existing := range j.Entries {
+		if strings.EqualFold(existing.ResourceID, entry.ResourceID) {
+			// Importing again with adoption hands over a resource imported earlier
+			if entry.Adopted && existing.Imported && !existing.Adopted {
+				j.Entries[i].Adopted = true
+				return j.save()
+			}
+			return nil
+		}
+	}
+
+	j.Entries = append(j.Entries, entry)
+
+	return j.save()
+}
+
+// Remove drops a resource from the journal, usually after it has been deleted, and saves it
+func (j *Journal) Remove(resourceID string) error {
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	for i, entry := range j.Entries {
+		if strings.EqualFold(entry.ResourceID, resourceID) {
+			j.Entries = append(j.Entries[:i], j.Entries[i+1:]...)
+			return j.save()
+		}
+	}
+
+	return nil
+}
+
+// Rename changes the id of a recorded resource, e.g. a volume moved to another capacity pool, and of the resources
+// nested under it, then saves the journal. Nothing is saved when the resource is not recorded.
+func (j *Journal) Rename(resourceID, newResourceID string) error {
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	renamed := false
+	prefix := strings.ToLower(resourceID) + "/"
+	for i, entry := range j.Entries {
+		switch {
+		case strings.EqualFold(entry.ResourceID, resourceID):
+			j.Entries[i].ResourceID = newResourceID
+		case strings.HasPrefix(strings.ToLower(entry.ResourceID), prefix):
+			j.Entries[i].ResourceID = newResourceID + entry.ResourceID[len(prefix)-1:]
+		default:
+			continue
+		}
+		renamed = true
+	}
+
+	if !renamed {
+		return nil
+	}
+
+	return j.save()
+}
+
+// SetApplied stores the last applied properties of a recorded resource and saves the journal
+func (j *Journal) SetApplied(resourceID string, applied interface{}) error {
+
+	appliedJSON, err := json.Marshal(applied)
+	if err != nil {
+		return fmt.Errorf("cannot serialize applied properties: %v", err)
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	for i, entry := range j.Entries {
+		if strings.EqualFold(entry.ResourceID, resourceID) {
+			j.Entries[i].Applied = appliedJSON
+			return j.save()
+		}
+	}
+
+	return fmt.Errorf("resource %v is not recorded in the state journal", resourceID)
+}
+
+// LastGrowth returns when a resource was last grown by the autoscaler, false when it never was
+func (j *Journal) LastGrowth(resourceID string) (time.Time, bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	grownAt, found := j.Growths[strings.ToLower(resourceID)]
+	return grownAt, found
+}
+
+// RecordGrowth stores when a resource was grown by the autoscaler and saves the journal, resources do not need
+// to be recorded in the journal
+func (j *Journal) RecordGrowth(resourceID string, grownAt time.Time) error {
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.Growths == nil {
+		j.Growths = map[string]time.Time{}
+	}
+	j.Growths[strings.ToLower(resourceID)] = grownAt.UTC()
+
+	return j.save()
+}
+
+// TypeFromID returns the journal type of a resource id, empty for resources the journal does not record, e.g. backups
+func TypeFromID(resourceID string) string {
+	switch {
+	case uri.IsANFSnapshot(resourceID):
+		return SnapshotType
+	case uri.IsANFVolume(resourceID):
+		return VolumeType
+	case uri.IsANFCapacityPool(resourceID):
+		return CapacityPoolType
+	case uri.IsANFSnapshotPolicy(resourceID):
+		return SnapshotPolicyType
+	case uri.IsANFBackupPolicy(resourceID):
+		return BackupPolicyType
+	case uri.IsANFAccount(resourceID):
+		return AccountType
+	case uri.IsSubnet(resourceID):
+		return SubnetType
+	case uri.IsVirtualNetwork(resourceID):
+		return VirtualNetworkType
+	case uri.IsResourceGroup(resourceID):
+		return ResourceGroupType
+	default:
+		return ""
+	}
+}
+
+// Contains checks if a resource is recorded in the journal
+func (j *Journal) Contains(resourceID string) bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.contains(resourceID)
+}
+
+func (j *Journal) contains(resourceID string) bool {
+
+	for _, entry := range j.Entries {
+		if strings.EqualFold(entry.ResourceID, resourceID) {
+			return true
+		}
+	}
+
+	return false
+}
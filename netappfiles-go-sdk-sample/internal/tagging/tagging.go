@@ -0,0 +1,280 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// This package adds, removes or replaces tags on accounts, capacity
+// pools, volumes and snapshot policies in bulk. Resources are picked
+// from an inventory of the subscription by resource id, name glob or
+// existing tags and changes can optionally be propagated down the
+// account hierarchy. Snapshots are left out, they cannot be tagged
+// with the 2021-04-01 API version.
+
+package tagging
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/sdkutils"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/state"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/uri"
+	"github.com/Azure/go-autorest/autorest/to"
+)
+
+// Resource is a taggable resource of the inventory
+type Resource struct {
+	ResourceID string
+	Type       string
+	Tags       map[string]*string
+}
+
+// Selector picks resources from the inventory, all criteria that are set must match
+type Selector struct {
+	ResourceIDs []string
+	NameGlob    string
+	TagFilter   map[string]string
+	Types       []string
+}
+
+// Change describes how tags are modified, with Replace the tags to add become the full tag set except for
+// the Preserve ones, e.g. ownership and lifetime tags, which are kept unless they are explicitly removed
+type Change struct {
+	Add      map[string]string
+	Remove   []string
+	Replace  bool
+	Preserve []string
+}
+
+// Update is a resource whose tags change
+type Update struct {
+	Resource Resource
+	NewTags  map[string]*string
+}
+
+// Failure is an update that could not be applied
+type Failure struct {
+	Update Update
+	Err    error
+}
+
+// Inventory lists every account of a location, or of the subscription when location is empty, with their
+// snapshot policies, capacity pools and volumes, parents always come before their children
+func Inventory(ctx context.Context, location string) ([]Resource, error) {
+
+	accountIDs, err := sdkutils.ListANFAccountIDs(ctx, location)
+	if err != nil {
+		return nil, err
+	}
+
+	inventory := []Resource{}
+	for _, accountID := range accountIDs {
+		account, err := sdkutils.GetANFAccountByID(ctx, accountID)
+		if err != nil {
+			return nil, err
+		}
+		inventory = append(inventory, Resource{ResourceID: *account.ID, Type: state.AccountType, Tags: account.Tags})
+
+		policies, err := sdkutils.ListANFSnapshotPolicies(ctx, uri.GetResourceGroup(accountID), uri.GetANFAccount(accountID))
+		if err != nil {
+			return nil, err
+		}
+		for _, policy := range policies {
+			inventory = append(inventory, Resource{ResourceID: *policy.ID, Type: state.SnapshotPolicyType, Tags: policy.Tags})
+		}
+
+		pools, err := sdkutils.ListANFCapacityPools(ctx, accountID)
+		if err != nil {
+			return nil, err
+		}
+		for _, pool := range pools {
+			inventory = append(inventory, Resource{ResourceID: *pool.ID, Type: state.CapacityPoolType, Tags: pool.Tags})
+
+			volumes, err := sdkutils.ListANFVolumes(ctx, *pool.ID)
+			if err != nil {
+				return nil, err
+			}
+			for _, volume := range volumes {
+				inventory = append(inventory, Resource{ResourceID: *volume.ID, Type: state.VolumeType, Tags: volume.Tags})
+			}
+		}
+	}
+
+	return inventory, nil
+}
+
+// Select returns the resources of the inventory matching the selector
+func Select(inventory []Resource, selector Selector) []Resource {
+
+	selected := []Resource{}
+	for _, resource := range inventory {
+		if selector.Matches(resource) {
+			selected = append(selected, resource)
+		}
+	}
+
+	return selected
+}
+
+// Matches checks if a resource matches every criteria of the selector, names are matched case insensitively
+// and a tag filter value of * only requires the tag to be present
+func (s Selector) Matches(resource Resource) bool {
+
+	if len(s.ResourceIDs) > 0 && !containsFold(s.ResourceIDs, resource.ResourceID) {
+		return false
+	}
+
+	if len(s.Types) > 0 && !containsFold(s.Types, resource.Type) {
+		return false
+	}
+
+	if s.NameGlob != "" {
+		matched, err := path.Match(strings.ToLower(s.NameGlob), strings.ToLower(uri.GetResourceName(resource.ResourceID)))
+		if err != nil || !matched {
+			return false
+		}
+	}
+
+	for key, value := range s.TagFilter {
+		actual, found := resource.Tags[key]
+		if !found || (value != "*" && to.String(actual) != value) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Plan computes the new tags of the selected resources, and of all their descendants when propagate is set,
+// resources whose tags would not change are left out
+func Plan(inventory, selected []Resource, change Change, propagate bool) []Update {
+
+	targets := map[string]bool{}
+	for _, resource := range selected {
+		targets[strings.ToLower(resource.ResourceID)] = true
+	}
+
+	updates := []Update{}
+	for _, resource := range inventory {
+		if !targets[strings.ToLower(resource.ResourceID)] && !(propagate && hasAncestor(resource.ResourceID, targets)) {
+			continue
+		}
+
+		newTags := change.Apply(resource.Tags)
+		if !equal(resource.Tags, newTags) {
+			updates = append(updates, Update{Resource: resource, NewTags: newTags})
+		}
+	}
+
+	return updates
+}
+
+// Apply returns the tags resulting from the change, the original tags are not modified
+func (c Change) Apply(tags map[string]*string) map[string]*string {
+
+	result := map[string]*string{}
+	for key, value := range tags {
+		if !c.Replace || containsFold(c.Preserve, key) {
+			result[key] = value
+		}
+	}
+
+	for _, key := range c.Remove {
+		delete(result, key)
+	}
+
+	for key, value := range c.Add {
+		result[key] = to.StringPtr(value)
+	}
+
+	return result
+}
+
+// Execute applies the updates in order, parents first, and returns the ones that failed
+func Execute(ctx context.Context, updates []Update) []Failure {
+
+	failures := []Failure{}
+	for _, update := range updates {
+		err := sdkutils.UpdateANFResourceTags(ctx, update.Resource.ResourceID, update.NewTags)
+		if err != nil {
+			failures = append(failures, Failure{Update: update, Err: err})
+		}
+	}
+
+	return failures
+}
+
+// Describe returns the tag changes of an update, e.g. +env=dev -owner
+func (u Update) Describe() string {
+
+	changes := []string{}
+	for key, value := range u.NewTags {
+		if old, found := u.Resource.Tags[key]; !found || to.String(old) != to.String(value) {
+			changes = append(changes, fmt.Sprintf("+%v=%v", key, to.String(value)))
+		}
+	}
+	for key := range u.Resource.Tags {
+		if _, found := u.NewTags[key]; !found {
+			changes = append(changes, fmt.Sprintf("-%v", key))
+		}
+	}
+	sort.Strings(changes)
+
+	return strings.Join(changes, " ")
+}
+
+// ParseTags parses a comma separated list of key=value pairs
+func ParseTags(list string) (map[string]string, error) {
+
+	tags := map[string]string{}
+	if strings.TrimSpace(list) == "" {
+		return tags, nil
+	}
+
+	for _, pair := range strings.Split(list, ",") {
+		keyValue := strings.SplitN(pair, "=", 2)
+		key := strings.TrimSpace(keyValue[0])
+		if len(keyValue) != 2 || key == "" {
+			return nil, fmt.Errorf("invalid tag %q, expected key=value", pair)
+		}
+		tags[key] = strings.TrimSpace(keyValue[1])
+	}
+
+	return tags, nil
+}
+
+// hasAncestor checks if one of the targets is a parent of the resource, e.g. the account of a volume
+func hasAncestor(resourceID string, targets map[string]bool) bool {
+	id := strings.ToLower(resourceID)
+	for target := range targets {
+		if strings.HasPrefix(id, target+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func equal(a, b map[string]*string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for key, value := range a {
+		other, found := b[key]
+		if !found || to.String(other) != to.String(value) {
+			return false
+		}
+	}
+	return true
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,72 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package tagging
+
+import (
+	"testing"
+
+	"github.com/Azure/go-autorest/autorest/to"
+)
+
+func TestChangeApply(t *testing.T) {
+
+	tags := map[string]*string{
+		"CreatedBy": to.StringPtr("anf-go-sdk-sample"),
+		"createdAt": to.StringPtr("2021-07-30T00:00:00Z"),
+		"env":       to.StringPtr("dev"),
+		"owner":     to.StringPtr("alice"),
+	}
+	preserve := []string{"CreatedBy", "createdAt", "expiresAt"}
+
+	tests := []struct {
+		name   string
+		change Change
+		want   map[string]string
+	}{
+		{
+			name:   "add and remove",
+			change: Change{Add: map[string]string{"env": "prod"}, Remove: []string{"owner"}},
+			want:   map[string]string{"CreatedBy": "anf-go-sdk-sample", "createdAt": "2021-07-30T00:00:00Z", "env": "prod"},
+		},
+		{
+			name:   "replace keeps preserved tags",
+			change: Change{Add: map[string]string{"team": "storage"}, Replace: true, Preserve: preserve},
+			want:   map[string]string{"CreatedBy": "anf-go-sdk-sample", "createdAt": "2021-07-30T00:00:00Z", "team": "storage"},
+		},
+		{
+			name:   "replace without tags to add keeps preserved tags",
+			change: Change{Replace: true, Preserve: preserve},
+			want:   map[string]string{"CreatedBy": "anf-go-sdk-sample", "createdAt": "2021-07-30T00:00:00Z"},
+		},
+		{
+			name:   "preserved tags can be removed explicitly",
+			change: Change{Replace: true, Remove: []string{"createdAt"}, Preserve: preserve},
+			want:   map[string]string{"CreatedBy": "anf-go-sdk-sample"},
+		},
+		{
+			name:   "preserved tags can be overwritten",
+			change: Change{Add: map[string]string{"CreatedBy": "someone"}, Replace: true, Preserve: preserve},
+			want:   map[string]string{"CreatedBy": "someone", "createdAt": "2021-07-30T00:00:00Z"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := test.change.Apply(tags)
+			if len(got) != len(test.want) {
+				t.Fatalf("got %v tags, want %v", len(got), test.want)
+			}
+			for key, value := range test.want {
+				if to.String(got[key]) != value {
+					t.Errorf("tag %v = %q, want %q", key, to.String(got[key]), value)
+				}
+			}
+			if to.String(tags["owner"]) != "alice" || len(tags) != 4 {
+				t.Errorf("original tags were modified: %v", tags)
+			}
+		})
+	}
+}
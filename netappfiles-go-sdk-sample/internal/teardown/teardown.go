@@ -0,0 +1,228 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+// This package removes the resources recorded in the state journal,
+// deepest first so children are always deleted before their parents,
+// or whole account trees discovered from Azure. Imported resources are
+// only deleted once adopted. Deletions of accounts, capacity pools,
+// volumes and snapshots are recorded in an operation store when one is
+// provided, so they can be resumed with the status command.
+
+package teardown
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/logging"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/operations"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/sdkutils"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/state"
+	"github.com/Azure-Samples/netappfiles-go-sdk-sample/netappfiles-go-sdk-sample/internal/uri"
+)
+
+// Run deletes every resource owned by the journal in the order returned by Order, store can be nil
+func Run(ctx context.Context, journal *state.Journal, store *operations.Store) error {
+
+	owned, _ := Order(journal)
+	resourceIDs := make([]string, 0, len(owned))
+	for _, entry := range owned {
+		resourceIDs = append(resourceIDs, entry.ResourceID)
+	}
+
+	return Resources(ctx, journal, resourceIDs, store)
+}
+
+// Order returns the journal entries to delete, deepest resource ids first and newest first among ids of the same
+// depth, so children are deleted before their parents whatever order they were recorded in, and the imported
+// entries that are kept because they were not adopted
+func Order(journal *state.Journal) (owned []state.Entry, kept []state.Entry) {
+
+	for i := len(journal.Entries) - 1; i >= 0; i-- {
+		entry := journal.Entries[i]
+		if !entry.Owned() {
+			kept = append(kept, entry)
+			continue
+		}
+		owned = append(owned, entry)
+	}
+
+	sort.SliceStable(owned, func(i, j int) bool {
+		return depth(owned[i].ResourceID) > depth(owned[j].ResourceID)
+	})
+
+	return owned, kept
+}
+
+// depth returns the number of segments of a resource id
+func depth(resourceID string) int {
+	return len(strings.Split(strings.Trim(resourceID, "/"), "/"))
+}
+
+// Resources deletes the resources in the order provided, dropping each one from the journal once deleted,
+// store can be nil
+func Resources(ctx context.Context, journal *state.Journal, resourceIDs []string, store *operations.Store) error {
+
+	for _, resourceID := range resourceIDs {
+		logging.Info(fmt.Sprintf("Deleting %v...", uri.GetResourceName(resourceID)), logging.Operation("delete"), logging.ResourceID(resourceID))
+
+		err := DeleteResource(ctx, resourceID, store)
+		if err != nil {
+			return fmt.Errorf("cannot delete %v: %v", resourceID, err)
+		}
+
+		err = journal.Remove(resourceID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Tree lists an account, capacity pool, volume or policy and everything it contains, children first, so the
+// resources can be deleted in the order returned
+func Tree(ctx context.Context, resourceID string) ([]string, error) {
+
+	switch {
+	case uri.IsANFVolume(resourceID), uri.IsANFSnapshotPolicy(resourceID), uri.IsANFBackupPolicy(resourceID):
+		return []string{resourceID}, nil
+	case uri.IsANFCapacityPool(resourceID):
+		volumes, err := sdkutils.ListANFVolumes(ctx, resourceID)
+		if err != nil {
+			return nil, err
+		}
+		resourceIDs := []string{}
+		for _, volume := range volumes {
+			resourceIDs = append(resourceIDs, *volume.ID)
+		}
+		return append(resourceIDs, resourceID), nil
+	case uri.IsANFAccount(resourceID):
+		pools, err := sdkutils.ListANFCapacityPools(ctx, resourceID)
+		if err != nil {
+			return nil, err
+		}
+		resourceIDs := []string{}
+		for _, pool := range pools {
+			poolTree, err := Tree(ctx, *pool.ID)
+			if err != nil {
+				return nil, err
+			}
+			resourceIDs = append(resourceIDs, poolTree...)
+		}
+
+		resourceGroupName := uri.GetResourceGroup(resourceID)
+		accountName := uri.GetANFAccount(resourceID)
+		snapshotPolicies, err := sdkutils.ListANFSnapshotPolicies(ctx, resourceGroupName, accountName)
+		if err != nil {
+			return nil, err
+		}
+		for _, policy := range snapshotPolicies {
+			resourceIDs = append(resourceIDs, *policy.ID)
+		}
+		backupPolicies, err := sdkutils.ListANFBackupPolicies(ctx, resourceGroupName, accountName)
+		if err != nil {
+			return nil, err
+		}
+		for _, policy := range backupPolicies {
+			resourceIDs = append(resourceIDs, *policy.ID)
+		}
+
+		return append(resourceIDs, resourceID), nil
+	}
+
+	return nil, fmt.Errorf("%v is not an account, capacity pool, volume or policy", resourceID)
+}
+
+// DeleteResource deletes a single resource based on the type found in its resource id, deletions of accounts,
+// capacity pools, volumes and snapshots are tracked in the store unless it is nil
+func DeleteResource(ctx context.Context, resourceID string, store *operations.Store) error {
+
+	resourceGroupName := uri.GetResourceGroup(resourceID)
+
+	switch {
+	case uri.IsANFSnapshot(resourceID):
+		err := deleteTracked(ctx, resourceID, state.SnapshotType, store)
+		if err != nil {
+			return err
+		}
+		return sdkutils.WaitForNoANFResource(ctx, resourceID, 60, 60, false)
+	case uri.IsANFBackup(resourceID):
+		return sdkutils.DeleteANFBackup(
+			ctx,
+			resourceGroupName,
+			uri.GetANFAccount(resourceID),
+			uri.GetANFCapacityPool(resourceID),
+			uri.GetANFVolume(resourceID),
+			uri.GetANFBackup(resourceID),
+		)
+	case uri.IsANFAccountBackup(resourceID):
+		return sdkutils.DeleteANFAccountBackup(
+			ctx,
+			resourceGroupName,
+			uri.GetANFAccount(resourceID),
+			uri.GetANFAccountBackup(resourceID),
+		)
+	case uri.IsANFVolume(resourceID):
+		err := deleteTracked(ctx, resourceID, state.VolumeType, store)
+		if err != nil {
+			return err
+		}
+		return sdkutils.WaitForNoANFResource(ctx, resourceID, 60, 60, false)
+	case uri.IsANFCapacityPool(resourceID):
+		err := deleteTracked(ctx, resourceID, state.CapacityPoolType, store)
+		if err != nil {
+			return err
+		}
+		return sdkutils.WaitForNoANFResource(ctx, resourceID, 60, 60, false)
+	case uri.IsANFSnapshotPolicy(resourceID):
+		return sdkutils.DeleteANFSnapshotPolicy(
+			ctx,
+			resourceGroupName,
+			uri.GetANFAccount(resourceID),
+			uri.GetANFSnapshotPolicy(resourceID),
+		)
+	case uri.IsANFBackupPolicy(resourceID):
+		return sdkutils.DeleteANFBackupPolicy(
+			ctx,
+			resourceGroupName,
+			uri.GetANFAccount(resourceID),
+			uri.GetANFBackupPolicy(resourceID),
+		)
+	case uri.IsANFAccount(resourceID):
+		return deleteTracked(ctx, resourceID, state.AccountType, store)
+	case uri.IsSubnet(resourceID):
+		return sdkutils.DeleteSubnet(ctx, resourceID)
+	case uri.IsVirtualNetwork(resourceID):
+		return sdkutils.DeleteVirtualNetwork(ctx, resourceID)
+	case uri.IsResourceGroup(resourceID):
+		return sdkutils.DeleteResourceGroup(ctx, resourceGroupName)
+	}
+
+	return fmt.Errorf("resource type of %v is not supported", resourceID)
+}
+
+// deleteTracked starts the deletion of an account, capacity pool, volume or snapshot and polls it until it completes,
+// recording it in the store while in progress so an interrupted deletion can be resumed
+func deleteTracked(ctx context.Context, resourceID, resourceType string, store *operations.Store) error {
+
+	future, err := sdkutils.BeginDeleteANFResource(ctx, resourceID)
+	if err != nil {
+		return err
+	}
+
+	if store != nil {
+		return store.Track(ctx, operations.Delete, resourceID, resourceType, future)
+	}
+
+	handle, err := operations.NewHandle(operations.Delete, resourceID, resourceType, future)
+	if err != nil {
+		return err
+	}
+
+	return operations.Wait(ctx, handle)
+}
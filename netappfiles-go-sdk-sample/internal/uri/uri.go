@@ -177,6 +177,66 @@ func GetANFSnapshotPolicy(resourceURI string) string {
 	return snapshotPolicyName
 }
 
+// GetANFBackup gets the name of a volume backup from resource id/uri
+func GetANFBackup(resourceURI string) string {
+
+	if len(strings.TrimSpace(resourceURI)) == 0 {
+		return ""
+	}
+
+	backupName := GetResourceValue(resourceURI, "/backups")
+	if backupName == "" {
+		return ""
+	}
+
+	return backupName
+}
+
+// GetANFAccountBackup gets the name of an account backup, i.e. a backup listed at the account level, from resource id/uri
+func GetANFAccountBackup(resourceURI string) string {
+
+	if len(strings.TrimSpace(resourceURI)) == 0 {
+		return ""
+	}
+
+	backupName := GetResourceValue(resourceURI, "/accountBackups")
+	if backupName == "" {
+		return ""
+	}
+
+	return backupName
+}
+
+// GetANFBackupPolicy gets backup policy name from resource id/uri
+func GetANFBackupPolicy(resourceURI string) string {
+
+	if len(strings.TrimSpace(resourceURI)) == 0 {
+		return ""
+	}
+
+	backupPolicyName := GetResourceValue(resourceURI, "/backupPolicies")
+	if backupPolicyName == "" {
+		return ""
+	}
+
+	return backupPolicyName
+}
+
+// GetANFCapacityPoolID gets the capacity pool resource id from the resource id/uri of one of its child resources
+func GetANFCapacityPoolID(resourceURI string) string {
+
+	if len(strings.TrimSpace(resourceURI)) == 0 {
+		return ""
+	}
+
+	index := strings.Index(strings.ToLower(resourceURI), "/volumes/")
+	if index == -1 || GetANFCapacityPool(resourceURI) == "" {
+		return ""
+	}
+
+	return resourceURI[:index]
+}
+
 // IsANFResource checks if resource is an ANF related resource
 func IsANFResource(resourceURI string) bool {
 
@@ -197,6 +257,27 @@ func IsANFSnapshot(resourceURI string) bool {
 	return strings.LastIndex(resourceURI, "/snapshots/") > -1
 }
 
+// IsANFBackup checks resource is a volume backup, account backups have no pool or volume and are not volume backups
+func IsANFBackup(resourceURI string) bool {
+
+	if len(strings.TrimSpace(resourceURI)) == 0 || !IsANFResource(resourceURI) {
+		return false
+	}
+
+	return strings.LastIndex(resourceURI, "/volumes/") > -1 &&
+		strings.LastIndex(resourceURI, "/backups/") > -1
+}
+
+// IsANFAccountBackup checks resource is an account backup
+func IsANFAccountBackup(resourceURI string) bool {
+
+	if len(strings.TrimSpace(resourceURI)) == 0 || !IsANFResource(resourceURI) {
+		return false
+	}
+
+	return strings.LastIndex(resourceURI, "/accountBackups/") > -1
+}
+
 // IsANFVolume checks resource is a volume
 func IsANFVolume(resourceURI string) bool {
 
@@ -205,6 +286,8 @@ func IsANFVolume(resourceURI string) bool {
 	}
 
 	return !IsANFSnapshot(resourceURI) &&
+		!IsANFBackup(resourceURI) &&
+		!IsANFAccountBackup(resourceURI) &&
 		strings.LastIndex(resourceURI, "/volumes/") > -1
 }
 
@@ -216,6 +299,8 @@ func IsANFCapacityPool(resourceURI string) bool {
 	}
 
 	return !IsANFSnapshot(resourceURI) &&
+		!IsANFBackup(resourceURI) &&
+		!IsANFAccountBackup(resourceURI) &&
 		!IsANFVolume(resourceURI) &&
 		strings.LastIndex(resourceURI, "/capacityPools/") > -1
 }
@@ -228,11 +313,28 @@ func IsANFSnapshotPolicy(resourceURI string) bool {
 	}
 
 	return !IsANFSnapshot(resourceURI) &&
+		!IsANFBackup(resourceURI) &&
+		!IsANFAccountBackup(resourceURI) &&
 		!IsANFVolume(resourceURI) &&
 		!IsANFCapacityPool(resourceURI) &&
 		strings.LastIndex(resourceURI, "/snapshotPolicies/") > -1
 }
 
+// IsANFBackupPolicy checks resource is a backup policy
+func IsANFBackupPolicy(resourceURI string) bool {
+
+	if len(strings.TrimSpace(resourceURI)) == 0 || !IsANFResource(resourceURI) {
+		return false
+	}
+
+	return !IsANFSnapshot(resourceURI) &&
+		!IsANFBackup(resourceURI) &&
+		!IsANFAccountBackup(resourceURI) &&
+		!IsANFVolume(resourceURI) &&
+		!IsANFCapacityPool(resourceURI) &&
+		strings.LastIndex(resourceURI, "/backupPolicies/") > -1
+}
+
 // IsANFAccount checks resource is an account
 func IsANFAccount(resourceURI string) bool {
 
@@ -241,10 +343,46 @@ func IsANFAccount(resourceURI string) bool {
 	}
 
 	return !IsANFSnapshot(resourceURI) &&
+		!IsANFBackup(resourceURI) &&
+		!IsANFAccountBackup(resourceURI) &&
 		!IsANFVolume(resourceURI) &&
 		!IsANFCapacityPool(resourceURI) &&
 		!IsANFSnapshotPolicy(resourceURI) &&
+		!IsANFBackupPolicy(resourceURI) &&
 		strings.LastIndex(resourceURI, "/snapshotPolicies/") == -1 &&
 		strings.LastIndex(resourceURI, "/backupPolicies/") == -1 &&
 		strings.LastIndex(resourceURI, "/netAppAccounts/") > -1
 }
+
+// IsSubnet checks resource is a virtual network subnet
+func IsSubnet(resourceURI string) bool {
+
+	if len(strings.TrimSpace(resourceURI)) == 0 {
+		return false
+	}
+
+	return strings.LastIndex(resourceURI, "/virtualNetworks/") > -1 &&
+		strings.LastIndex(resourceURI, "/subnets/") > -1
+}
+
+// IsVirtualNetwork checks resource is a virtual network
+func IsVirtualNetwork(resourceURI string) bool {
+
+	if len(strings.TrimSpace(resourceURI)) == 0 {
+		return false
+	}
+
+	return !IsSubnet(resourceURI) &&
+		strings.LastIndex(resourceURI, "/virtualNetworks/") > -1
+}
+
+// IsResourceGroup checks resource is a resource group
+func IsResourceGroup(resourceURI string) bool {
+
+	if len(strings.TrimSpace(resourceURI)) == 0 {
+		return false
+	}
+
+	return strings.LastIndex(resourceURI, "/providers/") == -1 &&
+		strings.LastIndex(resourceURI, "/resourceGroups/") > -1
+}
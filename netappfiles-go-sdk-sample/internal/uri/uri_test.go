@@ -0,0 +1,93 @@
+// Copyright (c) Microsoft and contributors.  All rights reserved.
+//
+// This source code is licensed under the MIT license found in the
+// LICENSE file in the root directory of this source tree.
+
+package uri
+
+import (
+	"testing"
+)
+
+const (
+	accountID = "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/anf01-rg/providers/Microsoft.NetApp/netAppAccounts/account01"
+)
+
+func TestBackupIDs(t *testing.T) {
+
+	tests := []struct {
+		name              string
+		resourceID        string
+		isBackup          bool
+		isAccountBackup   bool
+		backupName        string
+		accountBackupName string
+		poolName          string
+		volumeName        string
+	}{
+		{
+			name:       "volume backup",
+			resourceID: accountID + "/capacityPools/pool01/volumes/volume01/backups/backup01",
+			isBackup:   true,
+			backupName: "backup01",
+			poolName:   "pool01",
+			volumeName: "volume01",
+		},
+		{
+			name:              "account backup",
+			resourceID:        accountID + "/accountBackups/backup01",
+			isAccountBackup:   true,
+			accountBackupName: "backup01",
+		},
+		{
+			name:       "volume",
+			resourceID: accountID + "/capacityPools/pool01/volumes/volume01",
+			poolName:   "pool01",
+			volumeName: "volume01",
+		},
+		{
+			name:       "backup policy",
+			resourceID: accountID + "/backupPolicies/policy01",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := IsANFBackup(test.resourceID); got != test.isBackup {
+				t.Errorf("IsANFBackup() = %v, want %v", got, test.isBackup)
+			}
+			if got := IsANFAccountBackup(test.resourceID); got != test.isAccountBackup {
+				t.Errorf("IsANFAccountBackup() = %v, want %v", got, test.isAccountBackup)
+			}
+			if got := GetANFBackup(test.resourceID); got != test.backupName {
+				t.Errorf("GetANFBackup() = %q, want %q", got, test.backupName)
+			}
+			if got := GetANFAccountBackup(test.resourceID); got != test.accountBackupName {
+				t.Errorf("GetANFAccountBackup() = %q, want %q", got, test.accountBackupName)
+			}
+			if got := GetANFCapacityPool(test.resourceID); got != test.poolName {
+				t.Errorf("GetANFCapacityPool() = %q, want %q", got, test.poolName)
+			}
+			if got := GetANFVolume(test.resourceID); got != test.volumeName {
+				t.Errorf("GetANFVolume() = %q, want %q", got, test.volumeName)
+			}
+			if got := GetANFAccount(test.resourceID); got != "account01" {
+				t.Errorf("GetANFAccount() = %q, want %q", got, "account01")
+			}
+		})
+	}
+}
+
+func TestResourceTypesOfBackupIDs(t *testing.T) {
+
+	for _, resourceID := range []string{
+		accountID + "/capacityPools/pool01/volumes/volume01/backups/backup01",
+		accountID + "/accountBackups/backup01",
+	} {
+		t.Run(resourceID, func(t *testing.T) {
+			if IsANFVolume(resourceID) || IsANFCapacityPool(resourceID) || IsANFAccount(resourceID) || IsANFSnapshot(resourceID) || IsANFBackupPolicy(resourceID) {
+				t.Errorf("backup id is reported as another resource type")
+			}
+		})
+	}
+}
@@ -11,7 +11,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
-	"log"
 	"strings"
 	"syscall"
 
@@ -25,9 +24,10 @@ func PrintHeader(header string) {
 	fmt.Println(strings.Repeat("-", len(header)))
 }
 
-// ConsoleOutput writes to stdout.
+// ConsoleOutput writes the result of a command to standard output whatever the log level, format or quiet mode,
+// progress and failures go through the logging package instead
 func ConsoleOutput(message string) {
-	log.Println(message)
+	fmt.Println(message)
 }
 
 // Contains checks if there is a string already in an existing splice of strings
@@ -62,6 +62,33 @@ func ReadAzureBasicInfoJSON(path string) (*models.AzureBasicInfo, error) {
 	return &info, nil
 }
 
+// ReadDeploymentSpecJSON reads a deployment spec json file and unmarshals it.
+func ReadDeploymentSpecJSON(path string) (*models.DeploymentSpec, error) {
+	specJSON, err := ioutil.ReadFile(path)
+	if err != nil {
+		return &models.DeploymentSpec{}, fmt.Errorf("failed to read deployment spec: %v", err)
+	}
+	var spec models.DeploymentSpec
+	err = json.Unmarshal(specJSON, &spec)
+	if err != nil {
+		return &models.DeploymentSpec{}, fmt.Errorf("failed to parse deployment spec: %v", err)
+	}
+	return &spec, nil
+}
+
+// WriteDeploymentSpecJSON marshals a deployment spec and writes it to a json file.
+func WriteDeploymentSpecJSON(path string, spec *models.DeploymentSpec) error {
+	specJSON, err := json.MarshalIndent(spec, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to serialize deployment spec: %v", err)
+	}
+	err = ioutil.WriteFile(path, specJSON, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write deployment spec: %v", err)
+	}
+	return nil
+}
+
 // FindInSlice returns index greater than -1 and true if item is found
 // Code from https://golangcode.com/check-if-element-exists-in-slice/
 func FindInSlice(slice []string, val string) (int, bool) {